@@ -186,7 +186,9 @@ func NewNode(id uint64, in Ingress, out Egress, deliverChan chan<- *Block, logge
 			ViewId:         0,
 		},
 	}
-	node.consensus.Start()
+	if err := node.consensus.Start(); err != nil {
+		panic(err)
+	}
 	node.Start()
 	return node
 }
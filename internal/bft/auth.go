@@ -0,0 +1,39 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package bft
+
+import (
+	"github.com/SmartBFT-Go/consensus/pkg/api"
+	protos "github.com/SmartBFT-Go/consensus/smartbftprotos"
+	"github.com/pkg/errors"
+)
+
+// AuthenticateMessage returns a copy of the message we send to the target,
+// which carries a tag computed over its content and protocol versions by the authenticator.
+func AuthenticateMessage(authenticator api.MessageAuthenticator, target uint64, m *protos.Message) *protos.Message {
+	authenticated := authenticatedContent(m)
+	authenticated.Authentication = authenticator.Authenticate(target, MarshalOrPanic(authenticated))
+	return authenticated
+}
+
+// VerifyMessageAuthentication verifies the tag carried by the message we received from the sender.
+func VerifyMessageAuthentication(authenticator api.MessageAuthenticator, sender uint64, m *protos.Message) error {
+	if len(m.Authentication) == 0 {
+		return errors.New("message is not authenticated")
+	}
+	if err := authenticator.VerifyAuthentication(sender, MarshalOrPanic(authenticatedContent(m)), m.Authentication); err != nil {
+		return errors.Wrap(err, "message authentication failed")
+	}
+	return nil
+}
+
+func authenticatedContent(m *protos.Message) *protos.Message {
+	return &protos.Message{
+		Content:            m.Content,
+		ProtocolVersion:    m.ProtocolVersion,
+		MaxProtocolVersion: m.MaxProtocolVersion,
+	}
+}
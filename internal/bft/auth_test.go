@@ -0,0 +1,80 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package bft_test
+
+import (
+	"testing"
+
+	"github.com/SmartBFT-Go/consensus/internal/bft"
+	"github.com/SmartBFT-Go/consensus/internal/bft/mocks"
+	"github.com/SmartBFT-Go/consensus/pkg/auth"
+	protos "github.com/SmartBFT-Go/consensus/smartbftprotos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func TestMessageAuthentication(t *testing.T) {
+	sender := &auth.HMACAuthenticator{SelfID: 1, Keys: map[uint64][]byte{2: []byte("secret")}}
+	receiver := &auth.HMACAuthenticator{SelfID: 2, Keys: map[uint64][]byte{1: []byte("secret")}}
+
+	authenticated := bft.AuthenticateMessage(sender, 2, prepare)
+	assert.NotEmpty(t, authenticated.Authentication)
+	assert.Empty(t, prepare.Authentication, "the original message should not be modified")
+	assert.Equal(t, prepare.Content, authenticated.Content)
+	assert.NoError(t, bft.VerifyMessageAuthentication(receiver, 1, authenticated))
+
+	err := bft.VerifyMessageAuthentication(receiver, 1, prepare)
+	assert.EqualError(t, err, "message is not authenticated")
+
+	forged := &protos.Message{Content: commit1.Content, Authentication: authenticated.Authentication}
+	err = bft.VerifyMessageAuthentication(receiver, 1, forged)
+	assert.EqualError(t, err, "message authentication failed: invalid MAC of node 1")
+}
+
+func TestControllerRejectsUnauthenticatedMessages(t *testing.T) {
+	basicLog, err := zap.NewDevelopment()
+	assert.NoError(t, err)
+	log := basicLog.Sugar()
+	batcher := &mocks.Batcher{}
+	batcher.On("Close")
+	pool := &mocks.RequestPool{}
+	pool.On("Close")
+	leaderMon := &mocks.LeaderMonitor{}
+	leaderMon.On("ChangeRole", mock.Anything, mock.Anything, mock.Anything)
+	leaderMon.On("ProcessMsg", mock.Anything, mock.Anything)
+	leaderMon.On("Close")
+	comm := &mocks.CommMock{}
+	comm.On("Nodes").Return([]uint64{0, 1, 2, 3})
+	rejectionHandler := &mocks.MessageRejectionHandlerMock{}
+	rejectionHandler.On("OnMessageRejected", mock.Anything, mock.Anything, mock.Anything)
+
+	keys := map[uint64][]byte{1: []byte("secret")}
+	controller := &bft.Controller{
+		Batcher:          batcher,
+		RequestPool:      pool,
+		LeaderMonitor:    leaderMon,
+		ID:               2, // not the leader
+		N:                4,
+		Logger:           log,
+		Comm:             comm,
+		RejectionHandler: rejectionHandler,
+		Authenticator:    &auth.HMACAuthenticator{SelfID: 2, Keys: keys},
+	}
+	configureProposerBuilder(controller)
+
+	controller.Start(1, 0)
+
+	authenticated := bft.AuthenticateMessage(&auth.HMACAuthenticator{SelfID: 1, Keys: map[uint64][]byte{2: []byte("secret")}}, 2, heartbeat)
+	controller.ProcessMessages(1, heartbeat)
+	controller.ProcessMessages(1, authenticated)
+	controller.Stop()
+
+	leaderMon.AssertNumberOfCalls(t, "ProcessMsg", 1)
+	leaderMon.AssertCalled(t, "ProcessMsg", uint64(1), authenticated)
+	rejectionHandler.AssertNumberOfCalls(t, "OnMessageRejected", 1)
+	rejectionHandler.AssertCalled(t, "OnMessageRejected", uint64(1), heartbeat, mock.Anything)
+}
@@ -0,0 +1,130 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package bft
+
+import (
+	"github.com/SmartBFT-Go/consensus/pkg/api"
+	"github.com/SmartBFT-Go/consensus/pkg/types"
+	protos "github.com/SmartBFT-Go/consensus/smartbftprotos"
+	"github.com/golang/protobuf/proto"
+)
+
+// blacklist computes the blacklist of a proposal from the blacklist of the previous decision.
+// The leaders of the views that passed since the previous decision were deposed, and are added to the blacklist,
+// as long as the nodes in it weigh at most f. Blacklisted nodes that signed the previous decision proved they are alive,
+// and are removed from the blacklist, as long as it doesn't change the leader of the current view.
+type blacklist struct {
+	prevMD             *protos.ViewMetadata
+	currView           uint64
+	leaderID           uint64
+	nodes              []uint64
+	rotation           api.LeaderRotation
+	decisionsPerLeader uint64
+	f                  int
+	weights            map[uint64]uint64
+}
+
+func (bl blacklist) computeUpdate(prevCommitSigners []uint64) []uint64 {
+	newBlacklist := make([]uint64, len(bl.prevMD.BlackList))
+	copy(newBlacklist, bl.prevMD.BlackList)
+
+	for _, signer := range prevCommitSigners {
+		if !containsID(newBlacklist, signer) {
+			continue
+		}
+		withoutSigner := removeID(newBlacklist, signer)
+		if getLeaderID(bl.currView, bl.rotation, bl.nodes, withoutSigner) != bl.leaderID {
+			continue
+		}
+		newBlacklist = withoutSigner
+	}
+
+	firstDeposedView := bl.prevMD.ViewId
+	if bl.prevMD.LatestSequence > 0 {
+		firstDeposedView = ViewAfterDecision(bl.prevMD, bl.decisionsPerLeader)
+	}
+	for view := firstDeposedView; view < bl.currView; view++ {
+		deposed := getLeaderID(view, bl.rotation, bl.nodes, bl.prevMD.BlackList)
+		if deposed == bl.leaderID || containsID(newBlacklist, deposed) {
+			continue
+		}
+		if votingWeight(bl.weights, append(newBlacklist, deposed)...) > bl.f {
+			continue
+		}
+		newBlacklist = append(newBlacklist, deposed)
+	}
+
+	if len(newBlacklist) == 0 {
+		return nil
+	}
+	return newBlacklist
+}
+
+// candidates returns the nodes the leader of the current view might have blacklisted,
+// which are the nodes in the blacklist of the previous decision, and the leaders deposed since.
+func (bl blacklist) candidates() []uint64 {
+	candidates := make([]uint64, len(bl.prevMD.BlackList))
+	copy(candidates, bl.prevMD.BlackList)
+	firstDeposedView := bl.prevMD.ViewId
+	if bl.prevMD.LatestSequence > 0 {
+		firstDeposedView = ViewAfterDecision(bl.prevMD, bl.decisionsPerLeader)
+	}
+	for view := firstDeposedView; view < bl.currView; view++ {
+		if deposed := getLeaderID(view, bl.rotation, bl.nodes, bl.prevMD.BlackList); !containsID(candidates, deposed) {
+			candidates = append(candidates, deposed)
+		}
+	}
+	return candidates
+}
+
+// blacklistOf returns the blacklist of the last decision.
+func blacklistOf(checkpoint *types.Checkpoint) []uint64 {
+	if checkpoint == nil {
+		return nil
+	}
+	proposal, _ := checkpoint.Get()
+	return metadataBlacklist(proposal.Metadata)
+}
+
+// metadataBlacklist returns the blacklist in the metadata of a decision.
+func metadataBlacklist(metadata []byte) []uint64 {
+	md := &protos.ViewMetadata{}
+	if err := proto.Unmarshal(metadata, md); err != nil {
+		return nil
+	}
+	return md.BlackList
+}
+
+func containsID(ids []uint64, id uint64) bool {
+	for _, i := range ids {
+		if i == id {
+			return true
+		}
+	}
+	return false
+}
+
+func removeID(ids []uint64, id uint64) []uint64 {
+	var res []uint64
+	for _, i := range ids {
+		if i != id {
+			res = append(res, i)
+		}
+	}
+	return res
+}
+
+func equalIDs(a, b []uint64) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,99 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package bft
+
+import (
+	"testing"
+
+	protos "github.com/SmartBFT-Go/consensus/smartbftprotos"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetLeaderIDSkipsBlacklisted(t *testing.T) {
+	nodes := []uint64{1, 2, 3, 4}
+	assert.Equal(t, uint64(2), getLeaderID(1, nil, nodes, nil))
+	assert.Equal(t, uint64(3), getLeaderID(1, nil, nodes, []uint64{2}))
+	assert.Equal(t, uint64(4), getLeaderID(1, nil, nodes, []uint64{2, 3}))
+	assert.Equal(t, uint64(1), getLeaderID(3, nil, nodes, []uint64{4}))
+	// The blacklist only matters when it includes the leader
+	assert.Equal(t, uint64(2), getLeaderID(1, nil, nodes, []uint64{3}))
+}
+
+func TestBlacklistComputeUpdate(t *testing.T) {
+	nodes := []uint64{1, 2, 3, 4, 5, 6, 7}
+
+	for _, testCase := range []struct {
+		description        string
+		prevMD             *protos.ViewMetadata
+		currView           uint64
+		decisionsPerLeader uint64
+		prevCommitSigners  []uint64
+		expected           []uint64
+	}{
+		{
+			description: "no view change",
+			prevMD:      &protos.ViewMetadata{ViewId: 3, LatestSequence: 5},
+			currView:    3,
+		},
+		{
+			description: "leaders deposed since the last decision",
+			prevMD:      &protos.ViewMetadata{ViewId: 1, LatestSequence: 5},
+			currView:    3,
+			expected:    []uint64{2, 3},
+		},
+		{
+			description: "no decisions yet",
+			prevMD:      &protos.ViewMetadata{},
+			currView:    2,
+			expected:    []uint64{1, 2},
+		},
+		{
+			description: "at most f nodes",
+			prevMD:      &protos.ViewMetadata{ViewId: 1, LatestSequence: 5, BlackList: []uint64{6}},
+			currView:    4,
+			expected:    []uint64{6, 2},
+		},
+		{
+			description:       "signers of the last decision are removed",
+			prevMD:            &protos.ViewMetadata{ViewId: 3, LatestSequence: 5, BlackList: []uint64{2, 6}},
+			currView:          3,
+			prevCommitSigners: []uint64{1, 6, 7},
+			expected:          []uint64{2},
+		},
+		{
+			description:       "signers skipped in the current view are not removed",
+			prevMD:            &protos.ViewMetadata{ViewId: 1, LatestSequence: 5, BlackList: []uint64{2}},
+			currView:          1,
+			prevCommitSigners: []uint64{2},
+			expected:          []uint64{2},
+		},
+		{
+			description:        "leader rotated after its decisions",
+			prevMD:             &protos.ViewMetadata{ViewId: 1, LatestSequence: 5, DecisionsInView: 1},
+			currView:           2,
+			decisionsPerLeader: 2,
+		},
+		{
+			description:        "leader deposed after the rotation",
+			prevMD:             &protos.ViewMetadata{ViewId: 1, LatestSequence: 5, DecisionsInView: 1},
+			currView:           3,
+			decisionsPerLeader: 2,
+			expected:           []uint64{3},
+		},
+	} {
+		t.Run(testCase.description, func(t *testing.T) {
+			bl := blacklist{
+				prevMD:             testCase.prevMD,
+				currView:           testCase.currView,
+				leaderID:           getLeaderID(testCase.currView, nil, nodes, testCase.prevMD.BlackList),
+				nodes:              nodes,
+				decisionsPerLeader: testCase.decisionsPerLeader,
+				f:                  2,
+			}
+			assert.Equal(t, testCase.expected, bl.computeUpdate(testCase.prevCommitSigners))
+		})
+	}
+}
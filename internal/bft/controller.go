@@ -7,10 +7,13 @@ package bft
 
 import (
 	"sync"
+	"sync/atomic"
+	"time"
 
 	"github.com/SmartBFT-Go/consensus/pkg/api"
 	"github.com/SmartBFT-Go/consensus/pkg/types"
 	protos "github.com/SmartBFT-Go/consensus/smartbftprotos"
+	"github.com/golang/protobuf/proto"
 )
 
 //go:generate mockery -dir . -name Decider -case underscore -output ./mocks/
@@ -23,6 +26,21 @@ type FailureDetector interface {
 	Complain(stopView bool)
 }
 
+//go:generate mockery -dir . -name Halter -case underscore -output ./mocks/
+type Halter interface {
+	// Halt stops the node from voting because of a fatal error.
+	Halt(err error)
+}
+
+// haltOrPanic halts the node by the halter, or panics if no halter is configured.
+func haltOrPanic(halter Halter, logger api.Logger, err error) {
+	if halter == nil {
+		logger.Panicf("%v", err)
+		return
+	}
+	halter.Halt(err)
+}
+
 //go:generate mockery -dir . -name Batcher -case underscore -output ./mocks/
 type Batcher interface {
 	NextBatch() [][]byte
@@ -35,8 +53,9 @@ type Batcher interface {
 //go:generate mockery -dir . -name RequestPool -case underscore -output ./mocks/
 
 type RequestPool interface {
-	Prune(predicate func([]byte) error)
+	Prune(verify func([][]byte) []error)
 	Submit(request []byte) error
+	TrySubmit(request []byte) error
 	Size() int
 	NextRequests(n int) [][]byte
 	RemoveRequest(request types.RequestInfo) error
@@ -86,14 +105,43 @@ type Controller struct {
 	ProposerBuilder  ProposerBuilder
 	Checkpoint       *types.Checkpoint
 	ViewChanger      *ViewChanger
-
-	quorum int
-	nodes  []uint64
+	Decisions        *DecisionHistory
+	LeaderRotation   api.LeaderRotation
+	// If positive, the leader is rotated after this number of decisions in a view
+	DecisionsPerLeader uint64
+	FaultModel         types.FaultModel
+	Weights            map[uint64]uint64
+	MessageLimits      MessageLimits
+	// Buffers the chunks and dissemination messages of each sender, which aren't passed to the view or the view changer
+	FlowControl      FlowControl
+	RejectionHandler api.MessageRejectionHandler
+	Authenticator    api.MessageAuthenticator
+	// If set, messages of incompatible protocol versions are rejected
+	ProtocolVersions *ProtocolVersions
+	// If set, the outstanding prepared proposal is rolled back when the node synchronizes past it
+	Speculation *Speculation
+	// If set, the leader proposes the in flight proposal a new view adopted instead of a new batch
+	InFlight *InFlightData
+	// If set, serves requests to nodes which are missing them to assemble proposals
+	Dissemination *Dissemination
+	// If set, reconstructs pre-prepares the leader broadcast in erasure coded chunks
+	ErasureCoding *ErasureCodedComm
+	// If set, verifies requests forwarded by other nodes in batches by its workers, instead of by the calling goroutine
+	VerificationPool *VerificationPool
+	RequestTracer    api.RequestTracer
+	// FatalErrorHandler is notified when the controller halts
+	FatalErrorHandler api.FatalErrorHandler
+
+	quorum    int
+	nodes     []uint64
+	validator *MessageValidator
+	incMsgs   *Inbox
 
 	currView Proposer
 
 	currViewLock   sync.RWMutex
 	currViewNumber uint64
+	currSequence   uint64
 
 	viewChange    chan viewInfo
 	abortViewChan chan struct{}
@@ -101,13 +149,20 @@ type Controller struct {
 	stopOnce sync.Once
 	stopChan chan struct{}
 
+	haltOnce sync.Once
+	haltChan chan struct{}
+	fatalErr atomic.Value // holds a *types.FatalError
+
 	syncChan             chan struct{}
 	decisionChan         chan decision
 	deliverChan          chan struct{}
 	leaderToken          chan struct{}
+	forwardedRequests    chan forwardedRequest
 	verificationSequence uint64
 
 	controllerDone sync.WaitGroup
+
+	scopedLogger *scopedLogger
 }
 
 func (c *Controller) getCurrentViewNumber() uint64 {
@@ -122,6 +177,29 @@ func (c *Controller) setCurrentViewNumber(viewNumber uint64) {
 	defer c.currViewLock.Unlock()
 
 	c.currViewNumber = viewNumber
+	c.scopeLogger()
+}
+
+func (c *Controller) getCurrentSequence() uint64 {
+	c.currViewLock.RLock()
+	defer c.currViewLock.RUnlock()
+
+	return c.currSequence
+}
+
+func (c *Controller) setCurrentSequence(seq uint64) {
+	c.currViewLock.Lock()
+	defer c.currViewLock.Unlock()
+
+	c.currSequence = seq
+	c.scopeLogger()
+}
+
+// scopeLogger scopes the entries with the current view and sequence, and is called with currViewLock held.
+func (c *Controller) scopeLogger() {
+	if c.scopedLogger != nil {
+		c.scopedLogger.scope("view", c.currViewNumber, "seq", c.currSequence)
+	}
 }
 
 // thread safe
@@ -132,38 +210,104 @@ func (c *Controller) iAmTheLeader() (bool, uint64) {
 
 // thread safe
 func (c *Controller) leaderID() uint64 {
-	return getLeaderID(c.getCurrentViewNumber(), c.N, c.nodes)
+	return getLeaderID(c.getCurrentViewNumber(), c.LeaderRotation, c.nodes, blacklistOf(c.Checkpoint))
 }
 
 func (c *Controller) HandleRequest(sender uint64, req []byte) {
+	if c.Halted() {
+		WithFields(c.Logger, "sender", sender).Debugf("Halted, dropping request")
+		return
+	}
 	iAm, leaderID := c.iAmTheLeader()
 	if !iAm {
-		c.Logger.Warnf("Got request from %d but the leader is %d, dropping request", sender, leaderID)
+		WithFields(c.Logger, "sender", sender).Warnf("Got request but the leader is %d, dropping request", leaderID)
 		return
 	}
-	reqInfo, err := c.Verifier.VerifyRequest(req)
+	if c.VerificationPool == nil {
+		reqInfo, err := c.Verifier.VerifyRequest(req)
+		c.addForwardedRequest(forwardedRequest{sender: sender, request: req}, reqInfo, err, true)
+		return
+	}
+	// The request is verified by the verification pool, which bounds the verifications
+	// of requests arriving concurrently from many nodes by the number of its workers.
+	select {
+	case c.forwardedRequests <- forwardedRequest{sender: sender, request: req}:
+	default:
+		WithFields(c.Logger, "sender", sender).Warnf("Forwarded requests queue is full, dropping request")
+	}
+}
+
+// verifyForwardedRequests verifies the forwarded requests by the verification pool, along with the requests
+// which arrived meanwhile, and adds the valid ones to the request pool.
+func (c *Controller) verifyForwardedRequests() {
+	for {
+		var batch []forwardedRequest
+		select {
+		case <-c.stopChan:
+			return
+		case fr := <-c.forwardedRequests:
+			batch = append(batch, fr)
+		}
+		for len(batch) < maxForwardedRequestsBatch && len(c.forwardedRequests) > 0 {
+			batch = append(batch, <-c.forwardedRequests)
+		}
+		c.VerificationPool.Go(func() {
+			requests := make([][]byte, len(batch))
+			for i, fr := range batch {
+				requests[i] = fr.request
+			}
+			infos, errs := c.VerificationPool.verifyRequests(c.Verifier, requests)
+			for i, fr := range batch {
+				// Workers must not block, as the request pool is drained only once the node makes progress
+				c.addForwardedRequest(fr, infos[i], errs[i], false)
+			}
+		})
+	}
+}
+
+func (c *Controller) addForwardedRequest(fr forwardedRequest, reqInfo types.RequestInfo, err error, wait bool) {
 	if err != nil {
-		c.Logger.Warnf("Got bad request from %d: %v", sender, err)
+		WithFields(c.Logger, "sender", fr.sender).Warnf("Got bad request: %v", err)
 		return
 	}
-	c.Logger.Debugf("Got request from %d", sender)
-	c.addRequest(reqInfo, req)
+	WithFields(c.Logger, "sender", fr.sender).Debugf("Got request")
+	if wait {
+		c.addRequest(reqInfo, fr.request)
+		return
+	}
+	c.requestSubmitted(reqInfo, c.RequestPool.TrySubmit(fr.request))
+}
+
+// verifyRequests verifies the requests in parallel by the verification pool, and serially if there is none.
+func (c *Controller) verifyRequests(requests [][]byte) ([]types.RequestInfo, []error) {
+	if c.VerificationPool == nil {
+		infos, errs, _ := verifyRequests(c.Verifier, requests)
+		return infos, errs
+	}
+	return c.VerificationPool.VerifyRequests(c.Verifier, requests)
 }
 
 // SubmitRequest Submits a request to go through consensus.
 func (c *Controller) SubmitRequest(request []byte) error {
+	if err := c.FatalError(); err != nil {
+		return err
+	}
 	info := c.RequestInspector.RequestID(request)
 	return c.addRequest(info, request)
 }
 
 func (c *Controller) addRequest(info types.RequestInfo, request []byte) error {
-	err := c.RequestPool.Submit(request)
+	return c.requestSubmitted(info, c.RequestPool.Submit(request))
+}
+
+func (c *Controller) requestSubmitted(info types.RequestInfo, err error) error {
 	if err != nil {
 		c.Logger.Warnf("Request %s was not submitted, error: %s", info, err)
 		return err
 	}
 
 	c.Logger.Debugf("Request %s was submitted", info)
+	c.traceRequest(types.RequestEvent{Type: types.RequestSubmitted, Request: info})
 
 	return nil
 }
@@ -179,6 +323,7 @@ func (c *Controller) OnRequestTimeout(request []byte, info types.RequestInfo) {
 
 	c.Logger.Warnf("Request %s timeout expired, forwarding request to leader: %d", info, leaderID)
 	c.Comm.SendTransaction(leaderID, request)
+	c.traceRequest(types.RequestEvent{Type: types.RequestForwarded, Request: info, Leader: leaderID})
 
 	return
 }
@@ -192,8 +337,14 @@ func (c *Controller) OnLeaderFwdRequestTimeout(request []byte, info types.Reques
 		return
 	}
 
+	if c.Halted() {
+		c.Logger.Warnf("Request %s leader-forwarding timeout expired, but halted; not complaining about leader: %d", info, leaderID)
+		return
+	}
+
 	c.Logger.Warnf("Request %s leader-forwarding timeout expired, complaining about leader: %d", info, leaderID)
 	c.FailureDetector.Complain(true)
+	c.traceRequest(types.RequestEvent{Type: types.RequestLeaderComplained, Request: info, Leader: leaderID})
 
 	return
 }
@@ -202,6 +353,7 @@ func (c *Controller) OnLeaderFwdRequestTimeout(request []byte, info types.Reques
 // Called by the request-pool timeout goroutine.
 func (c *Controller) OnAutoRemoveTimeout(requestInfo types.RequestInfo) {
 	c.Logger.Warnf("Request %s auto-remove timeout expired, removed from the request pool", requestInfo)
+	c.traceRequest(types.RequestEvent{Type: types.RequestAutoRemoved, Request: requestInfo})
 }
 
 // OnHeartbeatTimeout is called when the heartbeat timeout expires.
@@ -221,30 +373,118 @@ func (c *Controller) OnHeartbeatTimeout(view uint64, leaderID uint64) {
 		return
 	}
 
+	if c.Halted() {
+		c.Logger.Debugf("Heartbeat timeout expired, but halted; not complaining about leader: %d", leaderID)
+		return
+	}
+
 	c.Logger.Warnf("Heartbeat timeout expired, complaining about leader: %d", leaderID)
 	c.FailureDetector.Complain(true)
 }
 
 // ProcessMessages dispatches the incoming message to the required component
 func (c *Controller) ProcessMessages(sender uint64, m *protos.Message) {
+	if c.Halted() {
+		WithFields(c.Logger, "sender", sender).Debugf("Halted, ignoring message %v", m)
+		return
+	}
+
+	if c.Authenticator != nil {
+		if err := VerifyMessageAuthentication(c.Authenticator, sender, m); err != nil {
+			c.rejectMessage(sender, m, err)
+			return
+		}
+	}
+
+	if c.ProtocolVersions != nil {
+		if err := c.ProtocolVersions.Check(sender, m); err != nil {
+			c.rejectMessage(sender, m, err)
+			return
+		}
+	}
+
+	if err := c.validator.ValidateMessage(sender, m, c.getCurrentViewNumber(), c.getCurrentSequence()); err != nil {
+		c.rejectMessage(sender, m, err)
+		return
+	}
+
 	switch m.GetContent().(type) {
-	case *protos.Message_PrePrepare, *protos.Message_Prepare, *protos.Message_Commit:
+	case *protos.Message_PrePrepare, *protos.Message_Prepare, *protos.Message_Commit, *protos.Message_VoteCertificate:
 		c.currViewLock.RLock()
 		view := c.currView
 		c.currViewLock.RUnlock()
 		view.HandleMessage(sender, m)
 	case *protos.Message_ViewChange, *protos.Message_ViewData, *protos.Message_NewView:
 		c.ViewChanger.HandleMessage(sender, m)
-		c.Logger.Debugf("Node %d handled view changer message from %d", c.ID, sender)
+		WithFields(c.Logger, "sender", sender).Debugf("Handled view changer message")
 
 	case *protos.Message_HeartBeat:
 		c.LeaderMonitor.ProcessMsg(sender, m)
 
+	case *protos.Message_PrePrepareChunk, *protos.Message_FetchRequests, *protos.Message_FetchedRequests:
+		if c.incMsgs == nil {
+			WithFields(c.Logger, "sender", sender).Debugf("Dropping message, as the controller isn't started")
+			return
+		}
+		c.incMsgs.Put(sender, m)
+
 	case *protos.Message_Error:
-		c.Logger.Debugf("Error message handling not yet implemented, ignoring message: %v, from %d", m, sender)
+		WithFields(c.Logger, "sender", sender).Debugf("Error message handling not yet implemented, ignoring message: %v", m)
 
 	default:
-		c.Logger.Warnf("Unexpected message type, ignoring")
+		WithFields(c.Logger, "sender", sender).Warnf("Unexpected message type, ignoring")
+	}
+}
+
+// processMessages handles the chunks and dissemination messages buffered in the inbox,
+// so that they are subject to the same flow control as the messages passed to the view and the view changer.
+func (c *Controller) processMessages() {
+	for {
+		select {
+		case <-c.stopChan:
+			return
+		case <-c.incMsgs.Ready():
+			sender, m, ok := c.incMsgs.Get()
+			if !ok {
+				continue
+			}
+			if chunk := m.GetPrePrepareChunk(); chunk != nil {
+				c.handlePrePrepareChunk(sender, chunk)
+			} else if c.Dissemination != nil {
+				c.Dissemination.HandleMessage(sender, m)
+			} else {
+				WithFields(c.Logger, "sender", sender).Debugf("Requests aren't disseminated, ignoring message")
+			}
+			c.incMsgs.Done()
+		}
+	}
+}
+
+// handlePrePrepareChunk passes the pre-prepare to the view once it is reconstructed from its chunks,
+// as if the leader sent it whole.
+func (c *Controller) handlePrePrepareChunk(sender uint64, chunk *protos.PrePrepareChunk) {
+	if c.ErasureCoding == nil {
+		WithFields(c.Logger, "sender", sender).Debugf("Pre-prepares aren't erasure coded, ignoring chunk")
+		return
+	}
+	m := c.ErasureCoding.HandleChunk(sender, chunk, c.getCurrentViewNumber(), c.getCurrentSequence(), c.leaderID())
+	if m == nil {
+		return
+	}
+	if err := c.validator.ValidateMessage(chunk.Leader, m, c.getCurrentViewNumber(), c.getCurrentSequence()); err != nil {
+		c.rejectMessage(chunk.Leader, m, err)
+		return
+	}
+	c.currViewLock.RLock()
+	view := c.currView
+	c.currViewLock.RUnlock()
+	view.HandleMessage(chunk.Leader, m)
+}
+
+func (c *Controller) rejectMessage(sender uint64, m *protos.Message, reason error) {
+	WithFields(c.Logger, "sender", sender).Warnf("Rejected message %v: %v", m, reason)
+	if c.RejectionHandler != nil {
+		c.RejectionHandler.OnMessageRejected(sender, m, reason)
 	}
 }
 
@@ -255,6 +495,8 @@ func (c *Controller) startView(proposalSequence uint64) {
 	c.currViewLock.Lock()
 	c.currView = view
 	c.currView.Start()
+	c.currSequence = proposalSequence
+	c.scopeLogger()
 	c.currViewLock.Unlock()
 
 	role := Follower
@@ -267,6 +509,11 @@ func (c *Controller) startView(proposalSequence uint64) {
 }
 
 func (c *Controller) changeView(newViewNumber uint64, newProposalSequence uint64) {
+	if c.Halted() {
+		c.Logger.Debugf("Got view change to %d but halted, ignoring", newViewNumber)
+		return
+	}
+
 	// Drain the leader token in case we held it,
 	// so we won't start proposing after view change.
 	c.relinquishLeaderToken()
@@ -277,7 +524,7 @@ func (c *Controller) changeView(newViewNumber uint64, newProposalSequence uint64
 		return
 	}
 	// Kill current view
-	c.Logger.Debugf("Aborting current view with number %d", latestView)
+	c.Logger.Debugf("Aborting current view")
 	c.currView.Abort()
 
 	c.setCurrentViewNumber(newViewNumber)
@@ -296,7 +543,7 @@ func (c *Controller) abortView() {
 	c.relinquishLeaderToken()
 
 	// Kill current view
-	c.Logger.Debugf("Aborting current view with number %d", c.getCurrentViewNumber())
+	c.Logger.Debugf("Aborting current view")
 	c.currView.Abort()
 }
 
@@ -306,7 +553,7 @@ func (c *Controller) Sync() {
 
 // AbortView makes the controller abort the current view
 func (c *Controller) AbortView() {
-	c.Logger.Debugf("AbortView, the current view num is %d", c.getCurrentViewNumber())
+	c.Logger.Debugf("AbortView")
 
 	// don't close batcher, it will be closed in ViewChanged
 
@@ -338,6 +585,13 @@ func (c *Controller) getNextBatch() [][]byte {
 }
 
 func (c *Controller) propose() {
+	if c.InFlight != nil {
+		if adopted := c.InFlight.Adopted(c.getCurrentSequence()); adopted != nil {
+			c.Logger.Infof("Leader proposing the in flight proposal adopted by the new view")
+			c.currView.Propose(*adopted)
+			return
+		}
+	}
 	nextBatch := c.getNextBatch()
 	if len(nextBatch) == 0 {
 		// If our next batch is empty,
@@ -346,10 +600,14 @@ func (c *Controller) propose() {
 		return
 	}
 	metadata := c.currView.GetMetadata()
+	if c.Halted() {
+		return
+	}
 	proposal, remainder := c.Assembler.AssembleProposal(metadata, nextBatch)
 	if len(remainder) != 0 {
 		c.Batcher.BatchRemainder(remainder)
 	}
+	c.traceBatchedRequests(nextBatch, remainder)
 	c.Logger.Debugf("Leader proposing proposal: %v", proposal)
 	c.currView.Propose(proposal)
 }
@@ -365,16 +623,29 @@ func (c *Controller) run() {
 	for {
 		select {
 		case d := <-c.decisionChan:
-			c.Application.Deliver(d.proposal, d.signatures)
-			c.Checkpoint.Set(d.proposal, d.signatures)
-			c.Logger.Debugf("Node %d delivered proposal", c.ID)
-			c.removeDeliveredFromPool(d)
+			// A decision of a view which raced with a synchronization may be of a sequence which was already delivered,
+			// so it is checked against the decisions delivered or synchronized at its own sequence.
+			seq := c.decisionSequence(d.proposal)
+			cert := types.DecisionCertificate{Proposal: d.proposal, Signatures: d.signatures}
+			delivered := false
+			if evidence := c.Decisions.Check(seq, cert); evidence != nil {
+				c.Halt(evidence)
+			} else if current := c.getCurrentSequence(); seq < current {
+				c.Logger.Infof("Decided sequence %d but already at sequence %d, not delivering it again", seq, current)
+			} else {
+				c.deliver(d)
+				delivered = true
+			}
 			select {
 			case c.deliverChan <- struct{}{}:
 			case <-c.stopChan:
 				return
 			}
+			if !delivered || c.Halted() {
+				continue
+			}
 			c.maybePruneRevokedRequests()
+			c.maybeRotateLeader(d.proposal)
 			if iAm, _ := c.iAmTheLeader(); iAm {
 				c.acquireLeaderToken()
 			}
@@ -382,6 +653,8 @@ func (c *Controller) run() {
 			c.changeView(newView.viewNumber, newView.proposalSeq)
 		case <-c.abortViewChan:
 			c.abortView()
+		case <-c.haltChan:
+			c.abortView()
 		case <-c.stopChan:
 			return
 		case <-c.leaderToken:
@@ -392,6 +665,33 @@ func (c *Controller) run() {
 	}
 }
 
+func (c *Controller) deliver(d decision) {
+	c.Application.Deliver(d.proposal, d.signatures)
+	c.Checkpoint.Set(d.proposal, d.signatures)
+	seq := c.getCurrentSequence()
+	c.Decisions.Record(seq, types.DecisionCertificate{Proposal: d.proposal, Signatures: d.signatures})
+	for _, reqInfo := range d.requests {
+		c.traceRequest(types.RequestEvent{Type: types.RequestCommitted, Request: reqInfo, Sequence: seq})
+	}
+	c.setCurrentSequence(seq + 1)
+	c.Logger.Debugf("Delivered proposal")
+	c.removeDeliveredFromPool(d)
+}
+
+// decisionSequence returns the sequence in the metadata of the decided proposal,
+// or the current sequence if the proposal carries no metadata or it is malformed.
+func (c *Controller) decisionSequence(proposal types.Proposal) uint64 {
+	if len(proposal.Metadata) == 0 {
+		return c.getCurrentSequence()
+	}
+	md := &protos.ViewMetadata{}
+	if err := proto.Unmarshal(proposal.Metadata, md); err != nil {
+		c.Logger.Warnf("Failed unmarshaling the metadata of the decided proposal: %v", err)
+		return c.getCurrentSequence()
+	}
+	return md.LatestSequence
+}
+
 func (c *Controller) sync() {
 	// Block any concurrent sync attempt.
 	c.grabSyncToken()
@@ -403,8 +703,51 @@ func (c *Controller) sync() {
 	md, vSeq := c.Synchronizer.Sync()
 	c.verificationSequence = vSeq
 	c.Logger.Infof("Synchronized to view %d with sequence %d", md.ViewId, md.LatestSequence)
-	c.ViewChanger.InformNewView(md.ViewId)
-	c.viewChange <- viewInfo{viewNumber: md.ViewId, proposalSeq: md.LatestSequence + 1}
+	if c.Speculation != nil {
+		c.Speculation.Synced(md.LatestSequence)
+	}
+	if ds, isDecisionSynchronizer := c.Synchronizer.(api.DecisionSynchronizer); isDecisionSynchronizer && md.LatestSequence > 0 {
+		proposal, signatures := ds.LastSyncedDecision()
+		cert := types.DecisionCertificate{Proposal: proposal, Signatures: signatures}
+		// Only a certificate signed by a quorum proves a fork, otherwise any node could halt us
+		if err := verifyCertificate(cert, c.quorum, consenterSigVerifier(c.Verifier, c.FaultModel), c.Weights); err != nil {
+			c.Logger.Warnf("The decision synchronized at sequence %d isn't signed by a quorum: %v", md.LatestSequence, err)
+		} else if evidence := c.Decisions.Check(md.LatestSequence, cert); evidence != nil {
+			c.Halt(evidence)
+			return
+		} else {
+			c.Decisions.Record(md.LatestSequence, cert)
+		}
+	}
+	if md.LatestSequence+1 > c.getCurrentSequence() {
+		c.setCurrentSequence(md.LatestSequence + 1)
+	}
+	view := md.ViewId
+	if md.LatestSequence > 0 {
+		view = ViewAfterDecision(&md, c.DecisionsPerLeader)
+	}
+	c.ViewChanger.InformNewView(view)
+	c.viewChange <- viewInfo{viewNumber: view, proposalSeq: md.LatestSequence + 1}
+}
+
+// maybeRotateLeader moves to the next view if the decision is the last one the leader makes in its view.
+// Since all nodes deliver the same decision, they all move to the next view at the same sequence.
+func (c *Controller) maybeRotateLeader(proposal types.Proposal) {
+	if c.DecisionsPerLeader == 0 {
+		return
+	}
+	md := &protos.ViewMetadata{}
+	if err := proto.Unmarshal(proposal.Metadata, md); err != nil {
+		c.Logger.Warnf("Failed unmarshaling the metadata of the delivered proposal: %v", err)
+		return
+	}
+	nextView := ViewAfterDecision(md, c.DecisionsPerLeader)
+	if nextView <= c.getCurrentViewNumber() {
+		return
+	}
+	c.Logger.Infof("Made %d decisions in view %d, rotating the leader to view %d", md.DecisionsInView+1, md.ViewId, nextView)
+	c.ViewChanger.InformNewView(nextView)
+	c.changeView(nextView, md.LatestSequence+1)
 }
 
 func (c *Controller) grabSyncToken() {
@@ -430,16 +773,23 @@ func (c *Controller) maybePruneRevokedRequests() {
 	c.verificationSequence = newVerSqn
 
 	c.Logger.Infof("Verification sequence changed: %d --> %d", oldVerSqn, newVerSqn)
-	c.RequestPool.Prune(func(req []byte) error {
-		_, err := c.Verifier.VerifyRequest(req)
-		return err
+	c.RequestPool.Prune(func(requests [][]byte) []error {
+		_, errs := c.verifyRequests(requests)
+		for i, err := range errs {
+			if err != nil {
+				c.traceRequest(types.RequestEvent{Type: types.RequestRevoked, Request: c.RequestInspector.RequestID(requests[i])})
+			}
+		}
+		return errs
 	})
 
 	var newRemainder [][]byte
-	for _, req := range c.Batcher.PopRemainder() {
-		reqInf, err := c.Verifier.VerifyRequest(req)
-		if err != nil {
-			c.Logger.Warnf("Revoking request %v due to %v", reqInf, err)
+	remainder := c.Batcher.PopRemainder()
+	reqInfos, errs := c.verifyRequests(remainder)
+	for i, req := range remainder {
+		if err := errs[i]; err != nil {
+			c.Logger.Warnf("Revoking request %v due to %v", reqInfos[i], err)
+			c.traceRequest(types.RequestEvent{Type: types.RequestRevoked, Request: c.RequestInspector.RequestID(req)})
 			continue
 		}
 		newRemainder = append(newRemainder, req)
@@ -467,19 +817,28 @@ func (c *Controller) relinquishLeaderToken() {
 func (c *Controller) Start(startViewNumber uint64, startProposalSequence uint64) {
 	c.controllerDone.Add(1)
 	c.stopOnce = sync.Once{}
-	c.syncChan = make(chan struct{})
+	// Holds the sync token, so a synchronization requested while the controller is busy is not lost
+	c.syncChan = make(chan struct{}, 1)
 	c.stopChan = make(chan struct{})
 	c.leaderToken = make(chan struct{}, 1)
 	c.decisionChan = make(chan decision)
 	c.deliverChan = make(chan struct{})
 	c.viewChange = make(chan viewInfo, 1)
 	c.abortViewChan = make(chan struct{})
+	c.haltChan = make(chan struct{}, 1)
+	c.forwardedRequests = make(chan forwardedRequest, forwardedRequestsQueueSize)
+	c.incMsgs = NewInbox(c.FlowControl, reportDroppedMessage(c.Logger, c.RejectionHandler))
+	if _, isStructured := c.Logger.(api.StructuredLogger); isStructured {
+		c.scopedLogger = newScopedLogger(c.Logger, "view", startViewNumber, "seq", startProposalSequence)
+		c.Logger = c.scopedLogger
+	}
 
-	Q, F := computeQuorum(c.N)
+	Q, F := computeWeightedQuorum(c.N, c.Weights, c.FaultModel)
 	c.Logger.Debugf("The number of nodes (N) is %d, F is %d, and the quorum size is %d", c.N, F, Q)
 	c.quorum = Q
 
-	c.nodes = c.Comm.Nodes()
+	c.nodes = sortedNodes(c.Comm.Nodes())
+	c.validator = NewMessageValidator(c.nodes, c.MessageLimits)
 
 	c.currViewNumber = startViewNumber
 	c.startView(startProposalSequence)
@@ -491,6 +850,20 @@ func (c *Controller) Start(startViewNumber uint64, startProposalSequence uint64)
 		defer c.controllerDone.Done()
 		c.run()
 	}()
+
+	c.controllerDone.Add(1)
+	go func() {
+		defer c.controllerDone.Done()
+		c.processMessages()
+	}()
+
+	if c.VerificationPool != nil {
+		c.controllerDone.Add(1)
+		go func() {
+			defer c.controllerDone.Done()
+			c.verifyForwardedRequests()
+		}()
+	}
 }
 
 func (c *Controller) close() {
@@ -532,6 +905,35 @@ func (c *Controller) stopped() bool {
 	}
 }
 
+// Halt moves the controller to a halted state because of a fatal error.
+// A halted controller aborts its view and ignores incoming messages and requests,
+// but keeps running until it is stopped. Only the first fatal error is reported.
+func (c *Controller) Halt(err error) {
+	c.haltOnce.Do(func() {
+		fatalErr := &types.FatalError{Node: c.ID, Err: err}
+		c.fatalErr.Store(fatalErr)
+		c.Logger.Errorf("Halting: %v", err)
+		c.haltChan <- struct{}{}
+		if c.FatalErrorHandler != nil {
+			c.FatalErrorHandler.OnFatalError(fatalErr)
+		}
+	})
+}
+
+// Halted returns whether the controller is halted.
+func (c *Controller) Halted() bool {
+	return c.FatalError() != nil
+}
+
+// FatalError returns the error the controller halted because of, or nil if it is not halted.
+func (c *Controller) FatalError() error {
+	fatalErr, _ := c.fatalErr.Load().(*types.FatalError)
+	if fatalErr == nil {
+		return nil
+	}
+	return fatalErr
+}
+
 // Decide delivers the decision to the application
 func (c *Controller) Decide(proposal types.Proposal, signatures []types.Signature, requests []types.RequestInfo) {
 	select {
@@ -557,8 +959,40 @@ func (c *Controller) removeDeliveredFromPool(d decision) {
 	for _, reqInfo := range d.requests {
 		if err := c.RequestPool.RemoveRequest(reqInfo); err != nil {
 			c.Logger.Warnf("Error during remove of request %s from the pool : %s", reqInfo, err)
+			continue
+		}
+		c.traceRequest(types.RequestEvent{Type: types.RequestRemoved, Request: reqInfo})
+	}
+}
+
+// traceBatchedRequests traces the requests of the batch which were assembled into the proposal,
+// which are all the requests except for the remainder.
+func (c *Controller) traceBatchedRequests(batch [][]byte, remainder [][]byte) {
+	if c.RequestTracer == nil {
+		return
+	}
+	left := make(map[types.RequestInfo]struct{}, len(remainder))
+	for _, req := range remainder {
+		left[c.RequestInspector.RequestID(req)] = struct{}{}
+	}
+	seq := c.getCurrentSequence()
+	for _, req := range batch {
+		info := c.RequestInspector.RequestID(req)
+		if _, isLeft := left[info]; isLeft {
+			continue
 		}
+		c.traceRequest(types.RequestEvent{Type: types.RequestBatched, Request: info, Sequence: seq})
+	}
+}
+
+// traceRequest stamps the event with the time and our ID, and passes it to the tracer, if there is one.
+func (c *Controller) traceRequest(event types.RequestEvent) {
+	if c.RequestTracer == nil {
+		return
 	}
+	event.Time = time.Now()
+	event.Node = c.ID
+	c.RequestTracer.TraceRequest(event)
 }
 
 type viewInfo struct {
@@ -571,3 +1005,13 @@ type decision struct {
 	signatures []types.Signature
 	requests   []types.RequestInfo
 }
+
+const (
+	forwardedRequestsQueueSize = 1000
+	maxForwardedRequestsBatch  = 100
+)
+
+type forwardedRequest struct {
+	sender  uint64
+	request []byte
+}
@@ -9,6 +9,7 @@ import (
 	"io/ioutil"
 	"os"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
@@ -124,6 +125,7 @@ func TestLeaderPropose(t *testing.T) {
 	assembler.On("AssembleProposal", mock.Anything, [][]byte{req}).Return(proposal, [][]byte{}).Once()
 	secondProposal := proposal
 	secondProposal.Metadata = bft.MarshalOrPanic(&protos.ViewMetadata{
+		Version:        bft.MetadataVersion,
 		LatestSequence: 1,
 		ViewId:         1,
 	})
@@ -133,7 +135,7 @@ func TestLeaderPropose(t *testing.T) {
 	comm.On("BroadcastConsensus", mock.Anything).Run(func(args mock.Arguments) {
 		commWG.Done()
 	})
-	comm.On("Nodes").Return([]uint64{11, 17, 23, 37})
+	comm.On("Nodes").Return([]uint64{2, 17, 23, 37})
 	signer := &mocks.SignerMock{}
 	signer.On("Sign", mock.Anything).Return(nil)
 	signer.On("SignProposal", mock.Anything).Return(&types.Signature{
@@ -181,7 +183,7 @@ func TestLeaderPropose(t *testing.T) {
 
 	commWG.Add(1)
 	controller.ProcessMessages(2, prepare)
-	controller.ProcessMessages(3, prepare)
+	controller.ProcessMessages(23, prepare)
 	commWG.Wait()
 
 	controller.ProcessMessages(2, commit2)
@@ -235,6 +237,7 @@ func TestViewChanged(t *testing.T) {
 
 	secondProposal := proposal
 	secondProposal.Metadata = bft.MarshalOrPanic(&protos.ViewMetadata{
+		Version:        bft.MetadataVersion,
 		LatestSequence: 0,
 		ViewId:         2,
 	})
@@ -378,6 +381,152 @@ func TestControllerLeaderRequestHandling(t *testing.T) {
 	}
 }
 
+func TestControllerVerifiesForwardedRequestsInBatches(t *testing.T) {
+	basicLog, err := zap.NewDevelopment()
+	assert.NoError(t, err)
+	log := basicLog.Sugar()
+	batcherClosed := make(chan struct{})
+	batcher := &mocks.Batcher{}
+	batcher.On("Close").Run(func(arguments mock.Arguments) {
+		close(batcherClosed)
+	})
+	batcher.On("NextBatch").Run(func(arguments mock.Arguments) {
+		<-batcherClosed
+	}).Return(nil)
+	leaderMon := &mocks.LeaderMonitor{}
+	leaderMon.On("ChangeRole", mock.Anything, mock.Anything, mock.Anything)
+	leaderMon.On("Close")
+	comm := &mocks.CommMock{}
+	comm.On("Nodes").Return([]uint64{0, 1, 2, 3})
+
+	submitted := make(chan []byte, 5)
+	pool := &mocks.RequestPool{}
+	pool.On("Close")
+	pool.On("TrySubmit", mock.Anything).Run(func(args mock.Arguments) {
+		submitted <- args.Get(0).([]byte)
+	}).Return(nil)
+
+	// The verification of the first request blocks the only worker until released
+	release := make(chan struct{})
+	verifying := make(chan struct{})
+	var once sync.Once
+	verifier := &batchVerifier{VerifierMock: &mocks.VerifierMock{}}
+	verifier.On("VerifyRequest", mock.Anything).Run(func(args mock.Arguments) {
+		once.Do(func() {
+			close(verifying)
+			<-release
+		})
+	}).Return(types.RequestInfo{}, nil)
+
+	vp := bft.NewVerificationPool(1, 1)
+	defer vp.Close()
+
+	controller := &bft.Controller{
+		RequestPool:      pool,
+		LeaderMonitor:    leaderMon,
+		ID:               1,
+		N:                4,
+		Logger:           log,
+		Batcher:          batcher,
+		Comm:             comm,
+		Verifier:         verifier,
+		VerificationPool: vp,
+	}
+	configureProposerBuilder(controller)
+	controller.Start(1, 0)
+
+	controller.HandleRequest(3, []byte{0})
+	<-verifying
+	// Requests are queued while the worker is busy, instead of blocking their senders
+	for i := byte(1); i < 5; i++ {
+		controller.HandleRequest(3, []byte{i})
+	}
+	close(release)
+
+	var requests [][]byte
+	for i := 0; i < 5; i++ {
+		requests = append(requests, <-submitted)
+	}
+	controller.Stop()
+
+	assert.ElementsMatch(t, [][]byte{{0}, {1}, {2}, {3}, {4}}, requests)
+	// The worker, the queue of the pool, and the goroutine waiting for room in it hold a batch each,
+	// so the requests which arrived meanwhile are verified in a single batch
+	assert.True(t, atomic.LoadInt32(&verifier.batches) < 5)
+	pool.AssertNotCalled(t, "Submit", mock.Anything)
+}
+
+func TestControllerLimitsDisseminationMessages(t *testing.T) {
+	basicLog, err := zap.NewDevelopment()
+	assert.NoError(t, err)
+	log := basicLog.Sugar()
+	batcherClosed := make(chan struct{})
+	batcher := &mocks.Batcher{}
+	batcher.On("Close").Run(func(arguments mock.Arguments) {
+		close(batcherClosed)
+	})
+	batcher.On("NextBatch").Run(func(arguments mock.Arguments) {
+		<-batcherClosed
+	}).Return(nil)
+	leaderMon := &mocks.LeaderMonitor{}
+	leaderMon.On("ChangeRole", mock.Anything, mock.Anything, mock.Anything)
+	leaderMon.On("Close")
+	pool := &mocks.RequestPool{}
+	pool.On("Close")
+	rejectionHandler := &mocks.MessageRejectionHandlerMock{}
+	rejectionHandler.On("OnMessageRejected", mock.Anything, mock.Anything, mock.Anything)
+
+	sent := make(chan *protos.Message, 10)
+	comm := &mocks.CommMock{}
+	comm.On("Nodes").Return([]uint64{0, 1, 2, 3})
+	comm.On("SendConsensus", uint64(3), mock.Anything).Run(func(args mock.Arguments) {
+		sent <- args.Get(1).(*protos.Message)
+	})
+
+	insp := &testRequestInspector{}
+	request := makeTestRequest("1", "1", "foo")
+	requests := bft.NewPool(log, insp, noopTimeoutHandler, bft.PoolOptions{QueueSize: 10})
+	assert.NoError(t, requests.Submit(request))
+
+	controller := &bft.Controller{
+		RequestPool:      pool,
+		LeaderMonitor:    leaderMon,
+		ID:               1,
+		N:                4,
+		Logger:           log,
+		Batcher:          batcher,
+		Comm:             comm,
+		FlowControl:      bft.FlowControl{RateLimit: 1},
+		RejectionHandler: rejectionHandler,
+		Dissemination: &bft.Dissemination{
+			Pool:      requests,
+			Inspector: insp,
+			Comm:      comm,
+			Logger:    log,
+		},
+	}
+	configureProposerBuilder(controller)
+	controller.Start(1, 0)
+
+	info := insp.RequestID(request)
+	fetch := &protos.Message{
+		Content: &protos.Message_FetchRequests{
+			FetchRequests: &protos.FetchRequests{
+				RequestIds: []*protos.RequestID{{Id: info.ID, ClientId: info.ClientID}},
+			},
+		},
+	}
+	// A flood of fetches is subject to the rate limit of its sender
+	for i := 0; i < 10; i++ {
+		controller.ProcessMessages(3, fetch)
+	}
+	assert.Equal(t, [][]byte{request}, (<-sent).GetFetchedRequests().Requests)
+	controller.Stop()
+
+	rejectionHandler.AssertNumberOfCalls(t, "OnMessageRejected", 9)
+	assert.Empty(t, sent)
+}
+
 func createView(c *bft.Controller, leader, proposalSequence, viewNum uint64, quorumSize int) *bft.View {
 	return &bft.View{
 		N:                c.N,
@@ -422,6 +571,7 @@ func TestSyncInform(t *testing.T) {
 
 	secondProposal := proposal
 	secondProposal.Metadata = bft.MarshalOrPanic(&protos.ViewMetadata{
+		Version:        bft.MetadataVersion,
 		LatestSequence: 2,
 		ViewId:         2,
 	})
@@ -524,3 +674,426 @@ func TestSyncInform(t *testing.T) {
 	vc.Stop()
 	wal.Close()
 }
+
+func TestSyncWhileBusy(t *testing.T) {
+	basicLog, err := zap.NewDevelopment()
+	assert.NoError(t, err)
+	log := basicLog.Sugar()
+	batcher := &mocks.Batcher{}
+	batcher.On("Close")
+	batcher.On("Reset")
+	pool := &mocks.RequestPool{}
+	pool.On("Close")
+	comm := &mocks.CommMock{}
+	comm.On("Nodes").Return([]uint64{0, 1, 2, 3})
+
+	// The controller is busy changing to view 2 until released
+	changingView := make(chan struct{})
+	release := make(chan struct{})
+	leaderMon := &mocks.LeaderMonitor{}
+	leaderMon.On("ChangeRole", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		if args.Get(1).(uint64) == 2 {
+			close(changingView)
+			<-release
+		}
+	})
+	leaderMon.On("Close")
+
+	synced := make(chan struct{}, 1)
+	synchronizer := &mocks.SynchronizerMock{}
+	synchronizer.On("Sync").Run(func(args mock.Arguments) {
+		synced <- struct{}{}
+	}).Return(protos.ViewMetadata{ViewId: 1}, uint64(0))
+
+	reqTimer := &mocks.RequestsTimer{}
+	reqTimer.On("StopTimers")
+	vc := &bft.ViewChanger{
+		SelfID:        3,
+		N:             4,
+		Logger:        log,
+		Comm:          comm,
+		RequestsTimer: reqTimer,
+		Ticker:        make(chan time.Time),
+		Controller:    &mocks.ViewController{},
+	}
+
+	controller := &bft.Controller{
+		Batcher:       batcher,
+		RequestPool:   pool,
+		LeaderMonitor: leaderMon,
+		ID:            3, // not the leader
+		N:             4,
+		Logger:        log,
+		Comm:          comm,
+		Synchronizer:  synchronizer,
+		ViewChanger:   vc,
+	}
+	configureProposerBuilder(controller)
+
+	vc.Start(1)
+	controller.Start(1, 0)
+
+	controller.ViewChanged(2, 1)
+	<-changingView
+	// Synchronization is requested while the controller isn't waiting for it, and isn't lost
+	controller.Sync()
+	close(release)
+
+	select {
+	case <-synced:
+	case <-time.After(10 * time.Second):
+		t.Fatal("synchronization requested while the controller was busy was lost")
+	}
+
+	controller.Stop()
+	vc.Stop()
+}
+
+func TestControllerTracesRequestTimeouts(t *testing.T) {
+	basicLog, err := zap.NewDevelopment()
+	assert.NoError(t, err)
+	log := basicLog.Sugar()
+	batcher := &mocks.Batcher{}
+	batcher.On("Close")
+	pool := &mocks.RequestPool{}
+	pool.On("Close")
+	leaderMon := &mocks.LeaderMonitor{}
+	leaderMon.On("ChangeRole", mock.Anything, mock.Anything, mock.Anything)
+	leaderMon.On("Close")
+	comm := &mocks.CommMock{}
+	comm.On("Nodes").Return([]uint64{0, 1, 2, 3})
+	comm.On("SendTransaction", uint64(1), []byte{1})
+	failureDetector := &mocks.FailureDetector{}
+	failureDetector.On("Complain", true)
+
+	var events []types.RequestEvent
+	tracer := &mocks.RequestTracerMock{}
+	tracer.On("TraceRequest", mock.Anything).Run(func(args mock.Arguments) {
+		event := args.Get(0).(types.RequestEvent)
+		assert.False(t, event.Time.IsZero())
+		event.Time = time.Time{}
+		events = append(events, event)
+	})
+
+	controller := &bft.Controller{
+		Batcher:         batcher,
+		RequestPool:     pool,
+		LeaderMonitor:   leaderMon,
+		ID:              2, // not the leader
+		N:               4,
+		Logger:          log,
+		Comm:            comm,
+		FailureDetector: failureDetector,
+		RequestTracer:   tracer,
+	}
+	configureProposerBuilder(controller)
+
+	controller.Start(1, 0)
+
+	info := types.RequestInfo{ClientID: "alice", ID: "1"}
+	controller.OnRequestTimeout([]byte{1}, info)
+	controller.OnLeaderFwdRequestTimeout([]byte{1}, info)
+	controller.OnAutoRemoveTimeout(info)
+	controller.Stop()
+
+	assert.Equal(t, []types.RequestEvent{
+		{Node: 2, Type: types.RequestForwarded, Request: info, Leader: 1},
+		{Node: 2, Type: types.RequestLeaderComplained, Request: info, Leader: 1},
+		{Node: 2, Type: types.RequestAutoRemoved, Request: info},
+	}, events)
+}
+
+func TestControllerHalt(t *testing.T) {
+	basicLog, err := zap.NewDevelopment()
+	assert.NoError(t, err)
+	log := basicLog.Sugar()
+	batcher := &mocks.Batcher{}
+	batcher.On("Close")
+	pool := &mocks.RequestPool{}
+	pool.On("Close")
+	leaderMon := &mocks.LeaderMonitor{}
+	leaderMon.On("ChangeRole", mock.Anything, mock.Anything, mock.Anything)
+	leaderMon.On("Close")
+	comm := &mocks.CommMock{}
+	comm.On("Nodes").Return([]uint64{0, 1, 2, 3})
+	failureDetector := &mocks.FailureDetector{}
+	fatalErrorHandler := &mocks.FatalErrorHandlerMock{}
+	fatalErrorHandler.On("OnFatalError", mock.Anything)
+
+	controller := &bft.Controller{
+		Batcher:           batcher,
+		RequestPool:       pool,
+		LeaderMonitor:     leaderMon,
+		ID:                2, // not the leader
+		N:                 4,
+		Logger:            log,
+		Comm:              comm,
+		FailureDetector:   failureDetector,
+		FatalErrorHandler: fatalErrorHandler,
+	}
+	configureProposerBuilder(controller)
+
+	controller.Start(1, 0)
+	assert.False(t, controller.Halted())
+	assert.NoError(t, controller.FatalError())
+
+	controller.Halt(errors.New("inconsistent checkpoint"))
+	controller.Halt(errors.New("another error"))
+	assert.True(t, controller.Halted())
+	assert.EqualError(t, controller.FatalError(), "node 2 halted: inconsistent checkpoint")
+
+	fatalErrorHandler.AssertNumberOfCalls(t, "OnFatalError", 1)
+	fatalErr := fatalErrorHandler.Calls[0].Arguments.Get(0).(*types.FatalError)
+	assert.Equal(t, uint64(2), fatalErr.Node)
+	assert.EqualError(t, fatalErr.Err, "inconsistent checkpoint")
+
+	// A halted controller neither accepts requests nor complains about the leader
+	assert.EqualError(t, controller.SubmitRequest([]byte{1}), "node 2 halted: inconsistent checkpoint")
+	controller.OnLeaderFwdRequestTimeout([]byte{1}, types.RequestInfo{ClientID: "alice", ID: "1"})
+	controller.OnHeartbeatTimeout(1, 1)
+	failureDetector.AssertNotCalled(t, "Complain", mock.Anything)
+
+	controller.Stop()
+}
+
+func TestControllerHaltsOnConflictingSync(t *testing.T) {
+	basicLog, err := zap.NewDevelopment()
+	assert.NoError(t, err)
+	log := basicLog.Sugar()
+
+	delivered := types.DecisionCertificate{
+		Proposal:   types.Proposal{Payload: []byte{1}},
+		Signatures: []types.Signature{{Id: 1}, {Id: 2}, {Id: 3}},
+	}
+	conflicting := types.DecisionCertificate{
+		Proposal:   types.Proposal{Payload: []byte{2}},
+		Signatures: []types.Signature{{Id: 0}, {Id: 2}, {Id: 3}},
+	}
+
+	for _, testCase := range []struct {
+		description string
+		signatures  []types.Signature
+		invalid     map[uint64]bool
+		halts       bool
+	}{
+		{
+			description: "signed by a quorum",
+			signatures:  conflicting.Signatures,
+			halts:       true,
+		},
+		{
+			description: "signed by too few nodes",
+			signatures:  []types.Signature{{Id: 0}, {Id: 2}, {Id: 2}},
+		},
+		{
+			description: "invalid signature",
+			signatures:  conflicting.Signatures,
+			invalid:     map[uint64]bool{3: true},
+		},
+	} {
+		t.Run(testCase.description, func(t *testing.T) {
+			batcher := &mocks.Batcher{}
+			batcher.On("Close")
+			batcher.On("Reset")
+			pool := &mocks.RequestPool{}
+			pool.On("Close")
+			roles := make(chan uint64, 2)
+			leaderMon := &mocks.LeaderMonitor{}
+			leaderMon.On("ChangeRole", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				roles <- args.Get(1).(uint64)
+			})
+			leaderMon.On("Close")
+			comm := &mocks.CommMock{}
+			comm.On("Nodes").Return([]uint64{0, 1, 2, 3})
+			verifier := &mocks.VerifierMock{}
+			verifier.On("VerifyConsenterSig", mock.Anything, mock.Anything).Return(func(signature types.Signature, _ types.Proposal) error {
+				if testCase.invalid[signature.Id] {
+					return errors.New("bad signature")
+				}
+				return nil
+			})
+
+			decisions := bft.NewDecisionHistory(0)
+			decisions.Record(5, delivered)
+
+			synchronizer := &mocks.DecisionSynchronizerMock{}
+			synchronizer.On("Sync").Return(protos.ViewMetadata{ViewId: 1, LatestSequence: 5}, uint64(0))
+			synchronizer.On("LastSyncedDecision").Return(conflicting.Proposal, testCase.signatures)
+
+			fatalErrors := make(chan *types.FatalError, 1)
+			fatalErrorHandler := &mocks.FatalErrorHandlerMock{}
+			fatalErrorHandler.On("OnFatalError", mock.Anything).Run(func(args mock.Arguments) {
+				fatalErrors <- args.Get(0).(*types.FatalError)
+			})
+
+			reqTimer := &mocks.RequestsTimer{}
+			reqTimer.On("StopTimers")
+			vc := &bft.ViewChanger{
+				SelfID:        2,
+				N:             4,
+				Logger:        log,
+				Comm:          comm,
+				RequestsTimer: reqTimer,
+				Ticker:        make(chan time.Time),
+				Controller:    &mocks.ViewController{},
+			}
+
+			controller := &bft.Controller{
+				Batcher:           batcher,
+				RequestPool:       pool,
+				LeaderMonitor:     leaderMon,
+				ID:                2, // not the leader
+				N:                 4,
+				Logger:            log,
+				Comm:              comm,
+				Verifier:          verifier,
+				Synchronizer:      synchronizer,
+				ViewChanger:       vc,
+				Decisions:         decisions,
+				FatalErrorHandler: fatalErrorHandler,
+			}
+			configureProposerBuilder(controller)
+
+			vc.Start(1)
+			controller.Start(1, 6)
+			<-roles
+			controller.Sync()
+
+			if !testCase.halts {
+				// The controller starts the view it synchronized to, and isn't halted
+				<-roles
+				controller.Stop()
+				vc.Stop()
+				assert.False(t, controller.Halted())
+				assert.Empty(t, fatalErrors)
+				return
+			}
+
+			fatalErr := <-fatalErrors
+			controller.Stop()
+			vc.Stop()
+
+			assert.Equal(t, &types.ForkEvidence{
+				Sequence:    5,
+				Delivered:   delivered,
+				Conflicting: conflicting,
+				Signers:     []uint64{2, 3},
+			}, fatalErr.Err)
+			assert.True(t, controller.Halted())
+		})
+	}
+}
+
+func TestControllerChecksStaleDecision(t *testing.T) {
+	basicLog, err := zap.NewDevelopment()
+	assert.NoError(t, err)
+	log := basicLog.Sugar()
+	batcher := &mocks.Batcher{}
+	batcher.On("Close")
+	pool := &mocks.RequestPool{}
+	pool.On("Close")
+	leaderMon := &mocks.LeaderMonitor{}
+	leaderMon.On("ChangeRole", mock.Anything, mock.Anything, mock.Anything)
+	leaderMon.On("Close")
+	comm := &mocks.CommMock{}
+	comm.On("Nodes").Return([]uint64{0, 1, 2, 3})
+	app := &mocks.ApplicationMock{}
+
+	metadata := bft.MarshalOrPanic(&protos.ViewMetadata{ViewId: 1, LatestSequence: 5})
+	delivered := types.DecisionCertificate{
+		Proposal:   types.Proposal{Payload: []byte{1}, Metadata: metadata},
+		Signatures: []types.Signature{{Id: 1}, {Id: 2}, {Id: 3}},
+	}
+	conflicting := types.DecisionCertificate{
+		Proposal:   types.Proposal{Payload: []byte{2}, Metadata: metadata},
+		Signatures: []types.Signature{{Id: 0}, {Id: 2}, {Id: 3}},
+	}
+	decisions := bft.NewDecisionHistory(0)
+	decisions.Record(5, delivered)
+
+	fatalErrorHandler := &mocks.FatalErrorHandlerMock{}
+	fatalErrorHandler.On("OnFatalError", mock.Anything)
+
+	controller := &bft.Controller{
+		Batcher:           batcher,
+		RequestPool:       pool,
+		LeaderMonitor:     leaderMon,
+		ID:                2, // not the leader
+		N:                 4,
+		Logger:            log,
+		Application:       app,
+		Comm:              comm,
+		Decisions:         decisions,
+		FatalErrorHandler: fatalErrorHandler,
+	}
+	configureProposerBuilder(controller)
+
+	// The controller synchronized past sequence 5 while its view decided it
+	controller.Start(1, 6)
+
+	// The same decision is not delivered again
+	controller.Decide(delivered.Proposal, delivered.Signatures, nil)
+	assert.False(t, controller.Halted())
+
+	// A conflicting decision is checked against the decision delivered at its own sequence
+	controller.Decide(conflicting.Proposal, conflicting.Signatures, nil)
+	controller.Stop()
+
+	app.AssertNotCalled(t, "Deliver", mock.Anything, mock.Anything)
+	assert.True(t, controller.Halted())
+	fatalErr := fatalErrorHandler.Calls[0].Arguments.Get(0).(*types.FatalError)
+	assert.Equal(t, &types.ForkEvidence{
+		Sequence:    5,
+		Delivered:   delivered,
+		Conflicting: conflicting,
+		Signers:     []uint64{2, 3},
+	}, fatalErr.Err)
+}
+
+type fixedLeader uint64
+
+func (l fixedLeader) Leader(_ uint64, _ []uint64) uint64 {
+	return uint64(l)
+}
+
+func TestControllerLeaderRotation(t *testing.T) {
+	basicLog, err := zap.NewDevelopment()
+	assert.NoError(t, err)
+	log := basicLog.Sugar()
+	batcher := &mocks.Batcher{}
+	batcher.On("Close")
+	batcherChan := make(chan struct{})
+	var once sync.Once
+	batcher.On("NextBatch").Run(func(args mock.Arguments) {
+		once.Do(func() {
+			batcherChan <- struct{}{}
+		})
+	}).Return([][]byte{})
+	pool := &mocks.RequestPool{}
+	pool.On("Close")
+	leaderMon := &mocks.LeaderMonitor{}
+	leaderMon.On("ChangeRole", bft.Leader, uint64(1), uint64(3))
+	leaderMon.On("Close")
+	nodes := []uint64{3, 1, 0, 2}
+	commMock := &mocks.CommMock{}
+	commMock.On("Nodes").Return(nodes)
+
+	controller := &bft.Controller{
+		RequestPool:    pool,
+		LeaderMonitor:  leaderMon,
+		ID:             3, // the leader according to the rotation, not round robin
+		N:              4,
+		Logger:         log,
+		Batcher:        batcher,
+		Comm:           commMock,
+		LeaderRotation: fixedLeader(3),
+	}
+	configureProposerBuilder(controller)
+
+	controller.Start(1, 0)
+	<-batcherChan
+	controller.Stop()
+	leaderMon.AssertCalled(t, "ChangeRole", bft.Leader, uint64(1), uint64(3))
+	// The nodes of the Comm are not sorted in place
+	assert.Equal(t, []uint64{3, 1, 0, 2}, nodes)
+}
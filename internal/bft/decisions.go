@@ -0,0 +1,141 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package bft
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/SmartBFT-Go/consensus/pkg/api"
+	"github.com/SmartBFT-Go/consensus/pkg/types"
+	protos "github.com/SmartBFT-Go/consensus/smartbftprotos"
+	"github.com/pkg/errors"
+)
+
+// DefaultDecisionHistorySize is the number of recent decisions checked for conflicts.
+const DefaultDecisionHistorySize = 100
+
+// DecisionHistory remembers the certificates of the most recent decisions the node delivered,
+// and detects quorum-signed certificates which conflict with them.
+// A nil DecisionHistory remembers nothing, and detects nothing.
+type DecisionHistory struct {
+	lock      sync.Mutex
+	size      int
+	decisions map[uint64]types.DecisionCertificate
+	sequences []uint64
+}
+
+// NewDecisionHistory returns a DecisionHistory which remembers the given number of decisions.
+func NewDecisionHistory(size int) *DecisionHistory {
+	if size <= 0 {
+		size = DefaultDecisionHistorySize
+	}
+	return &DecisionHistory{
+		size:      size,
+		decisions: make(map[uint64]types.DecisionCertificate, size),
+	}
+}
+
+// Record remembers the certificate of the decision delivered at the given sequence,
+// and forgets the oldest decision if the history is full.
+func (dh *DecisionHistory) Record(seq uint64, cert types.DecisionCertificate) {
+	if dh == nil {
+		return
+	}
+	dh.lock.Lock()
+	defer dh.lock.Unlock()
+
+	if _, exists := dh.decisions[seq]; !exists {
+		dh.sequences = append(dh.sequences, seq)
+	}
+	dh.decisions[seq] = cert
+	if len(dh.sequences) > dh.size {
+		delete(dh.decisions, dh.sequences[0])
+		dh.sequences = dh.sequences[1:]
+	}
+}
+
+// Check returns evidence of a fork if the certificate is for a different proposal
+// than the one delivered at the given sequence, or nil otherwise.
+func (dh *DecisionHistory) Check(seq uint64, cert types.DecisionCertificate) *types.ForkEvidence {
+	if dh == nil {
+		return nil
+	}
+	dh.lock.Lock()
+	delivered, exists := dh.decisions[seq]
+	dh.lock.Unlock()
+
+	if !exists || delivered.Proposal.Digest() == cert.Proposal.Digest() {
+		return nil
+	}
+
+	signedDelivered := make(map[uint64]struct{}, len(delivered.Signatures))
+	for _, sig := range delivered.Signatures {
+		signedDelivered[sig.Id] = struct{}{}
+	}
+	var signers []uint64
+	for _, sig := range cert.Signatures {
+		if _, signedBoth := signedDelivered[sig.Id]; signedBoth {
+			signers = append(signers, sig.Id)
+			delete(signedDelivered, sig.Id)
+		}
+	}
+	sort.Slice(signers, func(i, j int) bool {
+		return signers[i] < signers[j]
+	})
+
+	return &types.ForkEvidence{
+		Sequence:    seq,
+		Delivered:   delivered,
+		Conflicting: cert,
+		Signers:     signers,
+	}
+}
+
+// verifyCertificate checks that the certificate is signed by a quorum of distinct nodes by voting weight,
+// and that all of their signatures are valid.
+func verifyCertificate(cert types.DecisionCertificate, quorum int, verifier api.Verifier, weights map[uint64]uint64) error {
+	var signers []uint64
+	var signatures []types.Signature
+	seen := make(map[uint64]struct{}, len(cert.Signatures))
+	for _, sig := range cert.Signatures {
+		if _, exists := seen[sig.Id]; exists {
+			continue // seen signature from this node already
+		}
+		seen[sig.Id] = struct{}{}
+		signers = append(signers, sig.Id)
+		signatures = append(signatures, sig)
+	}
+	// The signers are counted before any signature is verified, so certificates without a quorum are rejected cheaply
+	if weight := votingWeight(weights, signers...); weight < quorum {
+		return errors.Errorf("there are only %d signatures", weight)
+	}
+	for _, sig := range signatures {
+		if err := verifier.VerifyConsenterSig(sig, cert.Proposal); err != nil {
+			return errors.Errorf("signature of %d is invalid, error: %v", sig.Id, err)
+		}
+	}
+	return nil
+}
+
+func certificateFromProtos(proposal *protos.Proposal, signatures []*protos.Signature) types.DecisionCertificate {
+	cert := types.DecisionCertificate{
+		Proposal: types.Proposal{
+			Header:               proposal.Header,
+			Payload:              proposal.Payload,
+			Metadata:             proposal.Metadata,
+			VerificationSequence: int64(proposal.VerificationSequence),
+		},
+	}
+	for _, sig := range signatures {
+		cert.Signatures = append(cert.Signatures, types.Signature{
+			Id:    sig.Signer,
+			Value: sig.Value,
+			Msg:   sig.Msg,
+		})
+	}
+	return cert
+}
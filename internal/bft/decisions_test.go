@@ -0,0 +1,54 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package bft_test
+
+import (
+	"testing"
+
+	"github.com/SmartBFT-Go/consensus/internal/bft"
+	"github.com/SmartBFT-Go/consensus/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func certificate(payload byte, signers ...uint64) types.DecisionCertificate {
+	cert := types.DecisionCertificate{
+		Proposal: types.Proposal{Payload: []byte{payload}},
+	}
+	for _, signer := range signers {
+		cert.Signatures = append(cert.Signatures, types.Signature{Id: signer, Value: []byte{payload}})
+	}
+	return cert
+}
+
+func TestDecisionHistory(t *testing.T) {
+	history := bft.NewDecisionHistory(2)
+	history.Record(1, certificate(1, 1, 2, 3))
+	history.Record(2, certificate(2, 1, 2, 3))
+
+	// The same proposal, even with other signatures, is no fork
+	assert.Nil(t, history.Check(2, certificate(2, 2, 3, 4)))
+	// Sequences which were not delivered can't be checked
+	assert.Nil(t, history.Check(3, certificate(3, 2, 3, 4)))
+
+	evidence := history.Check(2, certificate(5, 4, 3, 2))
+	assert.Equal(t, &types.ForkEvidence{
+		Sequence:    2,
+		Delivered:   certificate(2, 1, 2, 3),
+		Conflicting: certificate(5, 4, 3, 2),
+		Signers:     []uint64{2, 3},
+	}, evidence)
+	assert.Contains(t, evidence.Error(), "conflicting decisions at sequence 2")
+	assert.Contains(t, evidence.Error(), "nodes [2 3] signed both")
+
+	// The oldest decision is forgotten once the history is full
+	history.Record(3, certificate(3, 1, 2, 3))
+	assert.Nil(t, history.Check(1, certificate(5, 1, 2, 3)))
+	assert.NotNil(t, history.Check(3, certificate(5, 1, 2, 3)))
+
+	var noHistory *bft.DecisionHistory
+	noHistory.Record(1, certificate(1, 1, 2, 3))
+	assert.Nil(t, noHistory.Check(1, certificate(5, 1, 2, 3)))
+}
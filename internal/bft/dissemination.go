@@ -0,0 +1,256 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package bft
+
+import (
+	"sync"
+	"time"
+
+	"github.com/SmartBFT-Go/consensus/pkg/api"
+	"github.com/SmartBFT-Go/consensus/pkg/types"
+	protos "github.com/SmartBFT-Go/consensus/smartbftprotos"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultRequestFetchTimeout = time.Second
+	// DefaultMaxFetchedRequests is the default maximal number of requests asked for or sent back in a single message
+	DefaultMaxFetchedRequests = 1000
+	// DefaultMaxFetchedBytes is the default maximal size of the requests sent back in a single message
+	DefaultMaxFetchedBytes = 10 * 1024 * 1024
+)
+
+// RequestLookup returns the requests the node holds.
+type RequestLookup interface {
+	Lookup(requestInfo types.RequestInfo) ([]byte, bool)
+}
+
+// Dissemination omits the payloads of the proposals the leader sends, and assembles them at the followers
+// from the requests they hold, as requests are disseminated in advance by forwarding them.
+// Requests a follower doesn't hold are fetched from the leader, and from all nodes if the leader
+// doesn't send them within the fetch timeout.
+// The Assembler must assemble the same proposal from the same metadata and requests at all nodes,
+// which are ordered as the Verifier returns them from VerifyProposal at the leader.
+type Dissemination struct {
+	Pool      RequestLookup
+	Assembler api.Assembler
+	Inspector api.RequestInspector
+	Comm      Comm
+	Logger    api.Logger
+	// DefaultRequestFetchTimeout if zero
+	FetchTimeout time.Duration
+	// DefaultMaxFetchedRequests if zero
+	MaxFetchedRequests int
+	// DefaultMaxFetchedBytes if zero
+	MaxFetchedBytes int
+
+	lock    sync.Mutex
+	missing map[types.RequestInfo]struct{}
+	fetched map[types.RequestInfo][]byte
+	arrived chan struct{}
+}
+
+// Compact returns a copy of the pre-prepare, which carries the requests of the proposal instead of its payload.
+func (d *Dissemination) Compact(pp *protos.PrePrepare, requests []types.RequestInfo) *protos.PrePrepare {
+	compact := &protos.PrePrepare{
+		View: pp.View,
+		Seq:  pp.Seq,
+		Proposal: &protos.Proposal{
+			Header:               pp.Proposal.Header,
+			Metadata:             pp.Proposal.Metadata,
+			VerificationSequence: pp.Proposal.VerificationSequence,
+		},
+		PrevCommitSignatures: pp.PrevCommitSignatures,
+		Digest:               proposalDigest(pp.Proposal),
+	}
+	for _, request := range requests {
+		compact.RequestIds = append(compact.RequestIds, &protos.RequestID{Id: request.ID, ClientId: request.ClientID})
+	}
+	return compact
+}
+
+// Assemble returns the proposal of a pre-prepare which carries the requests of the proposal instead of its payload,
+// or the requests which are missing to assemble it.
+func (d *Dissemination) Assemble(pp *protos.PrePrepare) (*types.Proposal, []types.RequestInfo, error) {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+
+	var requests [][]byte
+	var missing []types.RequestInfo
+	for _, id := range pp.RequestIds {
+		info := types.RequestInfo{ID: id.Id, ClientID: id.ClientId}
+		if request, exists := d.Pool.Lookup(info); exists {
+			requests = append(requests, request)
+		} else if request, exists := d.fetched[info]; exists {
+			requests = append(requests, request)
+		} else {
+			missing = append(missing, info)
+		}
+	}
+
+	d.missing = make(map[types.RequestInfo]struct{}, len(missing))
+	for _, info := range missing {
+		d.missing[info] = struct{}{}
+	}
+	if len(missing) > 0 {
+		return nil, missing, nil
+	}
+	d.fetched = nil
+
+	proposal, remainder := d.Assembler.AssembleProposal(pp.Proposal.Metadata, requests)
+	if len(remainder) > 0 {
+		return nil, nil, errors.Errorf("%d requests were left out of the assembled proposal", len(remainder))
+	}
+	if digest := proposal.Digest(); digest != pp.Digest {
+		return nil, nil, errors.Errorf("assembled proposal with digest %s but the pre-prepare has digest %s", digest, pp.Digest)
+	}
+	return &proposal, nil, nil
+}
+
+// Fetch asks the target for the missing requests.
+func (d *Dissemination) Fetch(target uint64, missing []types.RequestInfo) {
+	for _, m := range fetchRequestsMsgs(missing, d.maxFetchedRequests()) {
+		d.Comm.SendConsensus(target, m)
+	}
+}
+
+// FetchFromAll asks all nodes for the missing requests.
+func (d *Dissemination) FetchFromAll(missing []types.RequestInfo) {
+	for _, m := range fetchRequestsMsgs(missing, d.maxFetchedRequests()) {
+		d.Comm.BroadcastConsensus(m)
+	}
+}
+
+// Arrived returns a channel which is signaled when missing requests arrive.
+func (d *Dissemination) Arrived() <-chan struct{} {
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	return d.arrivedChan()
+}
+
+func (d *Dissemination) arrivedChan() chan struct{} {
+	if d.arrived == nil {
+		d.arrived = make(chan struct{}, 1)
+	}
+	return d.arrived
+}
+
+func (d *Dissemination) fetchTimeout() time.Duration {
+	if d.FetchTimeout == 0 {
+		return DefaultRequestFetchTimeout
+	}
+	return d.FetchTimeout
+}
+
+func (d *Dissemination) maxFetchedRequests() int {
+	if d.MaxFetchedRequests == 0 {
+		return DefaultMaxFetchedRequests
+	}
+	return d.MaxFetchedRequests
+}
+
+func (d *Dissemination) maxFetchedBytes() int {
+	if d.MaxFetchedBytes == 0 {
+		return DefaultMaxFetchedBytes
+	}
+	return d.MaxFetchedBytes
+}
+
+// HandleMessage sends the sender of FetchRequests the requests we hold,
+// and keeps the missing requests among FetchedRequests.
+// Only a limited number of requests is looked up and sent back, up to a limited size,
+// and the rest are fetched again once the fetch times out.
+func (d *Dissemination) HandleMessage(sender uint64, m *protos.Message) {
+	if fetch := m.GetFetchRequests(); fetch != nil {
+		ids := fetch.RequestIds
+		if len(ids) > d.maxFetchedRequests() {
+			d.Logger.Warnf("%d asked for %d requests, looking up only %d of them", sender, len(ids), d.maxFetchedRequests())
+			ids = ids[:d.maxFetchedRequests()]
+		}
+		var requests [][]byte
+		var size int
+		for _, id := range ids {
+			request, exists := d.Pool.Lookup(types.RequestInfo{ID: id.Id, ClientID: id.ClientId})
+			if !exists {
+				continue
+			}
+			if len(requests) > 0 && size+len(request) > d.maxFetchedBytes() {
+				break
+			}
+			requests = append(requests, request)
+			size += len(request)
+		}
+		d.Logger.Debugf("%d asked for %d requests, sending back %d", sender, len(fetch.RequestIds), len(requests))
+		if len(requests) > 0 {
+			d.Comm.SendConsensus(sender, &protos.Message{
+				Content: &protos.Message_FetchedRequests{
+					FetchedRequests: &protos.FetchedRequests{Requests: requests},
+				},
+			})
+		}
+		return
+	}
+
+	fetched := m.GetFetchedRequests()
+	if fetched == nil {
+		return
+	}
+
+	d.lock.Lock()
+	defer d.lock.Unlock()
+	var arrived int
+	for _, request := range fetched.Requests {
+		info := d.Inspector.RequestID(request)
+		if _, isMissing := d.missing[info]; !isMissing {
+			continue
+		}
+		if d.fetched == nil {
+			d.fetched = make(map[types.RequestInfo][]byte)
+		}
+		d.fetched[info] = request
+		delete(d.missing, info)
+		arrived++
+	}
+	d.Logger.Debugf("Got %d requests from %d, %d of them were missing", len(fetched.Requests), sender, arrived)
+	if arrived == 0 {
+		return
+	}
+	select {
+	case d.arrivedChan() <- struct{}{}:
+	default:
+	}
+}
+
+// fetchRequestsMsgs returns the messages which ask for the missing requests, each for at most the given number of them.
+func fetchRequestsMsgs(missing []types.RequestInfo, maxRequests int) []*protos.Message {
+	var msgs []*protos.Message
+	for len(missing) > 0 {
+		n := len(missing)
+		if n > maxRequests {
+			n = maxRequests
+		}
+		fetch := &protos.FetchRequests{}
+		for _, info := range missing[:n] {
+			fetch.RequestIds = append(fetch.RequestIds, &protos.RequestID{Id: info.ID, ClientId: info.ClientID})
+		}
+		msgs = append(msgs, &protos.Message{
+			Content: &protos.Message_FetchRequests{
+				FetchRequests: fetch,
+			},
+		})
+		missing = missing[n:]
+	}
+	return msgs
+}
+
+func proposalDigest(p *protos.Proposal) string {
+	return types.Proposal{
+		Header:               p.Header,
+		Payload:              p.Payload,
+		Metadata:             p.Metadata,
+		VerificationSequence: int64(p.VerificationSequence),
+	}.Digest()
+}
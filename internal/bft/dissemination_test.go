@@ -0,0 +1,150 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package bft_test
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/SmartBFT-Go/consensus/internal/bft"
+	"github.com/SmartBFT-Go/consensus/internal/bft/mocks"
+	"github.com/SmartBFT-Go/consensus/pkg/types"
+	protos "github.com/SmartBFT-Go/consensus/smartbftprotos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+type concatAssembler struct{}
+
+func (concatAssembler) AssembleProposal(metadata []byte, requests [][]byte) (types.Proposal, [][]byte) {
+	return types.Proposal{
+		Metadata: metadata,
+		Payload:  bytes.Join(requests, []byte{0}),
+	}, nil
+}
+
+func TestDissemination(t *testing.T) {
+	basicLog, err := zap.NewDevelopment()
+	assert.NoError(t, err)
+	log := basicLog.Sugar()
+	insp := &testRequestInspector{}
+
+	req1 := makeTestRequest("1", "1", "foo")
+	req2 := makeTestRequest("2", "2", "bar")
+
+	newDissemination := func(requests ...[]byte) (*bft.Dissemination, chan *protos.Message) {
+		pool := bft.NewPool(log, insp, noopTimeoutHandler, bft.PoolOptions{QueueSize: 10})
+		for _, request := range requests {
+			assert.NoError(t, pool.Submit(request))
+		}
+		sent := make(chan *protos.Message, 10)
+		comm := &mocks.CommMock{}
+		comm.On("SendConsensus", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			sent <- args.Get(1).(*protos.Message)
+		})
+		return &bft.Dissemination{
+			Pool:      pool,
+			Assembler: concatAssembler{},
+			Inspector: insp,
+			Comm:      comm,
+			Logger:    log,
+		}, sent
+	}
+
+	leader, leaderSent := newDissemination(req1, req2)
+	follower, followerSent := newDissemination(req1)
+
+	proposal, _ := concatAssembler{}.AssembleProposal([]byte{1}, [][]byte{req1, req2})
+	pp := &protos.PrePrepare{
+		View: 1,
+		Seq:  2,
+		Proposal: &protos.Proposal{
+			Payload:  proposal.Payload,
+			Metadata: proposal.Metadata,
+		},
+	}
+	compact := leader.Compact(pp, []types.RequestInfo{insp.RequestID(req1), insp.RequestID(req2)})
+	assert.Nil(t, compact.Proposal.Payload)
+	assert.Equal(t, proposal.Digest(), compact.Digest)
+	assert.Len(t, compact.RequestIds, 2)
+
+	// The follower is missing the second request
+	assembled, missing, err := follower.Assemble(compact)
+	assert.NoError(t, err)
+	assert.Nil(t, assembled)
+	assert.Equal(t, []types.RequestInfo{insp.RequestID(req2)}, missing)
+
+	follower.Fetch(1, missing)
+	leader.HandleMessage(2, <-followerSent)
+	follower.HandleMessage(1, <-leaderSent)
+	<-follower.Arrived()
+
+	assembled, missing, err = follower.Assemble(compact)
+	assert.NoError(t, err)
+	assert.Empty(t, missing)
+	assert.Equal(t, proposal, *assembled)
+
+	// A proposal assembled with a different digest is rejected
+	compact.Digest = "foo"
+	_, _, err = leader.Assemble(compact)
+	assert.EqualError(t, err, "assembled proposal with digest "+proposal.Digest()+" but the pre-prepare has digest foo")
+}
+
+func TestDisseminationLimitsFetches(t *testing.T) {
+	basicLog, err := zap.NewDevelopment()
+	assert.NoError(t, err)
+	log := basicLog.Sugar()
+	insp := &testRequestInspector{}
+
+	requests := [][]byte{
+		makeTestRequest("1", "1", "foo"),
+		makeTestRequest("2", "2", "bar"),
+		makeTestRequest("3", "3", "baz"),
+	}
+	var infos []types.RequestInfo
+	for _, request := range requests {
+		infos = append(infos, insp.RequestID(request))
+	}
+
+	pool := bft.NewPool(log, insp, noopTimeoutHandler, bft.PoolOptions{QueueSize: 10})
+	for _, request := range requests {
+		assert.NoError(t, pool.Submit(request))
+	}
+	sent := make(chan *protos.Message, 10)
+	comm := &mocks.CommMock{}
+	comm.On("SendConsensus", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		sent <- args.Get(1).(*protos.Message)
+	})
+	d := &bft.Dissemination{
+		Pool:               pool,
+		Assembler:          concatAssembler{},
+		Inspector:          insp,
+		Comm:               comm,
+		Logger:             log,
+		MaxFetchedRequests: 2,
+	}
+
+	// Missing requests are asked for in several messages
+	d.Fetch(1, infos)
+	first, second := <-sent, <-sent
+	assert.Len(t, first.GetFetchRequests().RequestIds, 2)
+	assert.Len(t, second.GetFetchRequests().RequestIds, 1)
+
+	// Only the first requests asked for in a single message are sent back
+	fetchAll := &protos.FetchRequests{}
+	for _, info := range infos {
+		fetchAll.RequestIds = append(fetchAll.RequestIds, &protos.RequestID{Id: info.ID, ClientId: info.ClientID})
+	}
+	fetchMsg := &protos.Message{Content: &protos.Message_FetchRequests{FetchRequests: fetchAll}}
+	d.HandleMessage(2, fetchMsg)
+	assert.Equal(t, requests[:2], (<-sent).GetFetchedRequests().Requests)
+
+	// The requests sent back are limited in size
+	d.MaxFetchedBytes = len(requests[0]) + 1
+	d.HandleMessage(2, fetchMsg)
+	assert.Equal(t, requests[:1], (<-sent).GetFetchedRequests().Requests)
+}
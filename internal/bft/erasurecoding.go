@@ -0,0 +1,319 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package bft
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"sync"
+
+	"github.com/SmartBFT-Go/consensus/pkg/api"
+	"github.com/SmartBFT-Go/consensus/pkg/erasure"
+	"github.com/SmartBFT-Go/consensus/pkg/types"
+	protos "github.com/SmartBFT-Go/consensus/smartbftprotos"
+	"github.com/golang/protobuf/proto"
+)
+
+// ErasureCodedComm broadcasts the pre-prepares of large proposals in erasure coded chunks, one to each node,
+// and each node relays its chunk to the others, so the leader sends the pre-prepare about once instead of N-1 times.
+// Any f+1 chunks suffice to reconstruct a pre-prepare, which is verified against the digest the leader sent.
+// When nodes have weights, fewer chunks suffice, so that the chunks of the correct nodes are always enough.
+// The leader sends every node the hashes of the shards of all chunks, so shards relayed by faulty nodes are discarded.
+// Other messages are sent through the wrapped Comm.
+type ErasureCodedComm struct {
+	Comm
+	SelfID     uint64
+	FaultModel types.FaultModel
+	// Weights are the voting weights of the nodes, all nodes weigh the same if empty
+	Weights map[uint64]uint64
+	// Pre-prepares of proposals of at least this size are erasure coded
+	MinProposalSize int
+	// Pre-prepares are erasure coded only once the version in effect supports it, if set
+	ProtocolVersions *ProtocolVersions
+	Logger           api.Logger
+
+	lock    sync.Mutex
+	pending map[chunkKey]*pendingChunks
+	done    map[chunkKey]struct{}
+}
+
+const (
+	// chunkSequenceWindow is how many sequences above the current sequence chunks are accepted for
+	chunkSequenceWindow = 2
+	// maxPendingPrePrepares is the maximal number of pre-prepares whose chunks are collected at once
+	maxPendingPrePrepares = 2 * (chunkSequenceWindow + 1)
+)
+
+type chunkKey struct {
+	view   uint64
+	seq    uint64
+	leader uint64
+}
+
+type pendingChunks struct {
+	// The chunk the leader sent us
+	header *protos.PrePrepareChunk
+	// Shards by their indices, which are verified once the leader's chunk arrives
+	shards     map[uint32][]byte
+	unverified map[uint32][]byte
+}
+
+// BroadcastConsensus sends the pre-prepares of large proposals in chunks, and other messages through the wrapped Comm.
+func (ecc *ErasureCodedComm) BroadcastConsensus(m *protos.Message) {
+	pp := m.GetPrePrepare()
+	if pp == nil || pp.Proposal == nil || proposalSize(pp.Proposal) < ecc.MinProposalSize ||
+		!ecc.ProtocolVersions.Supports(PrePrepareChunksVersion) {
+		ecc.Comm.BroadcastConsensus(m)
+		return
+	}
+
+	nodes := sortedNodes(ecc.Comm.Nodes())
+	code, err := ecc.code(nodes)
+	if err != nil {
+		ecc.Logger.Warnf("Failed erasure coding the pre-prepare of seq %d, broadcasting it whole: %v", pp.Seq, err)
+		ecc.Comm.BroadcastConsensus(m)
+		return
+	}
+
+	encoded := MarshalOrPanic(pp)
+	shards := code.Encode(encoded)
+	hashes := make([][]byte, len(shards))
+	for i, shard := range shards {
+		hashes[i] = shardHash(shard)
+	}
+
+	ecc.Logger.Debugf("Broadcasting the pre-prepare of seq %d of %d bytes in %d chunks", pp.Seq, len(encoded), len(shards))
+	for i, node := range nodes {
+		if node == ecc.SelfID {
+			continue
+		}
+		ecc.Comm.SendConsensus(node, &protos.Message{
+			Content: &protos.Message_PrePrepareChunk{
+				PrePrepareChunk: &protos.PrePrepareChunk{
+					View:        pp.View,
+					Seq:         pp.Seq,
+					Leader:      ecc.SelfID,
+					Index:       uint32(i),
+					Shard:       shards[i],
+					Digest:      encodedDigest(encoded),
+					Size:        uint64(len(encoded)),
+					DataShards:  uint32(code.DataShards()),
+					ShardHashes: hashes,
+				},
+			},
+		})
+	}
+}
+
+// HandleChunk relays the chunk the leader sent us, and returns the pre-prepare once enough chunks arrived.
+// Only chunks of pre-prepares of the given leader in the given view, with sequences
+// in a small window above the given sequence, are collected.
+func (ecc *ErasureCodedComm) HandleChunk(sender uint64, chunk *protos.PrePrepareChunk, view, seq, leader uint64) *protos.Message {
+	if chunk.View != view || chunk.Leader != leader {
+		ecc.Logger.Debugf("Got chunk from %d of view %d and leader %d but the view is %d and its leader is %d",
+			sender, chunk.View, chunk.Leader, view, leader)
+		return nil
+	}
+	if chunk.Seq < seq || chunk.Seq > seq+chunkSequenceWindow {
+		ecc.Logger.Debugf("Got chunk from %d of seq %d but the seq is %d", sender, chunk.Seq, seq)
+		return nil
+	}
+	nodes := sortedNodes(ecc.Comm.Nodes())
+	if int(chunk.Index) >= len(nodes) {
+		ecc.Logger.Warnf("Got chunk %d from %d but there are only %d nodes", chunk.Index, sender, len(nodes))
+		return nil
+	}
+	fromLeader := sender == chunk.Leader
+	if fromLeader && nodes[chunk.Index] != ecc.SelfID {
+		ecc.Logger.Warnf("Leader %d sent us chunk %d, which is of node %d", sender, chunk.Index, nodes[chunk.Index])
+		return nil
+	}
+	if !fromLeader && nodes[chunk.Index] != sender {
+		ecc.Logger.Warnf("Node %d relayed chunk %d, which is of node %d", sender, chunk.Index, nodes[chunk.Index])
+		return nil
+	}
+
+	ecc.lock.Lock()
+	defer ecc.lock.Unlock()
+
+	key := chunkKey{view: chunk.View, seq: chunk.Seq, leader: chunk.Leader}
+	if _, done := ecc.done[key]; done {
+		return nil
+	}
+	if ecc.pending == nil {
+		ecc.pending = make(map[chunkKey]*pendingChunks)
+		ecc.done = make(map[chunkKey]struct{})
+	}
+	pending, exists := ecc.pending[key]
+	if !exists {
+		if len(ecc.pending) >= maxPendingPrePrepares {
+			ecc.Logger.Warnf("Already collecting the chunks of %d pre-prepares, dropping chunk of seq %d from %d", len(ecc.pending), chunk.Seq, sender)
+			return nil
+		}
+		pending = &pendingChunks{
+			shards:     make(map[uint32][]byte),
+			unverified: make(map[uint32][]byte),
+		}
+		ecc.pending[key] = pending
+	}
+
+	if fromLeader {
+		if pending.header != nil {
+			return nil
+		}
+		code, err := ecc.code(nodes)
+		if err != nil {
+			ecc.Logger.Warnf("Failed erasure decoding the chunks of %d nodes: %v", len(nodes), err)
+			return nil
+		}
+		if int(chunk.DataShards) != code.DataShards() || len(chunk.ShardHashes) != len(nodes) {
+			ecc.Logger.Warnf("Leader %d sent us a chunk of seq %d which needs %d of %d chunks, but %d of %d chunks are needed",
+				sender, chunk.Seq, chunk.DataShards, len(chunk.ShardHashes), code.DataShards(), len(nodes))
+			return nil
+		}
+		pending.header = chunk
+		ecc.relay(nodes, chunk)
+	}
+
+	if _, exists := pending.unverified[chunk.Index]; !exists {
+		pending.unverified[chunk.Index] = chunk.Shard
+	}
+	if pending.header == nil {
+		return nil
+	}
+
+	for index, shard := range pending.unverified {
+		if bytes.Equal(shardHash(shard), pending.header.ShardHashes[index]) {
+			pending.shards[index] = shard
+		} else {
+			ecc.Logger.Warnf("Discarding chunk %d of seq %d, which doesn't match the hash the leader sent", index, chunk.Seq)
+		}
+		delete(pending.unverified, index)
+	}
+
+	return ecc.reconstruct(key, pending, nodes)
+}
+
+// relay sends the chunk the leader sent us to the nodes other than the leader.
+func (ecc *ErasureCodedComm) relay(nodes []uint64, chunk *protos.PrePrepareChunk) {
+	relayed := &protos.Message{
+		Content: &protos.Message_PrePrepareChunk{
+			PrePrepareChunk: &protos.PrePrepareChunk{
+				View:   chunk.View,
+				Seq:    chunk.Seq,
+				Leader: chunk.Leader,
+				Index:  chunk.Index,
+				Shard:  chunk.Shard,
+			},
+		},
+	}
+	for _, node := range nodes {
+		if node != ecc.SelfID && node != chunk.Leader {
+			ecc.Comm.SendConsensus(node, relayed)
+		}
+	}
+}
+
+// reconstruct returns the pre-prepare once enough chunks arrived, and discards its chunks only if it is valid,
+// as otherwise the chunks of correct nodes may still arrive.
+func (ecc *ErasureCodedComm) reconstruct(key chunkKey, pending *pendingChunks, nodes []uint64) *protos.Message {
+	code, err := ecc.code(nodes)
+	if err != nil || len(pending.shards) < code.DataShards() {
+		return nil
+	}
+
+	shards := make([][]byte, len(nodes))
+	for index, shard := range pending.shards {
+		shards[index] = shard
+	}
+
+	encoded, err := code.Decode(shards, int(pending.header.Size))
+	if err != nil {
+		ecc.Logger.Warnf("Failed reconstructing the pre-prepare of seq %d: %v", key.seq, err)
+		return nil
+	}
+	if digest := encodedDigest(encoded); digest != pending.header.Digest {
+		ecc.Logger.Warnf("Reconstructed the pre-prepare of seq %d with digest %s but the leader sent digest %s", key.seq, digest, pending.header.Digest)
+		return nil
+	}
+	pp := &protos.PrePrepare{}
+	if err := proto.Unmarshal(encoded, pp); err != nil {
+		ecc.Logger.Warnf("Failed unmarshaling the pre-prepare of seq %d: %v", key.seq, err)
+		return nil
+	}
+	if pp.View != key.view || pp.Seq != key.seq {
+		ecc.Logger.Warnf("Reconstructed a pre-prepare of view %d and seq %d from chunks of view %d and seq %d", pp.View, pp.Seq, key.view, key.seq)
+		return nil
+	}
+
+	ecc.complete(key)
+	ecc.Logger.Debugf("Reconstructed the pre-prepare of seq %d from %d chunks", key.seq, len(pending.shards))
+	return &protos.Message{
+		Content: &protos.Message_PrePrepare{
+			PrePrepare: pp,
+		},
+	}
+}
+
+// complete discards the chunks of the given pre-prepare, and of pre-prepares of older sequences.
+func (ecc *ErasureCodedComm) complete(key chunkKey) {
+	for k := range ecc.pending {
+		if k.seq <= key.seq {
+			delete(ecc.pending, k)
+		}
+	}
+	for k := range ecc.done {
+		if k.seq < key.seq {
+			delete(ecc.done, k)
+		}
+	}
+	ecc.done[key] = struct{}{}
+}
+
+// code returns the code of the chunks of the given nodes, any f+1 of which suffice to reconstruct a pre-prepare.
+// When nodes have weights, f is the number of the lightest nodes that may be faulty together,
+// and no more chunks are needed than there are nodes besides them.
+func (ecc *ErasureCodedComm) code(nodes []uint64) (*erasure.ReedSolomon, error) {
+	n := len(nodes)
+	if len(ecc.Weights) == 0 {
+		_, f := computeQuorum(uint64(n), ecc.FaultModel)
+		return erasure.NewReedSolomon(f+1, n)
+	}
+	_, maxFaultyWeight := computeWeightedQuorum(uint64(n), ecc.Weights, ecc.FaultModel)
+	weights := make([]uint64, 0, n)
+	for _, node := range nodes {
+		weights = append(weights, ecc.Weights[node])
+	}
+	sort.Slice(weights, func(i, j int) bool {
+		return weights[i] < weights[j]
+	})
+	var f int
+	var faultyWeight uint64
+	for _, weight := range weights {
+		if faultyWeight+weight > uint64(maxFaultyWeight) {
+			break
+		}
+		faultyWeight += weight
+		f++
+	}
+	dataShards := f + 1
+	if dataShards > n-f {
+		dataShards = n - f
+	}
+	return erasure.NewReedSolomon(dataShards, n)
+}
+
+func shardHash(shard []byte) []byte {
+	hash := sha256.Sum256(shard)
+	return hash[:]
+}
+
+func encodedDigest(encoded []byte) string {
+	hash := sha256.Sum256(encoded)
+	return hex.EncodeToString(hash[:])
+}
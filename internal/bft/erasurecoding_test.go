@@ -0,0 +1,182 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package bft_test
+
+import (
+	"crypto/sha256"
+	"testing"
+
+	"github.com/SmartBFT-Go/consensus/internal/bft"
+	"github.com/SmartBFT-Go/consensus/internal/bft/mocks"
+	protos "github.com/SmartBFT-Go/consensus/smartbftprotos"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+type sentMessage struct {
+	target uint64
+	msg    *protos.Message
+}
+
+func TestErasureCodedComm(t *testing.T) {
+	basicLog, err := zap.NewDevelopment()
+	assert.NoError(t, err)
+	log := basicLog.Sugar()
+
+	newComm := func(id uint64) (*bft.ErasureCodedComm, chan sentMessage, chan *protos.Message) {
+		sent := make(chan sentMessage, 10)
+		broadcast := make(chan *protos.Message, 10)
+		comm := &mocks.CommMock{}
+		comm.On("Nodes").Return([]uint64{1, 2, 3, 4})
+		comm.On("SendConsensus", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			sent <- sentMessage{target: args.Get(0).(uint64), msg: args.Get(1).(*protos.Message)}
+		})
+		comm.On("BroadcastConsensus", mock.Anything).Run(func(args mock.Arguments) {
+			broadcast <- args.Get(0).(*protos.Message)
+		})
+		return &bft.ErasureCodedComm{
+			Comm:            comm,
+			SelfID:          id,
+			MinProposalSize: 100,
+			Logger:          log,
+		}, sent, broadcast
+	}
+
+	leader, leaderSent, leaderBroadcast := newComm(1)
+
+	// Small pre-prepares and other messages are sent whole
+	leader.BroadcastConsensus(prePrepare)
+	assert.Equal(t, prePrepare, <-leaderBroadcast)
+	leader.BroadcastConsensus(prepare)
+	assert.Equal(t, prepare, <-leaderBroadcast)
+
+	pp := proto.Clone(prePrepare).(*protos.Message)
+	pp.GetPrePrepare().Proposal.Payload = make([]byte, 1000)
+	leader.BroadcastConsensus(pp)
+	assert.Empty(t, leaderBroadcast)
+
+	chunks := make(map[uint64]*protos.PrePrepareChunk)
+	for i := 0; i < 3; i++ {
+		sent := <-leaderSent
+		chunks[sent.target] = sent.msg.GetPrePrepareChunk()
+	}
+	assert.Len(t, chunks, 3)
+	assert.Empty(t, leaderSent)
+
+	follower, followerSent, _ := newComm(2)
+
+	// The relayed chunk arrives before the leader's chunk, which holds the hashes to verify it
+	assert.Nil(t, follower.HandleChunk(3, relayedChunk(chunks[3]), 1, 0, 1))
+	assert.Empty(t, followerSent)
+
+	reconstructed := follower.HandleChunk(1, chunks[2], 1, 0, 1)
+	assert.True(t, proto.Equal(pp, reconstructed))
+
+	// Our chunk is relayed to the nodes other than the leader
+	relayedTo := []uint64{(<-followerSent).target, (<-followerSent).target}
+	assert.ElementsMatch(t, []uint64{3, 4}, relayedTo)
+
+	// Chunks of a reconstructed pre-prepare are ignored
+	assert.Nil(t, follower.HandleChunk(4, relayedChunk(chunks[4]), 1, 0, 1))
+
+	// A corrupted relayed chunk is discarded
+	follower, _, _ = newComm(2)
+	corrupted := relayedChunk(chunks[3])
+	corrupted.Shard = append([]byte{}, corrupted.Shard...)
+	corrupted.Shard[0]++
+	assert.Nil(t, follower.HandleChunk(3, corrupted, 1, 0, 1))
+	assert.Nil(t, follower.HandleChunk(1, chunks[2], 1, 0, 1))
+	reconstructed = follower.HandleChunk(4, relayedChunk(chunks[4]), 1, 0, 1)
+	assert.True(t, proto.Equal(pp, reconstructed))
+
+	// Chunks relayed on behalf of other nodes are discarded
+	follower, _, _ = newComm(2)
+	assert.Nil(t, follower.HandleChunk(1, chunks[2], 1, 0, 1))
+	assert.Nil(t, follower.HandleChunk(4, relayedChunk(chunks[3]), 1, 0, 1))
+	assert.Nil(t, follower.HandleChunk(1, chunks[3], 1, 0, 1))
+
+	// Chunks of other views, of other leaders, or of sequences outside the window are discarded
+	follower, followerSent, _ = newComm(2)
+	assert.Nil(t, follower.HandleChunk(1, chunks[2], 2, 0, 1))
+	assert.Nil(t, follower.HandleChunk(1, chunks[2], 1, 0, 3))
+	assert.Nil(t, follower.HandleChunk(1, chunks[2], 1, 1, 1))
+	assert.Nil(t, follower.HandleChunk(1, outOfWindow(chunks[2], 3), 1, 0, 1))
+	assert.Empty(t, followerSent)
+
+	// Chunks are collected for a bounded number of pre-prepares
+	for seq := uint64(0); seq <= 2; seq++ {
+		for leader := uint64(2); leader <= 3; leader++ {
+			chunk := relayedChunk(chunks[4])
+			chunk.Seq, chunk.Leader = seq, leader
+			assert.Nil(t, follower.HandleChunk(4, chunk, 1, 0, leader))
+		}
+	}
+	assert.Nil(t, follower.HandleChunk(1, chunks[2], 1, 0, 1))
+	assert.Empty(t, followerSent)
+
+	// A pre-prepare that fails to be reconstructed is reconstructed once other chunks arrive
+	follower, _, _ = newComm(2)
+	garbage := append([]byte{}, chunks[4].Shard...)
+	garbage[0]++
+	header := proto.Clone(chunks[2]).(*protos.PrePrepareChunk)
+	garbageHash := sha256.Sum256(garbage)
+	header.ShardHashes[3] = garbageHash[:]
+	garbageChunk := relayedChunk(chunks[4])
+	garbageChunk.Shard = garbage
+	assert.Nil(t, follower.HandleChunk(1, header, 1, 0, 1))
+	assert.Nil(t, follower.HandleChunk(4, garbageChunk, 1, 0, 1))
+	reconstructed = follower.HandleChunk(3, relayedChunk(chunks[3]), 1, 0, 1)
+	assert.True(t, proto.Equal(pp, reconstructed))
+}
+
+func TestErasureCodedCommWeights(t *testing.T) {
+	basicLog, err := zap.NewDevelopment()
+	assert.NoError(t, err)
+	log := basicLog.Sugar()
+
+	nodes := []uint64{1, 2, 3, 4, 5, 6}
+	sent := make(chan sentMessage, 10)
+	comm := &mocks.CommMock{}
+	comm.On("Nodes").Return(nodes)
+	comm.On("SendConsensus", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		sent <- sentMessage{target: args.Get(0).(uint64), msg: args.Get(1).(*protos.Message)}
+	})
+	// The 3 lightest nodes may be faulty together, so the chunks of the 3 other nodes must suffice
+	weights := map[uint64]uint64{1: 3, 2: 3, 3: 1, 4: 1, 5: 1, 6: 1}
+	leader := &bft.ErasureCodedComm{
+		Comm:            comm,
+		SelfID:          1,
+		Weights:         weights,
+		MinProposalSize: 100,
+		Logger:          log,
+	}
+
+	pp := proto.Clone(prePrepare).(*protos.Message)
+	pp.GetPrePrepare().Proposal.Payload = make([]byte, 1000)
+	leader.BroadcastConsensus(pp)
+	for range nodes[1:] {
+		chunk := (<-sent).msg.GetPrePrepareChunk()
+		assert.Equal(t, uint32(3), chunk.DataShards)
+	}
+}
+
+func outOfWindow(chunk *protos.PrePrepareChunk, seq uint64) *protos.PrePrepareChunk {
+	chunk = proto.Clone(chunk).(*protos.PrePrepareChunk)
+	chunk.Seq = seq
+	return chunk
+}
+
+func relayedChunk(chunk *protos.PrePrepareChunk) *protos.PrePrepareChunk {
+	return &protos.PrePrepareChunk{
+		View:   chunk.View,
+		Seq:    chunk.Seq,
+		Leader: chunk.Leader,
+		Index:  chunk.Index,
+		Shard:  chunk.Shard,
+	}
+}
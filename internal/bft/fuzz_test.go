@@ -0,0 +1,352 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package bft_test
+
+import (
+	"testing"
+	"time"
+
+	"github.com/SmartBFT-Go/consensus/internal/bft"
+	"github.com/SmartBFT-Go/consensus/internal/bft/mocks"
+	"github.com/SmartBFT-Go/consensus/pkg/auth"
+	"github.com/SmartBFT-Go/consensus/pkg/types"
+	protos "github.com/SmartBFT-Go/consensus/smartbftprotos"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+const fuzzDeadlockTimeout = 10 * time.Second
+
+// addFuzzSeeds adds to the corpus well formed messages of every type,
+// as well as messages with missing nested fields.
+// Messages with nil nested fields which dereferenced nil pointers before they were rejected are in testdata/fuzz.
+func addFuzzSeeds(f *testing.F) {
+	nv := &protos.Message{
+		Content: &protos.Message_NewView{
+			NewView: &protos.NewView{
+				SignedViewData: []*protos.SignedViewData{viewDataMsg1.GetViewData(), {}},
+			},
+		},
+	}
+	vdWithEmptySig := proto.Clone(vd).(*protos.ViewData)
+	vdWithEmptySig.LastDecisionSignatures = append(vdWithEmptySig.LastDecisionSignatures, &protos.Signature{})
+	viewDataWithEmptySig := &protos.Message{
+		Content: &protos.Message_ViewData{
+			ViewData: &protos.SignedViewData{
+				RawViewData: bft.MarshalOrPanic(vdWithEmptySig),
+				Signer:      0,
+			},
+		},
+	}
+
+	for _, m := range []*protos.Message{
+		prePrepare,
+		prepare,
+		commit1,
+		heartbeat,
+		viewChangeMsg,
+		viewDataMsg1,
+		viewDataWithEmptySig,
+		nv,
+		{Content: &protos.Message_PrePrepare{PrePrepare: &protos.PrePrepare{View: 1}}},
+		{Content: &protos.Message_Commit{Commit: &protos.Commit{View: 1, Digest: digest}}},
+		{Content: &protos.Message_ViewData{ViewData: &protos.SignedViewData{}}},
+		{Content: &protos.Message_NewView{NewView: &protos.NewView{}}},
+		{Content: &protos.Message_Error{Error: &protos.Error{}}},
+		{Content: &protos.Message_PrePrepareChunk{PrePrepareChunk: &protos.PrePrepareChunk{View: 1, Seq: 1, Leader: 1}}},
+		{Content: &protos.Message_FetchRequests{FetchRequests: &protos.FetchRequests{
+			RequestIds: []*protos.RequestID{{Id: "1", ClientId: "1"}, {}},
+		}}},
+		{Content: &protos.Message_FetchedRequests{FetchedRequests: &protos.FetchedRequests{Requests: [][]byte{{1}, nil}}}},
+		{},
+	} {
+		for sender := uint8(0); sender < 4; sender++ {
+			f.Add(sender, bft.MarshalOrPanic(m))
+		}
+	}
+}
+
+func FuzzViewHandleMessage(f *testing.F) {
+	addFuzzSeeds(f)
+
+	f.Fuzz(func(t *testing.T, sender uint8, raw []byte) {
+		m := &protos.Message{}
+		if err := proto.Unmarshal(raw, m); err != nil {
+			return
+		}
+
+		comm := &mocks.CommMock{}
+		comm.On("BroadcastConsensus", mock.Anything)
+		comm.On("SendConsensus", mock.Anything, mock.Anything)
+		synchronizer := &mocks.Synchronizer{}
+		synchronizer.On("Sync")
+		fd := &mocks.FailureDetector{}
+		fd.On("Complain", mock.Anything)
+		decider := &mocks.Decider{}
+		decider.On("Decide", mock.Anything, mock.Anything, mock.Anything)
+		verifier := &mocks.VerifierMock{}
+		verifier.On("VerifyProposal", mock.Anything).Return(nil, nil)
+		verifier.On("VerifyConsenterSig", mock.Anything, mock.Anything).Return(nil)
+		verifier.On("VerificationSequence").Return(uint64(1))
+		signer := &mocks.SignerMock{}
+		signer.On("SignProposal", mock.Anything).Return(&types.Signature{Id: 2})
+
+		view := &bft.View{
+			SelfID:           2,
+			N:                4,
+			LeaderID:         1,
+			Quorum:           3,
+			Number:           1,
+			ProposalSequence: 0,
+			Logger:           zap.NewNop().Sugar(),
+			Comm:             comm,
+			Sync:             synchronizer,
+			FailureDetector:  fd,
+			Decider:          decider,
+			Verifier:         verifier,
+			Signer:           signer,
+			State:            &bft.StateRecorder{},
+		}
+		view.Start()
+
+		assertNoDeadlock(t, func() {
+			view.HandleMessage(uint64(sender%4), m)
+			<-view.Drained()
+			view.Abort()
+		})
+	})
+}
+
+func FuzzViewChangerHandleMessage(f *testing.F) {
+	addFuzzSeeds(f)
+
+	f.Fuzz(func(t *testing.T, sender uint8, raw []byte) {
+		m := &protos.Message{}
+		if err := proto.Unmarshal(raw, m); err != nil {
+			return
+		}
+
+		comm := &mocks.CommMock{}
+		comm.On("Nodes").Return([]uint64{0, 1, 2, 3})
+		comm.On("BroadcastConsensus", mock.Anything)
+		comm.On("SendConsensus", mock.Anything, mock.Anything)
+		signer := &mocks.SignerMock{}
+		signer.On("Sign", mock.Anything).Return([]byte{1, 2, 3})
+		verifier := &mocks.VerifierMock{}
+		verifier.On("VerifySignature", mock.Anything).Return(nil)
+		verifier.On("VerifyConsenterSig", mock.Anything, mock.Anything).Return(nil)
+		verifier.On("VerifyProposal", mock.Anything).Return(nil, nil)
+		app := &mocks.ApplicationMock{}
+		app.On("Deliver", mock.Anything, mock.Anything)
+		synchronizer := &mocks.Synchronizer{}
+		synchronizer.On("Sync")
+		controller := &mocks.ViewController{}
+		controller.On("AbortView")
+		controller.On("ViewChanged", mock.Anything, mock.Anything)
+		reqTimer := &mocks.RequestsTimer{}
+		reqTimer.On("StopTimers")
+		reqTimer.On("RestartTimers")
+		reqTimer.On("RemoveRequest", mock.Anything).Return(nil)
+
+		checkpoint := types.Checkpoint{}
+		checkpoint.Set(lastDecision, lastDecisionSignatures)
+
+		vc := &bft.ViewChanger{
+			SelfID:        1,
+			N:             4,
+			Comm:          comm,
+			Signer:        signer,
+			Verifier:      verifier,
+			Application:   app,
+			Synchronizer:  synchronizer,
+			Controller:    controller,
+			RequestsTimer: reqTimer,
+			Checkpoint:    &checkpoint,
+			InFlight:      &bft.InFlightData{},
+			Ticker:        make(chan time.Time),
+			Logger:        zap.NewNop().Sugar(),
+		}
+		// Node 1 is the leader of view 1, so it processes view data and new views of every sender
+		vc.Start(1)
+
+		assertNoDeadlock(t, func() {
+			vc.HandleMessage(uint64(sender%4), m)
+			<-vc.Drained()
+			vc.Stop()
+		})
+	})
+}
+
+func FuzzControllerProcessMessages(f *testing.F) {
+	addFuzzSeeds(f)
+
+	secret := []byte("secret")
+	keys := map[uint64][]byte{0: secret, 1: secret, 2: secret, 3: secret}
+
+	f.Fuzz(func(t *testing.T, sender uint8, raw []byte) {
+		m := &protos.Message{}
+		if err := proto.Unmarshal(raw, m); err != nil {
+			return
+		}
+		from := uint64(sender % 4)
+		// Messages are authenticated by their sender, unless they carry authentication of their own
+		if len(m.Authentication) == 0 {
+			m = bft.AuthenticateMessage(&auth.HMACAuthenticator{SelfID: from, Keys: keys}, 2, m)
+		}
+
+		log := zap.NewNop().Sugar()
+		comm := &mocks.CommMock{}
+		comm.On("Nodes").Return([]uint64{0, 1, 2, 3})
+		comm.On("BroadcastConsensus", mock.Anything)
+		comm.On("SendConsensus", mock.Anything, mock.Anything)
+		batcherClosed := make(chan struct{})
+		batcher := &mocks.Batcher{}
+		batcher.On("Close").Run(func(arguments mock.Arguments) {
+			close(batcherClosed)
+		})
+		batcher.On("Reset")
+		batcher.On("NextBatch").Run(func(arguments mock.Arguments) {
+			<-batcherClosed
+		}).Return(nil)
+		pool := &mocks.RequestPool{}
+		pool.On("Close")
+		pool.On("StopTimers")
+		pool.On("RestartTimers")
+		pool.On("RemoveRequest", mock.Anything).Return(nil)
+		leaderMon := &mocks.LeaderMonitor{}
+		leaderMon.On("ChangeRole", mock.Anything, mock.Anything, mock.Anything)
+		leaderMon.On("ProcessMsg", mock.Anything, mock.Anything)
+		leaderMon.On("Close")
+		fd := &mocks.FailureDetector{}
+		fd.On("Complain", mock.Anything, mock.Anything)
+		synchronizer := &mocks.SynchronizerMock{}
+		synchronizer.On("Sync").Return(protos.ViewMetadata{ViewId: 1}, uint64(0))
+		verifier := &mocks.VerifierMock{}
+		verifier.On("VerifyProposal", mock.Anything).Return(nil, nil)
+		verifier.On("VerifyConsenterSig", mock.Anything, mock.Anything).Return(nil)
+		verifier.On("VerifySignature", mock.Anything).Return(nil)
+		verifier.On("VerificationSequence").Return(uint64(1))
+		signer := &mocks.SignerMock{}
+		signer.On("Sign", mock.Anything).Return([]byte{1, 2, 3})
+		signer.On("SignProposal", mock.Anything).Return(&types.Signature{Id: 2})
+		vcController := &mocks.ViewController{}
+		vcController.On("AbortView")
+		vcController.On("ViewChanged", mock.Anything, mock.Anything)
+		vcSynchronizer := &mocks.Synchronizer{}
+		vcSynchronizer.On("Sync")
+		reqTimer := &mocks.RequestsTimer{}
+		reqTimer.On("StopTimers")
+		reqTimer.On("RestartTimers")
+		reqTimer.On("RemoveRequest", mock.Anything).Return(nil)
+
+		checkpoint := types.Checkpoint{}
+		checkpoint.Set(lastDecision, lastDecisionSignatures)
+
+		vc := &bft.ViewChanger{
+			SelfID:        2,
+			N:             4,
+			Comm:          comm,
+			Signer:        signer,
+			Verifier:      verifier,
+			Synchronizer:  vcSynchronizer,
+			Controller:    vcController,
+			RequestsTimer: reqTimer,
+			Checkpoint:    &checkpoint,
+			InFlight:      &bft.InFlightData{},
+			Ticker:        make(chan time.Time),
+			Logger:        log,
+		}
+
+		requests := bft.NewPool(log, &fuzzRequestInspector{}, noopTimeoutHandler, bft.PoolOptions{QueueSize: 10})
+		defer requests.Close()
+
+		controller := &bft.Controller{
+			ID:              2,
+			N:               4,
+			RequestPool:     pool,
+			Batcher:         batcher,
+			LeaderMonitor:   leaderMon,
+			FailureDetector: fd,
+			Verifier:        verifier,
+			Signer:          signer,
+			Synchronizer:    synchronizer,
+			Comm:            comm,
+			Checkpoint:      &checkpoint,
+			ViewChanger:     vc,
+			Logger:          log,
+			Authenticator:   &auth.HMACAuthenticator{SelfID: 2, Keys: keys},
+			ProtocolVersions: &bft.ProtocolVersions{
+				SelfID:     2,
+				Checkpoint: &checkpoint,
+				Logger:     log,
+			},
+			Dissemination: &bft.Dissemination{
+				Pool:      requests,
+				Inspector: &fuzzRequestInspector{},
+				Comm:      comm,
+				Logger:    log,
+			},
+			ErasureCoding: &bft.ErasureCodedComm{
+				Comm:            comm,
+				SelfID:          2,
+				MinProposalSize: 100,
+				Logger:          log,
+			},
+		}
+		pb := &mocks.ProposerBuilder{}
+		pb.On("NewProposer", mock.Anything, mock.Anything, mock.Anything, mock.Anything).
+			Return(func(leader uint64, proposalSequence uint64, viewNum uint64, quorumSize int) bft.Proposer {
+				return &bft.View{
+					SelfID:           2,
+					N:                4,
+					LeaderID:         leader,
+					Quorum:           quorumSize,
+					Number:           viewNum,
+					ProposalSequence: proposalSequence,
+					Logger:           log,
+					Comm:             comm,
+					Sync:             controller,
+					FailureDetector:  fd,
+					Decider:          controller,
+					Verifier:         verifier,
+					Signer:           signer,
+					State:            &bft.StateRecorder{},
+				}
+			})
+		controller.ProposerBuilder = pb
+
+		vc.Start(1)
+		controller.Start(1, 0)
+
+		assertNoDeadlock(t, func() {
+			controller.ProcessMessages(from, m)
+			controller.Stop()
+			vc.Stop()
+		})
+	})
+}
+
+// fuzzRequestInspector identifies requests by their contents, as fuzzed requests are arbitrary bytes.
+type fuzzRequestInspector struct{}
+
+func (*fuzzRequestInspector) RequestID(req []byte) types.RequestInfo {
+	return types.RequestInfo{ClientID: "fuzz", ID: string(req)}
+}
+
+func assertNoDeadlock(t *testing.T, f func()) {
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		f()
+	}()
+
+	select {
+	case <-done:
+	case <-time.After(fuzzDeadlockTimeout):
+		t.Fatalf("did not finish within %v", fuzzDeadlockTimeout)
+	}
+}
@@ -0,0 +1,238 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package bft
+
+import (
+	"sync"
+	"time"
+
+	"github.com/SmartBFT-Go/consensus/pkg/api"
+	protos "github.com/SmartBFT-Go/consensus/smartbftprotos"
+	"github.com/pkg/errors"
+)
+
+const (
+	// DefaultIncomingMessageQueueSize is the number of incoming messages
+	// buffered for each sender, unless configured otherwise.
+	DefaultIncomingMessageQueueSize = 10
+)
+
+// FlowControl configures the buffering of incoming messages.
+type FlowControl struct {
+	// QueueSize is the number of messages buffered for each sender.
+	// If zero, DefaultIncomingMessageQueueSize is used.
+	QueueSize int
+	// RateLimit is the number of messages per second accepted from each sender.
+	// If zero, the rate is not limited.
+	RateLimit int
+}
+
+// Inbox buffers incoming messages in a bounded queue per sender,
+// and hands them out in a round robin manner across the senders.
+// Messages that don't fit in the queue of their sender, or exceed its rate limit, are dropped,
+// so one sender can neither block the caller nor starve the messages of other senders.
+type Inbox struct {
+	queueSize int
+	rateLimit int
+	onDrop    func(sender uint64, m *protos.Message, reason error)
+	now       func() time.Time
+
+	lock    sync.Mutex
+	queues  map[uint64]*senderQueue
+	senders []uint64
+	next    int
+	pending int
+	signal  chan struct{}
+	// Messages returned by Get which are not Done yet
+	processing int
+	// Closed while there are neither pending nor processed messages
+	drained chan struct{}
+}
+
+type senderQueue struct {
+	msgs       []*protos.Message
+	dropped    uint64
+	tokens     float64
+	lastRefill time.Time
+}
+
+// NewInbox creates an inbox with the given flow control,
+// which invokes onDrop (if not nil) whenever it drops a message.
+func NewInbox(fc FlowControl, onDrop func(sender uint64, m *protos.Message, reason error)) *Inbox {
+	queueSize := fc.QueueSize
+	if queueSize <= 0 {
+		queueSize = DefaultIncomingMessageQueueSize
+	}
+	drained := make(chan struct{})
+	close(drained)
+	return &Inbox{
+		queueSize: queueSize,
+		rateLimit: fc.RateLimit,
+		onDrop:    onDrop,
+		now:       time.Now,
+		queues:    make(map[uint64]*senderQueue),
+		signal:    make(chan struct{}, 1),
+		drained:   drained,
+	}
+}
+
+// Put adds a message of the given sender, without blocking.
+// It returns false if the message was dropped.
+func (in *Inbox) Put(sender uint64, m *protos.Message) bool {
+	in.lock.Lock()
+	q := in.queue(sender)
+
+	var reason error
+	if !in.allow(q) {
+		q.dropped++
+		reason = errors.Errorf("sender %d exceeded the rate limit of %d messages per second, %d messages dropped so far",
+			sender, in.rateLimit, q.dropped)
+	} else if len(q.msgs) >= in.queueSize {
+		q.dropped++
+		reason = errors.Errorf("queue of sender %d is full, %d messages dropped so far", sender, q.dropped)
+	} else {
+		if in.idle() {
+			in.drained = make(chan struct{})
+		}
+		q.msgs = append(q.msgs, m)
+		in.pending++
+	}
+	in.lock.Unlock()
+
+	if reason != nil {
+		if in.onDrop != nil {
+			in.onDrop(sender, m, reason)
+		}
+		return false
+	}
+
+	in.notify()
+	return true
+}
+
+// Ready returns a channel which is signaled when there are messages in the inbox.
+// A single Get should follow each signal, as the inbox re-signals while messages remain.
+func (in *Inbox) Ready() <-chan struct{} {
+	return in.signal
+}
+
+// Get removes and returns the next message, taking turns among the senders.
+// It returns false if there are no messages.
+// Done must be called once the returned message is processed.
+func (in *Inbox) Get() (uint64, *protos.Message, bool) {
+	in.lock.Lock()
+	defer in.lock.Unlock()
+
+	if in.pending == 0 {
+		return 0, nil, false
+	}
+
+	for i := 0; i < len(in.senders); i++ {
+		index := (in.next + i) % len(in.senders)
+		sender := in.senders[index]
+		q := in.queues[sender]
+		if len(q.msgs) == 0 {
+			continue
+		}
+		m := q.msgs[0]
+		q.msgs[0] = nil
+		q.msgs = q.msgs[1:]
+		in.next = index + 1
+		in.pending--
+		in.processing++
+		if in.pending > 0 {
+			in.notify()
+		}
+		return sender, m, true
+	}
+
+	return 0, nil, false
+}
+
+// Done marks a message returned by Get as processed.
+func (in *Inbox) Done() {
+	in.lock.Lock()
+	defer in.lock.Unlock()
+
+	in.processing--
+	if in.idle() {
+		close(in.drained)
+	}
+}
+
+// Drained returns a channel which is closed once every message put in the inbox so far is processed.
+func (in *Inbox) Drained() <-chan struct{} {
+	in.lock.Lock()
+	defer in.lock.Unlock()
+	return in.drained
+}
+
+func (in *Inbox) idle() bool {
+	return in.pending == 0 && in.processing == 0
+}
+
+// Dropped returns the number of messages of the given sender that were dropped.
+func (in *Inbox) Dropped(sender uint64) uint64 {
+	in.lock.Lock()
+	defer in.lock.Unlock()
+
+	if q, exists := in.queues[sender]; exists {
+		return q.dropped
+	}
+	return 0
+}
+
+func (in *Inbox) queue(sender uint64) *senderQueue {
+	q, exists := in.queues[sender]
+	if !exists {
+		q = &senderQueue{
+			tokens:     float64(in.rateLimit),
+			lastRefill: in.now(),
+		}
+		in.queues[sender] = q
+		in.senders = append(in.senders, sender)
+	}
+	return q
+}
+
+// allow consumes a token of the sender's bucket, which is refilled at the rate limit,
+// up to a burst of one second worth of messages.
+func (in *Inbox) allow(q *senderQueue) bool {
+	if in.rateLimit <= 0 {
+		return true
+	}
+
+	now := in.now()
+	q.tokens += now.Sub(q.lastRefill).Seconds() * float64(in.rateLimit)
+	if q.tokens > float64(in.rateLimit) {
+		q.tokens = float64(in.rateLimit)
+	}
+	q.lastRefill = now
+
+	if q.tokens < 1 {
+		return false
+	}
+	q.tokens--
+	return true
+}
+
+func (in *Inbox) notify() {
+	select {
+	case in.signal <- struct{}{}:
+	default:
+	}
+}
+
+// reportDroppedMessage returns a function which logs messages dropped by an inbox,
+// and notifies the given handler (if not nil) about them.
+func reportDroppedMessage(logger api.Logger, handler api.MessageRejectionHandler) func(uint64, *protos.Message, error) {
+	return func(sender uint64, m *protos.Message, reason error) {
+		logger.Warnf("Dropped message %v from %d: %v", m, sender, reason)
+		if handler != nil {
+			handler.OnMessageRejected(sender, m, reason)
+		}
+	}
+}
@@ -0,0 +1,165 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package bft_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SmartBFT-Go/consensus/internal/bft"
+	protos "github.com/SmartBFT-Go/consensus/smartbftprotos"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInboxRoundRobin(t *testing.T) {
+	inbox := bft.NewInbox(bft.FlowControl{}, nil)
+
+	for i := 0; i < 3; i++ {
+		assert.True(t, inbox.Put(1, prepare))
+	}
+	assert.True(t, inbox.Put(2, commit1))
+	assert.True(t, inbox.Put(3, heartbeat))
+
+	var senders []uint64
+	for {
+		sender, _, ok := inbox.Get()
+		if !ok {
+			break
+		}
+		senders = append(senders, sender)
+	}
+	assert.Equal(t, []uint64{1, 2, 3, 1, 1}, senders)
+}
+
+func TestInboxReady(t *testing.T) {
+	inbox := bft.NewInbox(bft.FlowControl{}, nil)
+
+	select {
+	case <-inbox.Ready():
+		t.Fatal("empty inbox is ready")
+	default:
+	}
+
+	inbox.Put(1, prepare)
+	inbox.Put(2, prepare)
+
+	for i := 0; i < 2; i++ {
+		select {
+		case <-inbox.Ready():
+		case <-time.After(time.Second):
+			t.Fatal("inbox is not ready")
+		}
+		_, _, ok := inbox.Get()
+		assert.True(t, ok)
+	}
+
+	select {
+	case <-inbox.Ready():
+		t.Fatal("drained inbox is ready")
+	default:
+	}
+}
+
+func TestInboxQueueFull(t *testing.T) {
+	var lock sync.Mutex
+	var reasons []string
+	onDrop := func(sender uint64, m *protos.Message, reason error) {
+		lock.Lock()
+		defer lock.Unlock()
+		assert.Equal(t, uint64(1), sender)
+		assert.Equal(t, prepare, m)
+		reasons = append(reasons, reason.Error())
+	}
+
+	inbox := bft.NewInbox(bft.FlowControl{QueueSize: 2}, onDrop)
+
+	assert.True(t, inbox.Put(1, prepare))
+	assert.True(t, inbox.Put(1, prepare))
+	assert.False(t, inbox.Put(1, prepare))
+	assert.False(t, inbox.Put(1, prepare))
+	// The queue of one sender doesn't affect other senders
+	assert.True(t, inbox.Put(2, commit1))
+
+	assert.Equal(t, uint64(2), inbox.Dropped(1))
+	assert.Equal(t, uint64(0), inbox.Dropped(2))
+	assert.Equal(t, []string{
+		"queue of sender 1 is full, 1 messages dropped so far",
+		"queue of sender 1 is full, 2 messages dropped so far",
+	}, reasons)
+
+	// Once a message is taken out, there is room for another one
+	sender, _, _ := inbox.Get()
+	assert.Equal(t, uint64(1), sender)
+	assert.True(t, inbox.Put(1, prepare))
+}
+
+func TestInboxDrained(t *testing.T) {
+	inbox := bft.NewInbox(bft.FlowControl{}, nil)
+	isDrained := func() bool {
+		select {
+		case <-inbox.Drained():
+			return true
+		default:
+			return false
+		}
+	}
+
+	assert.True(t, isDrained())
+	inbox.Put(1, prepare)
+	inbox.Put(2, prepare)
+	assert.False(t, isDrained())
+
+	// Messages are drained once they are processed, not once they are taken out of the inbox
+	inbox.Get()
+	inbox.Get()
+	assert.False(t, isDrained())
+	inbox.Done()
+	assert.False(t, isDrained())
+	inbox.Done()
+	assert.True(t, isDrained())
+
+	inbox.Put(1, prepare)
+	assert.False(t, isDrained())
+}
+
+func TestInboxDefaultQueueSize(t *testing.T) {
+	inbox := bft.NewInbox(bft.FlowControl{}, nil)
+
+	for i := 0; i < bft.DefaultIncomingMessageQueueSize; i++ {
+		assert.True(t, inbox.Put(1, prepare))
+	}
+	assert.False(t, inbox.Put(1, prepare))
+	assert.Equal(t, uint64(1), inbox.Dropped(1))
+}
+
+func TestInboxRateLimit(t *testing.T) {
+	var dropped int
+	onDrop := func(sender uint64, _ *protos.Message, reason error) {
+		dropped++
+		assert.Contains(t, reason.Error(), "sender 1 exceeded the rate limit of 5 messages per second")
+	}
+
+	inbox := bft.NewInbox(bft.FlowControl{QueueSize: 100, RateLimit: 5}, onDrop)
+
+	var accepted int
+	for i := 0; i < 10; i++ {
+		if inbox.Put(1, prepare) {
+			accepted++
+		}
+	}
+	// A burst of one second worth of messages is accepted
+	assert.Equal(t, 5, accepted)
+	assert.Equal(t, 5, dropped)
+	assert.Equal(t, uint64(5), inbox.Dropped(1))
+
+	// Other senders are not limited by the rate of sender 1
+	assert.True(t, inbox.Put(2, prepare))
+
+	// The rate limit is replenished over time
+	time.Sleep(300 * time.Millisecond)
+	assert.True(t, inbox.Put(1, prepare))
+}
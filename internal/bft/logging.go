@@ -0,0 +1,68 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package bft
+
+import (
+	"sync/atomic"
+
+	"github.com/SmartBFT-Go/consensus/pkg/api"
+)
+
+// WithFields returns a child of the logger which adds the given alternating keys and values
+// to every entry, if the logger is an api.StructuredLogger. Otherwise, the logger is returned as is.
+// The fields of a child of a scopedLogger don't advance with its scope.
+func WithFields(logger api.Logger, keysAndValues ...interface{}) api.Logger {
+	if sl, isScoped := logger.(*scopedLogger); isScoped {
+		logger = sl.logger()
+	}
+	return api.WithFields(logger, keysAndValues...)
+}
+
+// scopedLogger is a Logger scoped with fields whose values advance while the logger is in use,
+// possibly by several goroutines.
+type scopedLogger struct {
+	base    api.Logger
+	current atomic.Value // holds a loggerRef
+}
+
+type loggerRef struct {
+	api.Logger
+}
+
+func newScopedLogger(base api.Logger, keysAndValues ...interface{}) *scopedLogger {
+	sl := &scopedLogger{base: base}
+	sl.scope(keysAndValues...)
+	return sl
+}
+
+// scope replaces the fields the entries are logged with.
+func (sl *scopedLogger) scope(keysAndValues ...interface{}) {
+	sl.current.Store(loggerRef{Logger: WithFields(sl.base, keysAndValues...)})
+}
+
+func (sl *scopedLogger) logger() api.Logger {
+	return sl.current.Load().(loggerRef).Logger
+}
+
+func (sl *scopedLogger) Debugf(template string, args ...interface{}) {
+	sl.logger().Debugf(template, args...)
+}
+
+func (sl *scopedLogger) Infof(template string, args ...interface{}) {
+	sl.logger().Infof(template, args...)
+}
+
+func (sl *scopedLogger) Errorf(template string, args ...interface{}) {
+	sl.logger().Errorf(template, args...)
+}
+
+func (sl *scopedLogger) Warnf(template string, args ...interface{}) {
+	sl.logger().Warnf(template, args...)
+}
+
+func (sl *scopedLogger) Panicf(template string, args ...interface{}) {
+	sl.logger().Panicf(template, args...)
+}
@@ -0,0 +1,139 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package bft_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/SmartBFT-Go/consensus/internal/bft"
+	"github.com/SmartBFT-Go/consensus/internal/bft/mocks"
+	"github.com/SmartBFT-Go/consensus/pkg/logging"
+	"github.com/SmartBFT-Go/consensus/pkg/types"
+	protos "github.com/SmartBFT-Go/consensus/smartbftprotos"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+type logEntry struct {
+	Msg    string
+	Node   uint64
+	View   uint64
+	Seq    uint64
+	Sender uint64
+}
+
+func TestViewLoggerScopedWithSequence(t *testing.T) {
+	buff := &bytes.Buffer{}
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.Lock(zapcore.AddSync(buff)), zapcore.DebugLevel)
+	nodeLogger := bft.WithFields(logging.NewZapLogger(zap.New(core).Sugar()), "node", 1)
+
+	comm := &mocks.CommMock{}
+	comm.On("BroadcastConsensus", mock.Anything)
+	decider := &mocks.Decider{}
+	decided := make(chan struct{})
+	decider.On("Decide", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		decided <- struct{}{}
+	})
+	verifier := &mocks.VerifierMock{}
+	verifier.On("VerificationSequence").Return(uint64(1))
+	verifier.On("VerifyProposal", mock.Anything).Return(nil, nil)
+	verifier.On("VerifyConsenterSig", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	signer := &mocks.SignerMock{}
+	signer.On("SignProposal", mock.Anything).Return(&types.Signature{
+		Id:    1,
+		Value: []byte{4},
+	})
+	state := &mocks.State{}
+	state.On("Restore", mock.Anything).Return(nil)
+	state.On("Save", mock.Anything).Return(nil)
+	pm := &bft.ProposalMaker{
+		N:        4,
+		SelfID:   1,
+		Decider:  decider,
+		Logger:   nodeLogger,
+		Comm:     comm,
+		Verifier: verifier,
+		Signer:   signer,
+		State:    state,
+	}
+	view := pm.NewProposer(1, 0, 1, 3).(*bft.View)
+	view.Start()
+
+	view.Propose(proposal)
+	view.HandleMessage(2, prepare)
+	view.HandleMessage(3, prepare)
+	view.HandleMessage(2, commit2)
+	view.HandleMessage(3, commit3)
+	<-decided
+	view.Abort()
+
+	entries := make(map[string]logEntry)
+	decoder := json.NewDecoder(bytes.NewReader(buff.Bytes()))
+	for {
+		var entry logEntry
+		err := decoder.Decode(&entry)
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		entries[entry.Msg] = entry
+	}
+
+	assert.Equal(t, logEntry{Msg: "Deciding on seq 0", Node: 1, View: 1, Seq: 0}, entries["Deciding on seq 0"])
+	assert.Equal(t, logEntry{Msg: "Sequence: 0-->1", Node: 1, View: 1, Seq: 1}, entries["Sequence: 0-->1"])
+}
+
+func TestViewChangerLoggerScopedWithView(t *testing.T) {
+	buff := &bytes.Buffer{}
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(zap.NewProductionEncoderConfig()), zapcore.Lock(zapcore.AddSync(buff)), zapcore.DebugLevel)
+	nodeLogger := bft.WithFields(logging.NewZapLogger(zap.New(core).Sugar()), "node", 2)
+
+	comm := &mocks.CommMock{}
+	comm.On("Nodes").Return([]uint64{0, 1, 2, 3})
+	verifier := &mocks.VerifierMock{}
+	verifier.On("VerifySignature", mock.Anything).Return(nil)
+	vc := &bft.ViewChanger{
+		SelfID:   2,
+		N:        4,
+		Comm:     comm,
+		Logger:   nodeLogger,
+		Verifier: verifier,
+		Ticker:   make(chan time.Time),
+	}
+	vc.Start(1)
+	defer vc.Stop()
+
+	msg := proto.Clone(viewDataMsg1).(*protos.Message)
+	msg.GetViewData().Signer = 10
+	vc.HandleMessage(3, msg)
+	<-vc.Drained()
+
+	var rejected logEntry
+	decoder := json.NewDecoder(bytes.NewReader(buff.Bytes()))
+	for {
+		var entry logEntry
+		err := decoder.Decode(&entry)
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		if strings.Contains(entry.Msg, "is not the sender") {
+			rejected = entry
+		}
+	}
+
+	assert.Equal(t, uint64(2), rejected.Node)
+	assert.Equal(t, uint64(1), rejected.View)
+	assert.Equal(t, uint64(3), rejected.Sender)
+}
@@ -33,9 +33,9 @@ func (_m *RequestPool) NextRequests(n int) [][]byte {
 	return r0
 }
 
-// Prune provides a mock function with given fields: predicate
-func (_m *RequestPool) Prune(predicate func([]byte) error) {
-	_m.Called(predicate)
+// Prune provides a mock function with given fields: verify
+func (_m *RequestPool) Prune(verify func([][]byte) []error) {
+	_m.Called(verify)
 }
 
 // RemoveRequest provides a mock function with given fields: request
@@ -89,3 +89,17 @@ func (_m *RequestPool) Submit(request []byte) error {
 
 	return r0
 }
+
+// TrySubmit provides a mock function with given fields: request
+func (_m *RequestPool) TrySubmit(request []byte) error {
+	ret := _m.Called(request)
+
+	var r0 error
+	if rf, ok := ret.Get(0).(func([]byte) error); ok {
+		r0 = rf(request)
+	} else {
+		r0 = ret.Error(0)
+	}
+
+	return r0
+}
@@ -97,15 +97,29 @@ func (rp *Pool) isStopped() bool {
 	return rp.stopped
 }
 
-// Submit a request into the pool, returns an error when request is already in the pool
+// Submit a request into the pool, returns an error when request is already in the pool.
+// If the pool is full, it waits until there is room in it.
 func (rp *Pool) Submit(request []byte) error {
+	return rp.submit(request, true)
+}
+
+// TrySubmit submits a request into the pool like Submit, but returns an error instead of waiting if the pool is full.
+func (rp *Pool) TrySubmit(request []byte) error {
+	return rp.submit(request, false)
+}
+
+func (rp *Pool) submit(request []byte, wait bool) error {
 	reqInfo := rp.inspector.RequestID(request)
 	if rp.isStopped() {
 		return errors.Errorf("pool stopped, request rejected: %s", reqInfo)
 	}
 
 	// do not wait for a semaphore with a lock, as it will prevent draining the pool.
-	if err := rp.semaphore.Acquire(context.Background(), 1); err != nil {
+	if !wait {
+		if !rp.semaphore.TryAcquire(1) {
+			return errors.Errorf("pool is full, request rejected: %s", reqInfo)
+		}
+	} else if err := rp.semaphore.Acquire(context.Background(), 1); err != nil {
 		return errors.Wrapf(err, "acquiring semaphore for request: %s", reqInfo)
 	}
 
@@ -115,7 +129,7 @@ func (rp *Pool) Submit(request []byte) error {
 	if _, exist := rp.existMap[reqInfo]; exist {
 		rp.semaphore.Release(1)
 		errStr := fmt.Sprintf("request %s already exists in the pool", reqInfo)
-		rp.logger.Errorf(errStr)
+		rp.logger.Errorf("%s", errStr)
 		return errors.New(errStr)
 	}
 
@@ -164,13 +178,25 @@ func (rp *Pool) NextRequests(n int) [][]byte {
 	return buff
 }
 
-// Prune removes requests for which the given predicate returns error.
-func (rp *Pool) Prune(predicate func([]byte) error) {
+// Lookup returns the request with the given request info, if it is in the pool.
+func (rp *Pool) Lookup(requestInfo types.RequestInfo) ([]byte, bool) {
+	rp.lock.Lock()
+	defer rp.lock.Unlock()
+
+	element, exist := rp.existMap[requestInfo]
+	if !exist {
+		return nil, false
+	}
+	return element.Value.(*requestItem).request, true
+}
+
+// Prune removes requests for which the given verification returns error.
+// The verification is given all requests at once, and returns the error of each of them.
+func (rp *Pool) Prune(verify func([][]byte) []error) {
 	reqVec, infoVec := rp.copyRequests()
 
 	var numPruned int
-	for i, req := range reqVec {
-		err := predicate(req)
+	for i, err := range verify(reqVec) {
 		if err == nil {
 			continue
 		}
@@ -211,8 +237,8 @@ func (rp *Pool) RemoveRequest(requestInfo types.RequestInfo) error {
 	element, exist := rp.existMap[requestInfo]
 	if !exist {
 		errStr := fmt.Sprintf("request %s is not in the pool at remove time", requestInfo)
-		rp.logger.Warnf(errStr)
-		return fmt.Errorf(errStr)
+		rp.logger.Warnf("%s", errStr)
+		return errors.New(errStr)
 	}
 
 	return rp.deleteRequest(element, requestInfo)
@@ -48,6 +48,17 @@ func TestReqPoolBasic(t *testing.T) {
 		assert.EqualError(t, err, "pool stopped, request rejected: {1 1}")
 	})
 
+	t.Run("remove missing", func(t *testing.T) {
+		timeoutHandler := &mocks.RequestTimeoutHandler{}
+
+		pool := bft.NewPool(log, insp, timeoutHandler, bft.PoolOptions{QueueSize: 3, RequestTimeout: time.Hour})
+		defer pool.Close()
+
+		// The request ID is not interpreted as a format string
+		err = pool.RemoveRequest(types.RequestInfo{ID: "100%d", ClientID: "alice"})
+		assert.EqualError(t, err, "request {alice 100%d} is not in the pool at remove time")
+	})
+
 	t.Run("submit remove next", func(t *testing.T) {
 		timeoutHandler := &mocks.RequestTimeoutHandler{}
 
@@ -186,6 +197,21 @@ func TestReqPoolCapacity(t *testing.T) {
 		timeoutHandler.AssertNumberOfCalls(t, "OnLeaderFwdRequestTimeout", .0)
 		pool.Close()
 	})
+
+	t.Run("try submit to a full pool", func(t *testing.T) {
+		timeoutHandler := &mocks.RequestTimeoutHandler{}
+		pool := bft.NewPool(log, insp, timeoutHandler, bft.PoolOptions{QueueSize: 1, RequestTimeout: time.Hour})
+		defer pool.Close()
+
+		assert.NoError(t, pool.TrySubmit(makeTestRequest("1", "1", "foo")))
+		// The pool is full, so the request is rejected instead of waiting for room
+		assert.EqualError(t, pool.TrySubmit(makeTestRequest("2", "2", "foo")), "pool is full, request rejected: {2 2}")
+		assert.Equal(t, 1, pool.Size())
+
+		assert.NoError(t, pool.RemoveRequest(types.RequestInfo{ID: "1", ClientID: "1"}))
+		assert.NoError(t, pool.TrySubmit(makeTestRequest("2", "2", "foo")))
+		assert.Equal(t, 1, pool.Size())
+	})
 }
 
 func TestReqPoolPrune(t *testing.T) {
@@ -208,11 +234,14 @@ func TestReqPoolPrune(t *testing.T) {
 	assert.NoError(t, err)
 	assert.Equal(t, 2, pool.Size())
 
-	pool.Prune(func(payload []byte) error {
-		if bytes.Equal(byteReq1, payload) {
-			return errors.New("revoked")
+	pool.Prune(func(payloads [][]byte) []error {
+		errs := make([]error, len(payloads))
+		for i, payload := range payloads {
+			if bytes.Equal(byteReq1, payload) {
+				errs[i] = errors.New("revoked")
+			}
 		}
-		return nil
+		return errs
 	})
 
 	assert.Equal(t, 1, pool.Size())
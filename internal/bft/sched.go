@@ -206,10 +206,11 @@ func (s *Scheduler) checkAndExecute() bool {
 	task := s.queue.DeQueue()
 
 	f := func() {
-		if task.isStopped() {
-			return
+		// Even a stopped Task needs to signal the scheduler,
+		// as other tasks might have been re-added while it occupied the executor.
+		if !task.isStopped() {
+			task.F()
 		}
-		task.F()
 		select {
 		case s.signalChan <- struct{}{}:
 		case <-s.exec.stopChan:
@@ -94,6 +94,34 @@ func TestSchedule(t *testing.T) {
 	s.Stop()
 }
 
+func TestScheduleAfterStoppedTask(t *testing.T) {
+	// A stopped task occupies the executor as any other task, so the tasks which couldn't be executed
+	// meanwhile are executed once it is done, without waiting for the next tick.
+	// It is repeated, as the executor might take the stopped task before the next task is tried.
+	for i := 0; i < 100; i++ {
+		timeChan := make(chan time.Time, 1)
+		s := bft.NewScheduler(timeChan)
+		s.Start()
+
+		start := time.Now()
+		timeChan <- start
+
+		done := make(chan struct{})
+		s.Schedule(time.Second, func() {}).Stop()
+		s.Schedule(time.Second*2, func() {
+			close(done)
+		})
+
+		timeChan <- start.Add(time.Second * 3)
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("task scheduled after a stopped task was not executed")
+		}
+		s.Stop()
+	}
+}
+
 func TestEnqueueDequeue(t *testing.T) {
 	q := bft.NewTaskQueue()
 	now := time.Now()
@@ -0,0 +1,150 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package bft
+
+import (
+	"sync"
+
+	"github.com/SmartBFT-Go/consensus/pkg/api"
+	"github.com/SmartBFT-Go/consensus/pkg/types"
+	protos "github.com/SmartBFT-Go/consensus/smartbftprotos"
+	"github.com/golang/protobuf/proto"
+)
+
+// Speculation passes prepared proposals to a SpeculativeApplication,
+// and rolls back the prepared proposal once it is known that it is not going to be delivered.
+// At most one prepared proposal is outstanding, since a proposal is prepared only after the previous one is delivered.
+// The application is invoked in order on a goroutine of its own, so that executing prepared proposals doesn't block
+// the view, and Delivered waits for the pending invocations, so they precede the delivery of the proposal.
+type Speculation struct {
+	Application api.SpeculativeApplication
+	Logger      api.Logger
+
+	lock           sync.Mutex
+	prepared       bool
+	preparedSeq    uint64
+	preparedDigest string
+
+	callsLock sync.Mutex
+	calls     []func()
+	running   bool
+	idle      *sync.Cond
+}
+
+// Prepared passes the proposal prepared with the given sequence to the application,
+// unless it was already passed to it. A different proposal which was prepared before is rolled back.
+func (s *Speculation) Prepared(proposal types.Proposal, seq uint64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	digest := proposal.Digest()
+	if s.prepared && s.preparedSeq == seq && s.preparedDigest == digest {
+		return
+	}
+	s.rollback("another proposal was prepared")
+	s.prepared, s.preparedSeq, s.preparedDigest = true, seq, digest
+	s.invoke(func() {
+		s.Application.Prepared(proposal, seq)
+	})
+}
+
+// Delivered is invoked before the proposal is delivered to the application.
+// If it is the outstanding prepared proposal its execution is confirmed by the delivery,
+// and otherwise the outstanding prepared proposal is rolled back.
+// It returns once the application was invoked on every proposal prepared or rolled back before.
+func (s *Speculation) Delivered(proposal types.Proposal) {
+	s.delivered(proposal)
+	s.wait()
+}
+
+func (s *Speculation) delivered(proposal types.Proposal) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !s.prepared {
+		return
+	}
+	md := &protos.ViewMetadata{}
+	if err := proto.Unmarshal(proposal.Metadata, md); err != nil {
+		s.Logger.Warnf("Failed unmarshaling the metadata of the delivered proposal: %v", err)
+	}
+	if md.LatestSequence == s.preparedSeq && proposal.Digest() == s.preparedDigest {
+		s.prepared = false
+		return
+	}
+	if md.LatestSequence >= s.preparedSeq {
+		s.rollback("another proposal was delivered")
+	}
+}
+
+// Synced is invoked after the node synchronized to the given sequence,
+// and rolls back the outstanding prepared proposal unless its sequence is after it.
+func (s *Speculation) Synced(seq uint64) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.prepared && s.preparedSeq <= seq {
+		s.rollback("the node synchronized past it")
+	}
+}
+
+func (s *Speculation) rollback(reason string) {
+	if !s.prepared {
+		return
+	}
+	s.Logger.Infof("Rolling back the proposal prepared with sequence %d because %s", s.preparedSeq, reason)
+	s.prepared = false
+	seq := s.preparedSeq
+	s.invoke(func() {
+		s.Application.Rollback(seq)
+	})
+}
+
+// invoke queues an invocation of the application, which is made after the invocations queued before it.
+func (s *Speculation) invoke(call func()) {
+	s.callsLock.Lock()
+	defer s.callsLock.Unlock()
+
+	s.calls = append(s.calls, call)
+	if s.running {
+		return
+	}
+	s.running = true
+	go s.invokeAll()
+}
+
+func (s *Speculation) invokeAll() {
+	for {
+		s.callsLock.Lock()
+		if len(s.calls) == 0 {
+			s.running = false
+			s.idleCond().Broadcast()
+			s.callsLock.Unlock()
+			return
+		}
+		call := s.calls[0]
+		s.calls = s.calls[1:]
+		s.callsLock.Unlock()
+		call()
+	}
+}
+
+// wait returns once all queued invocations of the application were made.
+func (s *Speculation) wait() {
+	s.callsLock.Lock()
+	defer s.callsLock.Unlock()
+
+	for s.running {
+		s.idleCond().Wait()
+	}
+}
+
+func (s *Speculation) idleCond() *sync.Cond {
+	if s.idle == nil {
+		s.idle = sync.NewCond(&s.callsLock)
+	}
+	return s.idle
+}
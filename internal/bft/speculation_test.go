@@ -0,0 +1,178 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package bft_test
+
+import (
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/SmartBFT-Go/consensus/internal/bft"
+	"github.com/SmartBFT-Go/consensus/internal/bft/mocks"
+	"github.com/SmartBFT-Go/consensus/pkg/types"
+	protos "github.com/SmartBFT-Go/consensus/smartbftprotos"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func TestSpeculation(t *testing.T) {
+	basicLog, err := zap.NewDevelopment()
+	assert.NoError(t, err)
+
+	proposalOf := func(seq uint64, payload string) types.Proposal {
+		return types.Proposal{
+			Payload:  []byte(payload),
+			Metadata: bft.MarshalOrPanic(&protos.ViewMetadata{LatestSequence: seq, Version: bft.MetadataVersion}),
+		}
+	}
+
+	for _, testCase := range []struct {
+		description string
+		events      func(s *bft.Speculation)
+		rollbacks   []uint64
+	}{
+		{
+			description: "delivery confirms the prepared proposal",
+			events: func(s *bft.Speculation) {
+				s.Prepared(proposalOf(1, "a"), 1)
+				s.Prepared(proposalOf(1, "a"), 1)
+				s.Delivered(proposalOf(1, "a"))
+				s.Synced(1)
+			},
+		},
+		{
+			description: "another proposal is delivered with the same sequence",
+			events: func(s *bft.Speculation) {
+				s.Prepared(proposalOf(1, "a"), 1)
+				s.Delivered(proposalOf(1, "b"))
+				s.Delivered(proposalOf(2, "c"))
+			},
+			rollbacks: []uint64{1},
+		},
+		{
+			description: "another proposal is prepared after a view change",
+			events: func(s *bft.Speculation) {
+				s.Prepared(proposalOf(1, "a"), 1)
+				s.Prepared(proposalOf(1, "b"), 1)
+				s.Delivered(proposalOf(1, "b"))
+			},
+			rollbacks: []uint64{1},
+		},
+		{
+			description: "the node synchronizes past the prepared proposal",
+			events: func(s *bft.Speculation) {
+				s.Prepared(proposalOf(2, "a"), 2)
+				s.Synced(1)
+				s.Synced(3)
+				s.Delivered(proposalOf(2, "a"))
+			},
+			rollbacks: []uint64{2},
+		},
+	} {
+		t.Run(testCase.description, func(t *testing.T) {
+			app := &mocks.SpeculativeApplicationMock{}
+			app.On("Prepared", mock.Anything, mock.Anything)
+			var rollbacks []uint64
+			app.On("Rollback", mock.Anything).Run(func(args mock.Arguments) {
+				rollbacks = append(rollbacks, args.Get(0).(uint64))
+			})
+
+			testCase.events(&bft.Speculation{Application: app, Logger: basicLog.Sugar()})
+			assert.Equal(t, testCase.rollbacks, rollbacks)
+		})
+	}
+}
+
+func TestSpeculationInvokesApplicationAsynchronously(t *testing.T) {
+	basicLog, err := zap.NewDevelopment()
+	assert.NoError(t, err)
+
+	proposal := types.Proposal{
+		Payload:  []byte("a"),
+		Metadata: bft.MarshalOrPanic(&protos.ViewMetadata{LatestSequence: 1, Version: bft.MetadataVersion}),
+	}
+
+	app := &mocks.SpeculativeApplicationMock{}
+	executing := make(chan struct{})
+	release := make(chan struct{})
+	app.On("Prepared", mock.Anything, uint64(1)).Run(func(args mock.Arguments) {
+		close(executing)
+		<-release
+	})
+	s := &bft.Speculation{Application: app, Logger: basicLog.Sugar()}
+
+	// The prepared proposal is executed without blocking
+	s.Prepared(proposal, 1)
+	<-executing
+
+	// Its delivery waits for its execution
+	delivered := make(chan struct{})
+	go func() {
+		s.Delivered(proposal)
+		close(delivered)
+	}()
+	select {
+	case <-delivered:
+		t.Fatal("delivered before the prepared proposal was executed")
+	case <-time.After(100 * time.Millisecond):
+	}
+	close(release)
+	<-delivered
+}
+
+func TestViewPassesPreparedProposal(t *testing.T) {
+	basicLog, err := zap.NewDevelopment()
+	assert.NoError(t, err)
+	log := basicLog.Sugar()
+	comm := &mocks.CommMock{}
+	comm.On("BroadcastConsensus", mock.Anything)
+	decider := &mocks.Decider{}
+	var decided sync.WaitGroup
+	decided.Add(1)
+	decider.On("Decide", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		decided.Done()
+	})
+	verifier := &mocks.VerifierMock{}
+	verifier.On("VerificationSequence").Return(uint64(1))
+	verifier.On("VerifyProposal", mock.Anything).Return(nil, nil)
+	verifier.On("VerifyConsenterSig", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+	verifier.On("VerifySignature", mock.Anything).Return(nil)
+	signer := &mocks.SignerMock{}
+	signer.On("SignProposal", mock.Anything).Return(&types.Signature{Id: 4, Value: []byte{4}})
+	app := &mocks.SpeculativeApplicationMock{}
+	prepared := make(chan types.Proposal, 1)
+	app.On("Prepared", mock.Anything, uint64(0)).Run(func(args mock.Arguments) {
+		prepared <- args.Get(0).(types.Proposal)
+	})
+
+	view := &bft.View{
+		State:            &bft.StateRecorder{},
+		Logger:           log,
+		N:                4,
+		LeaderID:         1,
+		SelfID:           1,
+		Quorum:           3,
+		Number:           1,
+		ProposalSequence: 0,
+		Comm:             comm,
+		Decider:          decider,
+		Verifier:         verifier,
+		Signer:           signer,
+		Speculation:      &bft.Speculation{Application: app, Logger: log},
+	}
+	view.Start()
+	defer view.Abort()
+
+	view.Propose(proposal)
+	view.HandleMessage(2, prepare)
+	view.HandleMessage(3, prepare)
+	assert.Equal(t, proposal, <-prepared)
+
+	view.HandleMessage(2, commit2)
+	view.HandleMessage(3, commit3)
+	decided.Wait()
+}
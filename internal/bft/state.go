@@ -67,6 +67,9 @@ func (ps *PersistedState) storeProposal(proposed *smartbftprotos.ProposedRecord)
 		Metadata:             proposal.Metadata,
 	}
 	ps.InFlightProposal.StoreProposal(proposalToStore)
+	if signature := proposed.Prepare.GetFastPathSignature(); signature != nil {
+		ps.InFlightProposal.StoreSignature(signature)
+	}
 }
 
 func (ps *PersistedState) storePrepared(commitMsg *smartbftprotos.Message) {
@@ -27,6 +27,11 @@ type ApplicationMock interface {
 	api.Application
 }
 
+//go:generate mockery -dir . -name SpeculativeApplicationMock -case underscore -output ./mocks/
+type SpeculativeApplicationMock interface {
+	api.SpeculativeApplication
+}
+
 //go:generate mockery -dir . -name CommMock -case underscore -output ./mocks/
 type CommMock interface {
 	api.Comm
@@ -38,11 +43,36 @@ type SynchronizerMock interface {
 	api.Synchronizer
 }
 
+//go:generate mockery -dir . -name DecisionSynchronizerMock -case underscore -output ./mocks/
+type DecisionSynchronizerMock interface {
+	api.DecisionSynchronizer
+}
+
 //go:generate mockery -dir . -name SignerMock -case underscore -output ./mocks/
 type SignerMock interface {
 	api.Signer
 }
 
+//go:generate mockery -dir . -name MessageRejectionHandlerMock -case underscore -output ./mocks/
+type MessageRejectionHandlerMock interface {
+	api.MessageRejectionHandler
+}
+
+//go:generate mockery -dir . -name RequestTracerMock -case underscore -output ./mocks/
+type RequestTracerMock interface {
+	api.RequestTracer
+}
+
+//go:generate mockery -dir . -name FatalErrorHandlerMock -case underscore -output ./mocks/
+type FatalErrorHandlerMock interface {
+	api.FatalErrorHandler
+}
+
+//go:generate mockery -dir . -name MetadataExtenderMock -case underscore -output ./mocks/
+type MetadataExtenderMock interface {
+	api.MetadataExtender
+}
+
 //go:generate mockery -dir . -name Synchronizer -case underscore -output ./mocks/
 
 type Synchronizer interface {
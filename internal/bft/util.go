@@ -11,11 +11,14 @@ import (
 	"sync/atomic"
 
 	"sync"
+	"time"
 
 	"github.com/SmartBFT-Go/consensus/pkg/api"
+	"github.com/SmartBFT-Go/consensus/pkg/rotation"
 	"github.com/SmartBFT-Go/consensus/pkg/types"
 	protos "github.com/SmartBFT-Go/consensus/smartbftprotos"
 	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
 )
 
 type proposalInfo struct {
@@ -37,6 +40,10 @@ func viewNumber(m *protos.Message) uint64 {
 		return cmt.GetView()
 	}
 
+	if cert := m.GetVoteCertificate(); cert != nil {
+		return cert.GetView()
+	}
+
 	return math.MaxUint64
 }
 
@@ -53,6 +60,10 @@ func proposalSequence(m *protos.Message) uint64 {
 		return cmt.Seq
 	}
 
+	if cert := m.GetVoteCertificate(); cert != nil {
+		return cert.Seq
+	}
+
 	return math.MaxUint64
 }
 
@@ -71,11 +82,47 @@ func MarshalOrPanic(msg proto.Message) []byte {
 	return b
 }
 
-func getLeaderID(view uint64, N uint64, nodes []uint64) uint64 {
-	sort.Slice(nodes, func(i, j int) bool {
-		return nodes[i] < nodes[j]
+// getLeaderID returns the leader of the view according to the rotation,
+// or in a round robin manner if no rotation is set. The nodes must be sorted.
+// Blacklisted nodes are skipped by rotation.Blacklisting, which selects the leader among the other nodes.
+func getLeaderID(view uint64, leaderRotation api.LeaderRotation, nodes []uint64, blacklist []uint64) uint64 {
+	if len(blacklist) == 0 {
+		return rotationLeader(view, leaderRotation, nodes)
+	}
+	blacklisting := &rotation.Blacklisting{
+		Rotation: leaderRotation,
+		Blacklist: func() []uint64 {
+			return blacklist
+		},
+	}
+	return blacklisting.Leader(view, nodes)
+}
+
+func rotationLeader(view uint64, leaderRotation api.LeaderRotation, nodes []uint64) uint64 {
+	if leaderRotation == nil {
+		return rotation.RoundRobin{}.Leader(view, nodes)
+	}
+	return leaderRotation.Leader(view, nodes)
+}
+
+// ViewAfterDecision returns the view which follows the decision with the given metadata.
+// If the leader is rotated after a number of decisions, and it is the last decision in its view,
+// the next view follows it. Otherwise, the view of the decision does.
+func ViewAfterDecision(md *protos.ViewMetadata, decisionsPerLeader uint64) uint64 {
+	if decisionsPerLeader > 0 && md.DecisionsInView+1 >= decisionsPerLeader {
+		return md.ViewId + 1
+	}
+	return md.ViewId
+}
+
+// sortedNodes returns a copy of the node IDs in ascending order.
+func sortedNodes(nodes []uint64) []uint64 {
+	sorted := make([]uint64, len(nodes))
+	copy(sorted, nodes)
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i] < sorted[j]
 	})
-	return nodes[view%N]
+	return sorted
 }
 
 type vote struct {
@@ -122,28 +169,97 @@ type incMsg struct {
 // computeQuorum calculates the quorums size Q, given a cluster size N.
 //
 // The calculation satisfies the following:
-// Given a cluster size of N nodes, which tolerates f failures according to:
+// Given a cluster size of N nodes, which tolerates f Byzantine failures according to:
 //    f = argmax ( N >= 3f+1 )
 // Q is the size of the quorum such that:
 //    any two subsets q1, q2 of size Q, intersect in at least f+1 nodes.
 //
 // Note that this is different from N-f (the number of correct nodes), when N=3f+3. That is, we have two extra nodes
 // above the minimum required to tolerate f failures.
-func computeQuorum(N uint64) (Q int, F int) {
+//
+// If the cluster tolerates only crash failures, f = argmax ( N >= 2f+1 ),
+// and Q is a majority of the nodes, such that any two subsets of size Q intersect.
+func computeQuorum(N uint64, model types.FaultModel) (Q int, F int) {
+	if model == types.CrashFaults {
+		F = int((int(N) - 1) / 2)
+		Q = int(N)/2 + 1
+		return
+	}
 	F = int((int(N) - 1) / 3)
 	Q = int(math.Ceil((float64(N) + float64(F) + 1) / 2.0))
 	return
 }
 
+// computeWeightedQuorum calculates the quorum Q and the number of tolerated failures F
+// as weights, if the nodes have voting weights. Otherwise, they are the same as computeQuorum's.
+func computeWeightedQuorum(N uint64, weights map[uint64]uint64, model types.FaultModel) (Q int, F int) {
+	if len(weights) == 0 {
+		return computeQuorum(N, model)
+	}
+	q, f := types.WeightedQuorum(weights, model)
+	return int(q), int(f)
+}
+
+// votingWeight returns the sum of the voting weights of the nodes,
+// where every node weighs one if no weights are set.
+func votingWeight(weights map[uint64]uint64, nodes ...uint64) int {
+	if len(weights) == 0 {
+		return len(nodes)
+	}
+	total := 0
+	for _, node := range nodes {
+		total += int(weights[node])
+	}
+	return total
+}
+
+func (vs *voteSet) weight(weights map[uint64]uint64) int {
+	if len(weights) == 0 {
+		return len(vs.voted)
+	}
+	total := 0
+	for voter := range vs.voted {
+		total += int(weights[voter])
+	}
+	return total
+}
+
+// trustThreshold returns the number of nodes which contains at least one correct node.
+func trustThreshold(F int, model types.FaultModel) int {
+	if model == types.CrashFaults {
+		return 1
+	}
+	return F + 1
+}
+
+// consenterSigVerifier returns a verifier which doesn't verify the signatures on commits
+// if the cluster tolerates only crash failures, as commits are not signed.
+func consenterSigVerifier(verifier api.Verifier, model types.FaultModel) api.Verifier {
+	if model == types.CrashFaults {
+		return &unsignedCommitsVerifier{Verifier: verifier}
+	}
+	return verifier
+}
+
+type unsignedCommitsVerifier struct {
+	api.Verifier
+}
+
+func (*unsignedCommitsVerifier) VerifyConsenterSig(types.Signature, types.Proposal) error {
+	return nil
+}
+
 // InFlightData records proposals that are in-flight,
 // as well as their corresponding prepares.
 type InFlightData struct {
-	v atomic.Value
+	v       atomic.Value
+	adopted atomic.Value
 }
 
 type inFlightProposalData struct {
-	proposal *types.Proposal
-	prepared bool
+	proposal  *types.Proposal
+	prepared  bool
+	signature *protos.Signature
 }
 
 // InFlightData returns an in-flight proposal or nil if there is no such.
@@ -173,25 +289,94 @@ func (ifp *InFlightData) StoreProposal(prop types.Proposal) {
 }
 
 func (ifp *InFlightData) StorePrepares(view, seq uint64) {
-	prop := ifp.InFlightProposal()
-	if prop == nil {
+	fetched := ifp.v.Load()
+	if fetched == nil {
 		panic("stored prepares but proposal is not initialized")
 	}
-	p := prop
-	ifp.v.Store(inFlightProposalData{proposal: p, prepared: true})
+	data := fetched.(inFlightProposalData)
+	data.prepared = true
+	ifp.v.Store(data)
+}
+
+// StoreSignature stores the signature on the in-flight proposal we sent in a prepare on the fast path.
+func (ifp *InFlightData) StoreSignature(signature *protos.Signature) {
+	fetched := ifp.v.Load()
+	if fetched == nil {
+		panic("stored signature but proposal is not initialized")
+	}
+	data := fetched.(inFlightProposalData)
+	data.signature = signature
+	ifp.v.Store(data)
+}
+
+// InFlightSignature returns the signature on the in-flight proposal we sent on the fast path, or nil if there is none.
+func (ifp *InFlightData) InFlightSignature() *protos.Signature {
+	fetched := ifp.v.Load()
+	if fetched == nil {
+		return nil
+	}
+	return fetched.(inFlightProposalData).signature
+}
+
+// StoreAdopted stores the in-flight proposal a new view adopted, or nil if it adopted none.
+func (ifp *InFlightData) StoreAdopted(prop *types.Proposal) {
+	ifp.adopted.Store(adoptedProposal{proposal: prop})
+}
+
+// Adopted returns the proposal with the given sequence that the last new view adopted, or nil if there is none.
+// An adopted proposal might have been committed on the fast path, so it is the only proposal allowed with its sequence.
+func (ifp *InFlightData) Adopted(seq uint64) *types.Proposal {
+	fetched := ifp.adopted.Load()
+	if fetched == nil {
+		return nil
+	}
+	prop := fetched.(adoptedProposal).proposal
+	if prop == nil {
+		return nil
+	}
+	md := &protos.ViewMetadata{}
+	if err := proto.Unmarshal(prop.Metadata, md); err != nil || md.LatestSequence != seq {
+		return nil
+	}
+	return prop
+}
+
+type adoptedProposal struct {
+	proposal *types.Proposal
 }
 
 type ProposalMaker struct {
-	N               uint64
-	SelfID          uint64
-	Decider         Decider
-	FailureDetector FailureDetector
-	Sync            Synchronizer
-	Logger          api.Logger
-	Comm            Comm
-	Verifier        api.Verifier
-	Signer          api.Signer
-	State           State
+	N                uint64
+	SelfID           uint64
+	Decider          Decider
+	FailureDetector  FailureDetector
+	Sync             Synchronizer
+	Logger           api.Logger
+	Comm             Comm
+	Verifier         api.Verifier
+	Signer           api.Signer
+	State            State
+	FlowControl      FlowControl
+	RejectionHandler api.MessageRejectionHandler
+	Halter           Halter
+	// If positive, the leader is rotated after this number of decisions in a view
+	DecisionsPerLeader uint64
+	// If set, leaders deposed by view changes are blacklisted
+	LeaderBlacklisting bool
+	LeaderRotation     api.LeaderRotation
+	Checkpoint         *types.Checkpoint
+	MetadataExtender   api.MetadataExtender
+	TimestampSkew      time.Duration
+	FaultModel         types.FaultModel
+	Weights            map[uint64]uint64
+	ProtocolVersions   *ProtocolVersions
+	Speculation        *Speculation
+	FastPathTimeout    time.Duration
+	InFlight           *InFlightData
+	CollectorTimeout   time.Duration
+	Collector          uint64
+	Dissemination      *Dissemination
+	VerificationPool   *VerificationPool
 
 	restoreOnceFromWAL sync.Once
 }
@@ -212,12 +397,32 @@ func (pm *ProposalMaker) NewProposer(leader, proposalSequence, viewNum uint64, q
 		Signer:           pm.Signer,
 		ProposalSequence: proposalSequence,
 		State:            pm.State,
+		FlowControl:      pm.FlowControl,
+		RejectionHandler: pm.RejectionHandler,
+		Halter:           pm.Halter,
+
+		DecisionsPerLeader: pm.DecisionsPerLeader,
+		LeaderBlacklisting: pm.LeaderBlacklisting,
+		LeaderRotation:     pm.LeaderRotation,
+		Checkpoint:         pm.Checkpoint,
+		MetadataExtender:   pm.MetadataExtender,
+		TimestampSkew:      pm.TimestampSkew,
+		FaultModel:         pm.FaultModel,
+		Weights:            pm.Weights,
+		ProtocolVersions:   pm.ProtocolVersions,
+		Speculation:        pm.Speculation,
+		FastPathTimeout:    pm.FastPathTimeout,
+		InFlight:           pm.InFlight,
+		CollectorTimeout:   pm.CollectorTimeout,
+		Collector:          pm.Collector,
+		Dissemination:      pm.Dissemination,
+		VerificationPool:   pm.VerificationPool,
 	}
 
 	pm.restoreOnceFromWAL.Do(func() {
 		err := pm.State.Restore(view)
 		if err != nil {
-			pm.Logger.Panicf("Failed restoring view from WAL: %v", err)
+			haltOrPanic(pm.Halter, pm.Logger, errors.Wrap(err, "failed restoring view from WAL"))
 		}
 	})
 
@@ -229,5 +434,29 @@ func (pm *ProposalMaker) NewProposer(leader, proposalSequence, viewNum uint64, q
 		view.Number = viewNum
 	}
 
+	view.Logger = WithFields(pm.Logger, "view", view.Number)
+
+	if pm.DecisionsPerLeader > 0 {
+		view.DecisionsInView = pm.decisionsInView(view.Number)
+	}
+
 	return view
 }
+
+// decisionsInView returns the number of decisions made in the given view,
+// according to the last decision.
+func (pm *ProposalMaker) decisionsInView(view uint64) uint64 {
+	lastDecision, _ := pm.Checkpoint.Get()
+	if lastDecision.Metadata == nil {
+		return 0
+	}
+	md := &protos.ViewMetadata{}
+	if err := proto.Unmarshal(lastDecision.Metadata, md); err != nil {
+		pm.Logger.Warnf("Failed unmarshaling the metadata of the last decision: %v", err)
+		return 0
+	}
+	if md.ViewId != view {
+		return 0
+	}
+	return md.DecisionsInView + 1
+}
@@ -10,6 +10,7 @@ import (
 	"testing"
 
 	"github.com/SmartBFT-Go/consensus/pkg/types"
+	protos "github.com/SmartBFT-Go/consensus/smartbftprotos"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -28,6 +29,23 @@ func TestInFlightProposalLatest(t *testing.T) {
 	assert.Equal(t, prop, *ifp.InFlightProposal())
 }
 
+func TestInFlightAdopted(t *testing.T) {
+	prop := types.Proposal{
+		Metadata: MarshalOrPanic(&protos.ViewMetadata{LatestSequence: 5}),
+		Payload:  []byte{1},
+	}
+
+	ifp := &InFlightData{}
+	assert.Nil(t, ifp.Adopted(5))
+
+	ifp.StoreAdopted(&prop)
+	assert.Equal(t, prop, *ifp.Adopted(5))
+	assert.Nil(t, ifp.Adopted(6))
+
+	ifp.StoreAdopted(nil)
+	assert.Nil(t, ifp.Adopted(5))
+}
+
 func TestQuorum(t *testing.T) {
 	// Ensure that quorum size is as expected.
 
@@ -42,10 +60,28 @@ func TestQuorum(t *testing.T) {
 
 	for _, testCase := range quorums {
 		t.Run(fmt.Sprintf("%d nodes", testCase.N), func(t *testing.T) {
-			Q, F := computeQuorum(testCase.N)
+			Q, F := computeQuorum(testCase.N, types.ByzantineFaults)
 			assert.Equal(t, testCase.Q, Q)
 			assert.Equal(t, testCase.F, F)
 		})
 	}
 
 }
+
+func TestCrashFaultQuorum(t *testing.T) {
+	type quorum struct {
+		N uint64
+		F int
+		Q int
+	}
+
+	quorums := []quorum{{1, 0, 1}, {2, 0, 2}, {3, 1, 2}, {4, 1, 3}, {5, 2, 3}, {6, 2, 4}, {7, 3, 4}}
+
+	for _, testCase := range quorums {
+		t.Run(fmt.Sprintf("%d nodes", testCase.N), func(t *testing.T) {
+			Q, F := computeQuorum(testCase.N, types.CrashFaults)
+			assert.Equal(t, testCase.Q, Q)
+			assert.Equal(t, testCase.F, F)
+		})
+	}
+}
@@ -0,0 +1,283 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package bft
+
+import (
+	protos "github.com/SmartBFT-Go/consensus/smartbftprotos"
+	"github.com/pkg/errors"
+)
+
+// MessageLimits bounds the sizes of fields of incoming messages.
+// A zero limit means the field is unbounded.
+type MessageLimits struct {
+	MaxProposalSize  int
+	MaxViewDataSize  int
+	MaxSignatureSize int
+}
+
+// MessageValidator checks incoming messages structurally,
+// before they are dispatched to the view, the view changer or the leader monitor.
+type MessageValidator struct {
+	n      uint64
+	nodes  map[uint64]struct{}
+	limits MessageLimits
+}
+
+// NewMessageValidator constructs a validator for messages sent by the given nodes.
+func NewMessageValidator(nodes []uint64, limits MessageLimits) *MessageValidator {
+	mv := &MessageValidator{
+		n:      uint64(len(nodes)),
+		nodes:  make(map[uint64]struct{}, len(nodes)),
+		limits: limits,
+	}
+	for _, node := range nodes {
+		mv.nodes[node] = struct{}{}
+	}
+	return mv
+}
+
+// ValidateMessage returns an error if the message sent by the sender is malformed,
+// or if it refers to a view or a sequence older than the given current view and sequence.
+func (mv *MessageValidator) ValidateMessage(sender uint64, m *protos.Message, currView uint64, currSeq uint64) error {
+	if !mv.isNode(sender) {
+		return errors.Errorf("sender %d is not a node", sender)
+	}
+
+	switch m.GetContent().(type) {
+	case *protos.Message_PrePrepare:
+		return mv.validatePrePrepare(m.GetPrePrepare(), currView, currSeq)
+	case *protos.Message_Prepare:
+		return mv.validatePrepare(m.GetPrepare(), currView, currSeq)
+	case *protos.Message_Commit:
+		return mv.validateCommit(sender, m.GetCommit(), currView, currSeq)
+	case *protos.Message_VoteCertificate:
+		return mv.validateVoteCertificate(m.GetVoteCertificate(), currView, currSeq)
+	case *protos.Message_PrePrepareChunk:
+		return mv.validatePrePrepareChunk(m.GetPrePrepareChunk(), currView, currSeq)
+	case *protos.Message_FetchRequests:
+		if len(m.GetFetchRequests().GetRequestIds()) == 0 {
+			return errors.New("fetch requests has no request IDs")
+		}
+		return nil
+	case *protos.Message_FetchedRequests:
+		return mv.validateFetchedRequests(m.GetFetchedRequests())
+	case *protos.Message_ViewChange:
+		return mv.validateViewChange(m.GetViewChange(), currView)
+	case *protos.Message_ViewData:
+		return mv.validateViewData(sender, m.GetViewData())
+	case *protos.Message_NewView:
+		return mv.validateNewView(m.GetNewView())
+	case *protos.Message_HeartBeat:
+		if m.GetHeartBeat() == nil {
+			return errors.New("empty heartbeat")
+		}
+		return nil
+	case *protos.Message_Error:
+		if m.GetError() == nil {
+			return errors.New("empty error message")
+		}
+		return nil
+	default:
+		return errors.New("unknown message type")
+	}
+}
+
+func (mv *MessageValidator) validatePrePrepare(pp *protos.PrePrepare, currView uint64, currSeq uint64) error {
+	if pp == nil {
+		return errors.New("empty pre-prepare")
+	}
+	if pp.Proposal == nil {
+		return errors.New("pre-prepare has no proposal")
+	}
+	if err := checkSize("proposal", proposalSize(pp.Proposal), mv.limits.MaxProposalSize); err != nil {
+		return err
+	}
+	return checkViewAndSeq(pp.View, pp.Seq, currView, currSeq)
+}
+
+func (mv *MessageValidator) validatePrepare(prp *protos.Prepare, currView uint64, currSeq uint64) error {
+	if prp == nil {
+		return errors.New("empty prepare")
+	}
+	if prp.Digest == "" {
+		return errors.New("prepare has no digest")
+	}
+	if err := checkSize("prepare signature", len(prp.Signature), mv.limits.MaxSignatureSize); err != nil {
+		return err
+	}
+	return checkViewAndSeq(prp.View, prp.Seq, currView, currSeq)
+}
+
+func (mv *MessageValidator) validateCommit(sender uint64, cmt *protos.Commit, currView uint64, currSeq uint64) error {
+	if cmt == nil {
+		return errors.New("empty commit")
+	}
+	if cmt.Digest == "" {
+		return errors.New("commit has no digest")
+	}
+	if cmt.Signature == nil {
+		return errors.New("commit has no signature")
+	}
+	if cmt.Signature.Signer != sender {
+		return errors.Errorf("commit signer %d is not the sender", cmt.Signature.Signer)
+	}
+	if err := checkSize("commit signature", len(cmt.Signature.Value)+len(cmt.Signature.Msg), mv.limits.MaxSignatureSize); err != nil {
+		return err
+	}
+	return checkViewAndSeq(cmt.View, cmt.Seq, currView, currSeq)
+}
+
+func (mv *MessageValidator) validateVoteCertificate(cert *protos.VoteCertificate, currView uint64, currSeq uint64) error {
+	if cert == nil {
+		return errors.New("empty vote certificate")
+	}
+	if cert.Digest == "" {
+		return errors.New("vote certificate has no digest")
+	}
+	for _, votes := range []struct {
+		kind       string
+		signatures []*protos.Signature
+	}{
+		{kind: "prepare", signatures: cert.Prepares},
+		{kind: "commit", signatures: cert.Commits},
+	} {
+		if uint64(len(votes.signatures)) > mv.n {
+			return errors.Errorf("vote certificate has %d %ss but there are only %d nodes", len(votes.signatures), votes.kind, mv.n)
+		}
+		signers := make(map[uint64]struct{}, len(votes.signatures))
+		for _, sig := range votes.signatures {
+			if sig == nil {
+				return errors.Errorf("vote certificate has an empty %s", votes.kind)
+			}
+			if !mv.isNode(sig.Signer) {
+				return errors.Errorf("%s signer %d is not a node", votes.kind, sig.Signer)
+			}
+			if _, exists := signers[sig.Signer]; exists {
+				return errors.Errorf("vote certificate has two %ss of %d", votes.kind, sig.Signer)
+			}
+			signers[sig.Signer] = struct{}{}
+			if err := checkSize(votes.kind+" signature", len(sig.Value)+len(sig.Msg), mv.limits.MaxSignatureSize); err != nil {
+				return err
+			}
+		}
+	}
+	return checkViewAndSeq(cert.View, cert.Seq, currView, currSeq)
+}
+
+func (mv *MessageValidator) validatePrePrepareChunk(chunk *protos.PrePrepareChunk, currView uint64, currSeq uint64) error {
+	if chunk == nil {
+		return errors.New("empty pre-prepare chunk")
+	}
+	if !mv.isNode(chunk.Leader) {
+		return errors.Errorf("pre-prepare chunk leader %d is not a node", chunk.Leader)
+	}
+	if uint64(chunk.Index) >= mv.n {
+		return errors.Errorf("pre-prepare chunk index %d is out of the %d nodes", chunk.Index, mv.n)
+	}
+	if len(chunk.Shard) == 0 {
+		return errors.New("pre-prepare chunk has no shard")
+	}
+	if uint64(len(chunk.ShardHashes)) > mv.n {
+		return errors.Errorf("pre-prepare chunk has %d shard hashes but there are only %d nodes", len(chunk.ShardHashes), mv.n)
+	}
+	if err := checkSize("pre-prepare chunk", len(chunk.Shard), mv.limits.MaxProposalSize); err != nil {
+		return err
+	}
+	return checkViewAndSeq(chunk.View, chunk.Seq, currView, currSeq)
+}
+
+func (mv *MessageValidator) validateFetchedRequests(fetched *protos.FetchedRequests) error {
+	if len(fetched.GetRequests()) == 0 {
+		return errors.New("fetched requests has no requests")
+	}
+	var size int
+	for _, request := range fetched.Requests {
+		size += len(request)
+	}
+	return checkSize("fetched requests", size, mv.limits.MaxProposalSize)
+}
+
+func (mv *MessageValidator) validateViewChange(vc *protos.ViewChange, currView uint64) error {
+	if vc == nil {
+		return errors.New("empty view change")
+	}
+	if vc.NextView <= currView {
+		return errors.Errorf("view change to view %d but current view is %d", vc.NextView, currView)
+	}
+	return nil
+}
+
+func (mv *MessageValidator) validateViewData(sender uint64, svd *protos.SignedViewData) error {
+	if err := mv.validateSignedViewData(svd); err != nil {
+		return err
+	}
+	if svd.Signer != sender {
+		return errors.Errorf("view data signer %d is not the sender", svd.Signer)
+	}
+	return nil
+}
+
+func (mv *MessageValidator) validateNewView(nv *protos.NewView) error {
+	if nv == nil {
+		return errors.New("empty new view")
+	}
+	if len(nv.SignedViewData) == 0 {
+		return errors.New("new view has no view data")
+	}
+	if uint64(len(nv.SignedViewData)) > mv.n {
+		return errors.Errorf("new view has %d view data but there are only %d nodes", len(nv.SignedViewData), mv.n)
+	}
+	for _, svd := range nv.SignedViewData {
+		if err := mv.validateSignedViewData(svd); err != nil {
+			return errors.Wrap(err, "new view contains invalid view data")
+		}
+	}
+	return nil
+}
+
+func (mv *MessageValidator) validateSignedViewData(svd *protos.SignedViewData) error {
+	if svd == nil {
+		return errors.New("empty view data")
+	}
+	if !mv.isNode(svd.Signer) {
+		return errors.Errorf("view data signer %d is not a node", svd.Signer)
+	}
+	if len(svd.RawViewData) == 0 {
+		return errors.New("view data is empty")
+	}
+	if err := checkSize("view data", len(svd.RawViewData), mv.limits.MaxViewDataSize); err != nil {
+		return err
+	}
+	return checkSize("view data signature", len(svd.Signature), mv.limits.MaxSignatureSize)
+}
+
+func (mv *MessageValidator) isNode(id uint64) bool {
+	_, exists := mv.nodes[id]
+	return exists
+}
+
+// checkViewAndSeq rejects messages of past views, and of sequences older than the previous sequence,
+// which is still served to help lagging nodes catch up.
+func checkViewAndSeq(view, seq, currView, currSeq uint64) error {
+	if view < currView {
+		return errors.Errorf("view %d is older than current view %d", view, currView)
+	}
+	if view == currView && seq+1 < currSeq {
+		return errors.Errorf("sequence %d is older than current sequence %d", seq, currSeq)
+	}
+	return nil
+}
+
+func checkSize(field string, size int, limit int) error {
+	if limit > 0 && size > limit {
+		return errors.Errorf("%s size is %d but the limit is %d", field, size, limit)
+	}
+	return nil
+}
+
+func proposalSize(p *protos.Proposal) int {
+	return len(p.Header) + len(p.Payload) + len(p.Metadata)
+}
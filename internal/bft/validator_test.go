@@ -0,0 +1,392 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package bft_test
+
+import (
+	"testing"
+
+	"github.com/SmartBFT-Go/consensus/internal/bft"
+	"github.com/SmartBFT-Go/consensus/internal/bft/mocks"
+	protos "github.com/SmartBFT-Go/consensus/smartbftprotos"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func TestValidateMessage(t *testing.T) {
+	validator := bft.NewMessageValidator([]uint64{0, 1, 2, 3}, bft.MessageLimits{
+		MaxProposalSize:  100,
+		MaxViewDataSize:  1000,
+		MaxSignatureSize: 10,
+	})
+
+	withPrePrepare := func(f func(pp *protos.PrePrepare)) *protos.Message {
+		m := proto.Clone(prePrepare).(*protos.Message)
+		f(m.GetPrePrepare())
+		return m
+	}
+	withCommit := func(f func(c *protos.Commit)) *protos.Message {
+		m := proto.Clone(commit1).(*protos.Message)
+		f(m.GetCommit())
+		return m
+	}
+	voteCertificate := func(prepares ...*protos.Signature) *protos.Message {
+		return &protos.Message{
+			Content: &protos.Message_VoteCertificate{
+				VoteCertificate: &protos.VoteCertificate{View: 1, Digest: digest, Prepares: prepares},
+			},
+		}
+	}
+	chunk := func(f func(c *protos.PrePrepareChunk)) *protos.Message {
+		c := &protos.PrePrepareChunk{View: 1, Seq: 5, Leader: 1, Index: 2, Shard: []byte{1}}
+		f(c)
+		return &protos.Message{Content: &protos.Message_PrePrepareChunk{PrePrepareChunk: c}}
+	}
+	newView := func(svd ...*protos.SignedViewData) *protos.Message {
+		return &protos.Message{
+			Content: &protos.Message_NewView{
+				NewView: &protos.NewView{SignedViewData: svd},
+			},
+		}
+	}
+
+	for _, testCase := range []struct {
+		description string
+		sender      uint64
+		msg         *protos.Message
+		currView    uint64
+		currSeq     uint64
+		expectedErr string
+	}{
+		{
+			description: "valid pre-prepare",
+			sender:      1,
+			msg:         prePrepare,
+			currView:    1,
+		},
+		{
+			description: "valid prepare",
+			sender:      2,
+			msg:         prepare,
+			currView:    1,
+		},
+		{
+			description: "valid commit",
+			sender:      1,
+			msg:         commit1,
+			currView:    1,
+		},
+		{
+			description: "valid commit of previous sequence",
+			sender:      1,
+			msg:         commit1,
+			currView:    1,
+			currSeq:     1,
+		},
+		{
+			description: "valid commit of future view",
+			sender:      1,
+			msg:         commit1,
+			currView:    0,
+			currSeq:     10,
+		},
+		{
+			description: "valid vote certificate",
+			sender:      1,
+			msg:         voteCertificate(&protos.Signature{Signer: 1}, &protos.Signature{Signer: 2}),
+			currView:    1,
+		},
+		{
+			description: "valid view change",
+			sender:      3,
+			msg:         viewChangeMsg,
+		},
+		{
+			description: "valid view data",
+			sender:      0,
+			msg:         viewDataMsg1,
+		},
+		{
+			description: "valid new view",
+			sender:      1,
+			msg:         newView(viewDataMsg1.GetViewData()),
+		},
+		{
+			description: "valid heartbeat",
+			sender:      1,
+			msg:         heartbeat,
+		},
+		{
+			description: "sender is not a node",
+			sender:      4,
+			msg:         heartbeat,
+			expectedErr: "sender 4 is not a node",
+		},
+		{
+			description: "empty message",
+			sender:      1,
+			msg:         &protos.Message{},
+			expectedErr: "unknown message type",
+		},
+		{
+			description: "empty pre-prepare",
+			sender:      1,
+			msg:         &protos.Message{Content: &protos.Message_PrePrepare{}},
+			expectedErr: "empty pre-prepare",
+		},
+		{
+			description: "pre-prepare without proposal",
+			sender:      1,
+			msg: withPrePrepare(func(pp *protos.PrePrepare) {
+				pp.Proposal = nil
+			}),
+			currView:    1,
+			expectedErr: "pre-prepare has no proposal",
+		},
+		{
+			description: "pre-prepare with oversized proposal",
+			sender:      1,
+			msg: withPrePrepare(func(pp *protos.PrePrepare) {
+				pp.Proposal.Payload = make([]byte, 100)
+			}),
+			currView:    1,
+			expectedErr: "proposal size is 105 but the limit is 100",
+		},
+		{
+			description: "pre-prepare of past view",
+			sender:      1,
+			msg:         prePrepare,
+			currView:    2,
+			expectedErr: "view 1 is older than current view 2",
+		},
+		{
+			description: "pre-prepare of past sequence",
+			sender:      1,
+			msg:         prePrepare,
+			currView:    1,
+			currSeq:     2,
+			expectedErr: "sequence 0 is older than current sequence 2",
+		},
+		{
+			description: "prepare without digest",
+			sender:      2,
+			msg:         &protos.Message{Content: &protos.Message_Prepare{Prepare: &protos.Prepare{View: 1}}},
+			currView:    1,
+			expectedErr: "prepare has no digest",
+		},
+		{
+			description: "commit without signature",
+			sender:      1,
+			msg: withCommit(func(c *protos.Commit) {
+				c.Signature = nil
+			}),
+			currView:    1,
+			expectedErr: "commit has no signature",
+		},
+		{
+			description: "commit signed by another node",
+			sender:      2,
+			msg:         commit1,
+			currView:    1,
+			expectedErr: "commit signer 1 is not the sender",
+		},
+		{
+			description: "commit with oversized signature",
+			sender:      1,
+			msg: withCommit(func(c *protos.Commit) {
+				c.Signature.Value = make([]byte, 11)
+			}),
+			currView:    1,
+			expectedErr: "commit signature size is 11 but the limit is 10",
+		},
+		{
+			description: "vote certificate without digest",
+			sender:      1,
+			msg:         &protos.Message{Content: &protos.Message_VoteCertificate{VoteCertificate: &protos.VoteCertificate{View: 1}}},
+			currView:    1,
+			expectedErr: "vote certificate has no digest",
+		},
+		{
+			description: "vote certificate with two votes of a node",
+			sender:      1,
+			msg:         voteCertificate(&protos.Signature{Signer: 2}, &protos.Signature{Signer: 2}),
+			currView:    1,
+			expectedErr: "vote certificate has two prepares of 2",
+		},
+		{
+			description: "vote certificate with vote of unknown signer",
+			sender:      1,
+			msg:         voteCertificate(&protos.Signature{Signer: 7}),
+			currView:    1,
+			expectedErr: "prepare signer 7 is not a node",
+		},
+		{
+			description: "vote certificate with oversized signature",
+			sender:      1,
+			msg:         voteCertificate(&protos.Signature{Signer: 2, Value: make([]byte, 11)}),
+			currView:    1,
+			expectedErr: "prepare signature size is 11 but the limit is 10",
+		},
+		{
+			description: "pre-prepare chunk",
+			sender:      1,
+			msg:         chunk(func(c *protos.PrePrepareChunk) {}),
+			currView:    1,
+			currSeq:     5,
+		},
+		{
+			description: "pre-prepare chunk of unknown leader",
+			sender:      1,
+			msg:         chunk(func(c *protos.PrePrepareChunk) { c.Leader = 7 }),
+			currView:    1,
+			expectedErr: "pre-prepare chunk leader 7 is not a node",
+		},
+		{
+			description: "pre-prepare chunk of unknown index",
+			sender:      1,
+			msg:         chunk(func(c *protos.PrePrepareChunk) { c.Index = 4 }),
+			currView:    1,
+			expectedErr: "pre-prepare chunk index 4 is out of the 4 nodes",
+		},
+		{
+			description: "pre-prepare chunk without shard",
+			sender:      1,
+			msg:         chunk(func(c *protos.PrePrepareChunk) { c.Shard = nil }),
+			currView:    1,
+			expectedErr: "pre-prepare chunk has no shard",
+		},
+		{
+			description: "pre-prepare chunk with too many shard hashes",
+			sender:      1,
+			msg:         chunk(func(c *protos.PrePrepareChunk) { c.ShardHashes = make([][]byte, 5) }),
+			currView:    1,
+			expectedErr: "pre-prepare chunk has 5 shard hashes but there are only 4 nodes",
+		},
+		{
+			description: "oversized pre-prepare chunk",
+			sender:      1,
+			msg:         chunk(func(c *protos.PrePrepareChunk) { c.Shard = make([]byte, 101) }),
+			currView:    1,
+			expectedErr: "pre-prepare chunk size is 101 but the limit is 100",
+		},
+		{
+			description: "pre-prepare chunk of past view",
+			sender:      1,
+			msg:         chunk(func(c *protos.PrePrepareChunk) {}),
+			currView:    2,
+			expectedErr: "view 1 is older than current view 2",
+		},
+		{
+			description: "fetch requests without request IDs",
+			sender:      1,
+			msg:         &protos.Message{Content: &protos.Message_FetchRequests{FetchRequests: &protos.FetchRequests{}}},
+			expectedErr: "fetch requests has no request IDs",
+		},
+		{
+			description: "oversized fetched requests",
+			sender:      1,
+			msg: &protos.Message{Content: &protos.Message_FetchedRequests{
+				FetchedRequests: &protos.FetchedRequests{Requests: [][]byte{make([]byte, 60), make([]byte, 60)}},
+			}},
+			expectedErr: "fetched requests size is 120 but the limit is 100",
+		},
+		{
+			description: "view change to past view",
+			sender:      3,
+			msg:         viewChangeMsg,
+			currView:    1,
+			expectedErr: "view change to view 1 but current view is 1",
+		},
+		{
+			description: "view data signed by another node",
+			sender:      1,
+			msg:         viewDataMsg1,
+			expectedErr: "view data signer 0 is not the sender",
+		},
+		{
+			description: "view data without content",
+			sender:      1,
+			msg:         &protos.Message{Content: &protos.Message_ViewData{ViewData: &protos.SignedViewData{Signer: 1}}},
+			expectedErr: "view data is empty",
+		},
+		{
+			description: "new view without view data",
+			sender:      1,
+			msg:         newView(),
+			expectedErr: "new view has no view data",
+		},
+		{
+			description: "new view with too many view data",
+			sender:      1,
+			msg: newView(viewDataMsg1.GetViewData(), viewDataMsg1.GetViewData(), viewDataMsg1.GetViewData(),
+				viewDataMsg1.GetViewData(), viewDataMsg1.GetViewData()),
+			expectedErr: "new view has 5 view data but there are only 4 nodes",
+		},
+		{
+			description: "new view with view data of unknown signer",
+			sender:      1,
+			msg:         newView(&protos.SignedViewData{Signer: 7, RawViewData: vdBytes}),
+			expectedErr: "new view contains invalid view data: view data signer 7 is not a node",
+		},
+		{
+			description: "new view with oversized view data",
+			sender:      1,
+			msg:         newView(&protos.SignedViewData{Signer: 1, RawViewData: make([]byte, 1001)}),
+			expectedErr: "new view contains invalid view data: view data size is 1001 but the limit is 1000",
+		},
+	} {
+		t.Run(testCase.description, func(t *testing.T) {
+			err := validator.ValidateMessage(testCase.sender, testCase.msg, testCase.currView, testCase.currSeq)
+			if testCase.expectedErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			assert.EqualError(t, err, testCase.expectedErr)
+		})
+	}
+}
+
+func TestControllerRejectsMalformedMessages(t *testing.T) {
+	basicLog, err := zap.NewDevelopment()
+	assert.NoError(t, err)
+	log := basicLog.Sugar()
+	batcher := &mocks.Batcher{}
+	batcher.On("Close")
+	pool := &mocks.RequestPool{}
+	pool.On("Close")
+	leaderMon := &mocks.LeaderMonitor{}
+	leaderMon.On("ChangeRole", mock.Anything, mock.Anything, mock.Anything)
+	leaderMon.On("Close")
+	comm := &mocks.CommMock{}
+	comm.On("Nodes").Return([]uint64{0, 1, 2, 3})
+	rejectionHandler := &mocks.MessageRejectionHandlerMock{}
+	rejectionHandler.On("OnMessageRejected", mock.Anything, mock.Anything, mock.Anything)
+
+	controller := &bft.Controller{
+		Batcher:          batcher,
+		RequestPool:      pool,
+		LeaderMonitor:    leaderMon,
+		ID:               2, // not the leader
+		N:                4,
+		Logger:           log,
+		Comm:             comm,
+		RejectionHandler: rejectionHandler,
+	}
+	configureProposerBuilder(controller)
+
+	controller.Start(1, 0)
+
+	emptyHeartbeat := &protos.Message{Content: &protos.Message_HeartBeat{}}
+	controller.ProcessMessages(1, emptyHeartbeat)
+	controller.ProcessMessages(5, heartbeat)
+	controller.Stop()
+
+	leaderMon.AssertNotCalled(t, "ProcessMsg", mock.Anything, mock.Anything)
+	rejectionHandler.AssertNumberOfCalls(t, "OnMessageRejected", 2)
+	rejectionHandler.AssertCalled(t, "OnMessageRejected", uint64(1), emptyHeartbeat, mock.Anything)
+	rejectionHandler.AssertCalled(t, "OnMessageRejected", uint64(5), heartbeat, mock.Anything)
+}
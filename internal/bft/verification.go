@@ -0,0 +1,202 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package bft
+
+import (
+	"runtime"
+	"sync"
+	"sync/atomic"
+
+	"github.com/SmartBFT-Go/consensus/pkg/api"
+	"github.com/SmartBFT-Go/consensus/pkg/types"
+	"github.com/pkg/errors"
+)
+
+const DefaultVerificationQueueSize = 1000
+
+// VerificationPool verifies signatures and requests by a fixed number of workers, which are shared by the node.
+// Tasks submitted while the queue is full wait for room in it, so verification slows down its callers
+// instead of spawning goroutines without bound.
+// Tasks never block, as a worker blocked on the progress of the node could deadlock it.
+type VerificationPool struct {
+	workers int
+	tasks   chan func()
+	running sync.WaitGroup
+
+	lock   sync.RWMutex
+	closed bool
+
+	maxQueueDepth uint64
+	verified      uint64
+	invalid       uint64
+	batches       uint64
+}
+
+// NewVerificationPool starts the given number of workers, the number of CPUs if zero,
+// with a queue of the given size, DefaultVerificationQueueSize if zero.
+func NewVerificationPool(workers int, queueSize int) *VerificationPool {
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if queueSize <= 0 {
+		queueSize = DefaultVerificationQueueSize
+	}
+	vp := &VerificationPool{
+		workers: workers,
+		tasks:   make(chan func(), queueSize),
+	}
+	vp.running.Add(workers)
+	for i := 0; i < workers; i++ {
+		go vp.run()
+	}
+	return vp
+}
+
+func (vp *VerificationPool) run() {
+	defer vp.running.Done()
+	for task := range vp.tasks {
+		task()
+	}
+}
+
+// Close stops the workers once they run the queued tasks.
+// Tasks submitted afterwards run in the calling goroutine.
+func (vp *VerificationPool) Close() {
+	vp.lock.Lock()
+	if !vp.closed {
+		vp.closed = true
+		close(vp.tasks)
+	}
+	vp.lock.Unlock()
+	vp.running.Wait()
+}
+
+// Go runs the task by one of the workers, waiting for room in the queue if it is full.
+func (vp *VerificationPool) Go(task func()) {
+	vp.lock.RLock()
+	if vp.closed {
+		vp.lock.RUnlock()
+		task()
+		return
+	}
+	vp.tasks <- task
+	depth := uint64(len(vp.tasks))
+	vp.lock.RUnlock()
+
+	for {
+		max := atomic.LoadUint64(&vp.maxQueueDepth)
+		if depth <= max || atomic.CompareAndSwapUint64(&vp.maxQueueDepth, max, depth) {
+			return
+		}
+	}
+}
+
+// Metrics returns a snapshot of the queue and the verifications.
+func (vp *VerificationPool) Metrics() types.VerificationMetrics {
+	return types.VerificationMetrics{
+		QueueDepth:    uint64(len(vp.tasks)),
+		MaxQueueDepth: atomic.LoadUint64(&vp.maxQueueDepth),
+		Verified:      atomic.LoadUint64(&vp.verified),
+		Invalid:       atomic.LoadUint64(&vp.invalid),
+		Batches:       atomic.LoadUint64(&vp.batches),
+	}
+}
+
+// VerifyRequests verifies the requests by the workers, splitting them evenly among the workers,
+// and waits for the results.
+func (vp *VerificationPool) VerifyRequests(verifier api.Verifier, requests [][]byte) ([]types.RequestInfo, []error) {
+	infos := make([]types.RequestInfo, len(requests))
+	errs := make([]error, len(requests))
+
+	chunkSize := (len(requests) + vp.workers - 1) / vp.workers
+	var wg sync.WaitGroup
+	for start := 0; start < len(requests); start += chunkSize {
+		end := start + chunkSize
+		if end > len(requests) {
+			end = len(requests)
+		}
+		wg.Add(1)
+		start := start
+		vp.Go(func() {
+			defer wg.Done()
+			chunkInfos, chunkErrs := vp.verifyRequests(verifier, requests[start:end])
+			copy(infos[start:end], chunkInfos)
+			copy(errs[start:end], chunkErrs)
+		})
+	}
+	wg.Wait()
+	return infos, errs
+}
+
+// verifyConsenterSigs verifies the signatures in the calling goroutine, at once if the verifier is a BatchVerifier.
+func (vp *VerificationPool) verifyConsenterSigs(verifier api.Verifier, signatures []types.Signature, proposal types.Proposal) []error {
+	errs, batched := verifyConsenterSigs(verifier, signatures, proposal)
+	vp.count(errs, batched)
+	return errs
+}
+
+// verifyRequests verifies the requests in the calling goroutine, at once if the verifier is a BatchVerifier.
+func (vp *VerificationPool) verifyRequests(verifier api.Verifier, requests [][]byte) ([]types.RequestInfo, []error) {
+	infos, errs, batched := verifyRequests(verifier, requests)
+	vp.count(errs, batched)
+	return infos, errs
+}
+
+func (vp *VerificationPool) count(errs []error, batched bool) {
+	var invalid uint64
+	for _, err := range errs {
+		if err != nil {
+			invalid++
+		}
+	}
+	atomic.AddUint64(&vp.verified, uint64(len(errs)))
+	atomic.AddUint64(&vp.invalid, invalid)
+	if batched {
+		atomic.AddUint64(&vp.batches, 1)
+	}
+}
+
+func verifyConsenterSigs(verifier api.Verifier, signatures []types.Signature, proposal types.Proposal) ([]error, bool) {
+	if batchVerifier, isBatch := verifier.(api.BatchVerifier); isBatch {
+		errs := batchVerifier.VerifyConsenterSigs(signatures, proposal)
+		if len(errs) != len(signatures) {
+			return sameError(len(signatures), errors.Errorf("batch verification returned %d results for %d signatures", len(errs), len(signatures))), true
+		}
+		return errs, true
+	}
+
+	errs := make([]error, len(signatures))
+	for i, signature := range signatures {
+		errs[i] = verifier.VerifyConsenterSig(signature, proposal)
+	}
+	return errs, false
+}
+
+func verifyRequests(verifier api.Verifier, requests [][]byte) ([]types.RequestInfo, []error, bool) {
+	if batchVerifier, isBatch := verifier.(api.BatchVerifier); isBatch {
+		infos, errs := batchVerifier.VerifyRequests(requests)
+		if len(infos) != len(requests) || len(errs) != len(requests) {
+			err := errors.Errorf("batch verification returned %d results for %d requests", len(errs), len(requests))
+			return make([]types.RequestInfo, len(requests)), sameError(len(requests), err), true
+		}
+		return infos, errs, true
+	}
+
+	infos := make([]types.RequestInfo, len(requests))
+	errs := make([]error, len(requests))
+	for i, request := range requests {
+		infos[i], errs[i] = verifier.VerifyRequest(request)
+	}
+	return infos, errs, false
+}
+
+func sameError(n int, err error) []error {
+	errs := make([]error, n)
+	for i := range errs {
+		errs[i] = err
+	}
+	return errs
+}
@@ -0,0 +1,157 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package bft_test
+
+import (
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/SmartBFT-Go/consensus/internal/bft"
+	"github.com/SmartBFT-Go/consensus/internal/bft/mocks"
+	"github.com/SmartBFT-Go/consensus/pkg/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type batchVerifier struct {
+	*mocks.VerifierMock
+	batches int32
+}
+
+func (bv *batchVerifier) VerifyConsenterSigs(signatures []types.Signature, prop types.Proposal) []error {
+	atomic.AddInt32(&bv.batches, 1)
+	errs := make([]error, len(signatures))
+	for i, signature := range signatures {
+		errs[i] = bv.VerifyConsenterSig(signature, prop)
+	}
+	return errs
+}
+
+func (bv *batchVerifier) VerifyRequests(requests [][]byte) ([]types.RequestInfo, []error) {
+	atomic.AddInt32(&bv.batches, 1)
+	infos := make([]types.RequestInfo, len(requests))
+	errs := make([]error, len(requests))
+	for i, request := range requests {
+		infos[i], errs[i] = bv.VerifyRequest(request)
+	}
+	return infos, errs
+}
+
+func TestVerificationPoolVerifyRequests(t *testing.T) {
+	requests := make([][]byte, 10)
+	for i := range requests {
+		requests[i] = []byte{byte(i)}
+	}
+
+	newVerifier := func() *mocks.VerifierMock {
+		verifier := &mocks.VerifierMock{}
+		verifier.On("VerifyRequest", mock.Anything).Return(func(request []byte) types.RequestInfo {
+			return types.RequestInfo{ID: fmt.Sprintf("%d", request[0])}
+		}, func(request []byte) error {
+			if request[0]%3 == 0 {
+				return errors.New("revoked")
+			}
+			return nil
+		})
+		return verifier
+	}
+
+	assertResults := func(t *testing.T, infos []types.RequestInfo, errs []error) {
+		for i := range requests {
+			assert.Equal(t, fmt.Sprintf("%d", i), infos[i].ID)
+			if i%3 == 0 {
+				assert.EqualError(t, errs[i], "revoked")
+			} else {
+				assert.NoError(t, errs[i])
+			}
+		}
+	}
+
+	t.Run("verifier", func(t *testing.T) {
+		vp := bft.NewVerificationPool(3, 10)
+		defer vp.Close()
+
+		infos, errs := vp.VerifyRequests(newVerifier(), requests)
+		assertResults(t, infos, errs)
+		metrics := vp.Metrics()
+		assert.Equal(t, uint64(10), metrics.Verified)
+		assert.Equal(t, uint64(4), metrics.Invalid)
+		assert.Equal(t, uint64(0), metrics.Batches)
+		assert.Equal(t, uint64(0), metrics.QueueDepth)
+	})
+
+	t.Run("batch verifier", func(t *testing.T) {
+		vp := bft.NewVerificationPool(3, 10)
+		defer vp.Close()
+
+		verifier := &batchVerifier{VerifierMock: newVerifier()}
+		infos, errs := vp.VerifyRequests(verifier, requests)
+		assertResults(t, infos, errs)
+		// The requests are split evenly among the workers
+		assert.Equal(t, int32(3), atomic.LoadInt32(&verifier.batches))
+		assert.Equal(t, uint64(3), vp.Metrics().Batches)
+	})
+
+	t.Run("closed", func(t *testing.T) {
+		vp := bft.NewVerificationPool(3, 10)
+		vp.Close()
+
+		infos, errs := vp.VerifyRequests(newVerifier(), requests)
+		assertResults(t, infos, errs)
+	})
+}
+
+func TestVerificationPoolBounded(t *testing.T) {
+	vp := bft.NewVerificationPool(2, 3)
+	defer vp.Close()
+
+	release := make(chan struct{})
+	var running, maxRunning int32
+	var wg sync.WaitGroup
+	task := func() {
+		defer wg.Done()
+		current := atomic.AddInt32(&running, 1)
+		for {
+			max := atomic.LoadInt32(&maxRunning)
+			if current <= max || atomic.CompareAndSwapInt32(&maxRunning, max, current) {
+				break
+			}
+		}
+		<-release
+		atomic.AddInt32(&running, -1)
+	}
+
+	// Two tasks occupy the workers and three wait in the queue
+	wg.Add(5)
+	for i := 0; i < 5; i++ {
+		vp.Go(task)
+	}
+
+	// The queue is full, so the next task waits for room in it
+	submitted := make(chan struct{})
+	wg.Add(1)
+	go func() {
+		vp.Go(task)
+		close(submitted)
+	}()
+	select {
+	case <-submitted:
+		t.Fatal("task was submitted to a full queue")
+	case <-time.After(100 * time.Millisecond):
+	}
+	assert.Equal(t, uint64(3), vp.Metrics().QueueDepth)
+
+	close(release)
+	<-submitted
+	wg.Wait()
+	assert.Equal(t, int32(2), atomic.LoadInt32(&maxRunning))
+	assert.Equal(t, uint64(0), vp.Metrics().QueueDepth)
+	assert.Equal(t, uint64(3), vp.Metrics().MaxQueueDepth)
+}
@@ -0,0 +1,189 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package bft
+
+import (
+	"bytes"
+	"sort"
+	"sync"
+
+	"github.com/SmartBFT-Go/consensus/pkg/api"
+	"github.com/SmartBFT-Go/consensus/pkg/types"
+	protos "github.com/SmartBFT-Go/consensus/smartbftprotos"
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+)
+
+const (
+	// ProtocolVersion is the highest version of the consensus protocol implemented by the library.
+	// Messages and metadata without a protocol version are of version 1.
+	ProtocolVersion uint32 = 3
+	// CompactPrePrepareVersion is the version from which leaders disseminating requests
+	// send pre-prepares with the IDs of the requests instead of the requests.
+	CompactPrePrepareVersion uint32 = 2
+	// PrePrepareChunksVersion is the version from which pre-prepares of large proposals are sent in erasure coded chunks.
+	PrePrepareChunksVersion uint32 = 3
+)
+
+// ProtocolVersions tracks the protocol version in effect, and the highest versions the nodes support.
+//
+// Every message is stamped with the version in effect at its sender and the highest version the sender supports.
+// Messages of versions we don't support, and of senders which don't support the version in effect, are rejected.
+// The version in effect is the version in the metadata of the last decision. The leader raises it once a quorum
+// of the nodes, including itself, advertised support for a higher version. Followers reject proposals of versions
+// they don't support, so a decision which raises the version is agreed by a quorum which supports it,
+// and the raised version is in effect from the next sequence.
+// Features which nodes of lower versions can't interpret are used only once a version which has them is in effect.
+type ProtocolVersions struct {
+	SelfID uint64
+	// The highest version we support, ProtocolVersion if zero
+	MaxVersion uint32
+	Checkpoint *types.Checkpoint
+	Logger     api.Logger
+
+	lock         sync.Mutex
+	advertised   map[uint64]uint32
+	incompatible map[uint64]struct{}
+
+	// The metadata of the last decision and the version decoded from it
+	activeLock     sync.Mutex
+	activeMetadata []byte
+	activeVersion  uint32
+}
+
+func (pv *ProtocolVersions) maxVersion() uint32 {
+	if pv.MaxVersion == 0 {
+		return ProtocolVersion
+	}
+	return pv.MaxVersion
+}
+
+// Active returns the protocol version in effect.
+// The metadata of the last decision is decoded only when it changes.
+func (pv *ProtocolVersions) Active() uint32 {
+	lastDecision, _ := pv.Checkpoint.Get()
+
+	pv.activeLock.Lock()
+	defer pv.activeLock.Unlock()
+	if pv.activeVersion != 0 && bytes.Equal(pv.activeMetadata, lastDecision.Metadata) {
+		return pv.activeVersion
+	}
+
+	md := &protos.ViewMetadata{}
+	if err := proto.Unmarshal(lastDecision.Metadata, md); err != nil {
+		pv.Logger.Warnf("Failed unmarshaling the metadata of the last decision: %v", err)
+	}
+	pv.activeMetadata = lastDecision.Metadata
+	pv.activeVersion = versionOrDefault(md.ProtocolVersion)
+	return pv.activeVersion
+}
+
+// Supports returns whether the version in effect has the features of the given version.
+// Without protocol versions, all features are used.
+func (pv *ProtocolVersions) Supports(version uint32) bool {
+	return pv == nil || pv.Active() >= version
+}
+
+// Stamp returns a copy of the message we send, stamped with the version in effect and the highest version we support.
+func (pv *ProtocolVersions) Stamp(m *protos.Message) *protos.Message {
+	return &protos.Message{
+		Content:            m.Content,
+		Authentication:     m.Authentication,
+		ProtocolVersion:    pv.Active(),
+		MaxProtocolVersion: pv.maxVersion(),
+	}
+}
+
+// Check records the highest version the sender supports,
+// and returns an error if the sender is incompatible with us.
+func (pv *ProtocolVersions) Check(sender uint64, m *protos.Message) error {
+	version := versionOrDefault(m.ProtocolVersion)
+	maxVersion := m.MaxProtocolVersion
+	if maxVersion < version {
+		maxVersion = version
+	}
+
+	err := pv.checkCompatibility(version, maxVersion)
+
+	pv.lock.Lock()
+	defer pv.lock.Unlock()
+	if pv.advertised == nil {
+		pv.advertised = make(map[uint64]uint32)
+		pv.incompatible = make(map[uint64]struct{})
+	}
+	pv.advertised[sender] = maxVersion
+	if err != nil {
+		pv.incompatible[sender] = struct{}{}
+	} else {
+		delete(pv.incompatible, sender)
+	}
+	return err
+}
+
+func (pv *ProtocolVersions) checkCompatibility(version, maxVersion uint32) error {
+	if version > pv.maxVersion() {
+		return errors.Errorf("protocol version %d is not supported, the highest supported version is %d", version, pv.maxVersion())
+	}
+	if active := pv.Active(); maxVersion < active {
+		return errors.Errorf("sender supports protocol versions up to %d, but version %d is in effect", maxVersion, active)
+	}
+	return nil
+}
+
+// Incompatible returns the nodes whose last message was rejected because of its protocol version.
+func (pv *ProtocolVersions) Incompatible() []uint64 {
+	pv.lock.Lock()
+	defer pv.lock.Unlock()
+	var nodes []uint64
+	for node := range pv.incompatible {
+		nodes = append(nodes, node)
+	}
+	sort.Slice(nodes, func(i, j int) bool {
+		return nodes[i] < nodes[j]
+	})
+	return nodes
+}
+
+// Next returns the version of the next proposal, which is the highest version
+// supported by a quorum of the nodes, including ourselves, and at least the version in effect.
+func (pv *ProtocolVersions) Next(quorum int, weights map[uint64]uint64) uint32 {
+	pv.lock.Lock()
+	defer pv.lock.Unlock()
+
+	next := pv.Active()
+	for version := pv.maxVersion(); version > next; version-- {
+		supporters := []uint64{pv.SelfID}
+		for node, maxVersion := range pv.advertised {
+			if node != pv.SelfID && maxVersion >= version {
+				supporters = append(supporters, node)
+			}
+		}
+		if votingWeight(weights, supporters...) >= quorum {
+			pv.Logger.Infof("A quorum supports protocol version %d, proposing to switch to it from version %d", version, next)
+			return version
+		}
+	}
+	return next
+}
+
+// Verify returns an error if the version of a proposal isn't the version in effect or a higher version we support.
+func (pv *ProtocolVersions) Verify(version uint32) error {
+	version = versionOrDefault(version)
+	if active := pv.Active(); version < active {
+		return errors.Errorf("protocol version %d is lower than the version in effect %d", version, active)
+	}
+	if version > pv.maxVersion() {
+		return errors.Errorf("protocol version %d is not supported", version)
+	}
+	return nil
+}
+
+func versionOrDefault(version uint32) uint32 {
+	if version == 0 {
+		return 1
+	}
+	return version
+}
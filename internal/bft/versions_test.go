@@ -0,0 +1,127 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package bft_test
+
+import (
+	"testing"
+
+	"github.com/SmartBFT-Go/consensus/internal/bft"
+	"github.com/SmartBFT-Go/consensus/internal/bft/mocks"
+	"github.com/SmartBFT-Go/consensus/pkg/auth"
+	"github.com/SmartBFT-Go/consensus/pkg/types"
+	protos "github.com/SmartBFT-Go/consensus/smartbftprotos"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"go.uber.org/zap"
+)
+
+func TestProtocolVersions(t *testing.T) {
+	basicLog, err := zap.NewDevelopment()
+	assert.NoError(t, err)
+
+	checkpoint := &types.Checkpoint{}
+	versions := &bft.ProtocolVersions{
+		SelfID:     1,
+		MaxVersion: 3,
+		Checkpoint: checkpoint,
+		Logger:     basicLog.Sugar(),
+	}
+	versionedMessage := func(version, maxVersion uint32) *protos.Message {
+		return &protos.Message{Content: prepare.Content, ProtocolVersion: version, MaxProtocolVersion: maxVersion}
+	}
+
+	// Nothing was decided yet, so the first version is in effect
+	assert.Equal(t, uint32(1), versions.Active())
+	stamped := versions.Stamp(prepare)
+	assert.Equal(t, uint32(1), stamped.ProtocolVersion)
+	assert.Equal(t, uint32(3), stamped.MaxProtocolVersion)
+	assert.Equal(t, prepare.Content, stamped.Content)
+
+	// Unversioned senders speak the first version
+	assert.NoError(t, versions.Check(2, prepare))
+	assert.NoError(t, versions.Check(3, versionedMessage(1, 2)))
+	assert.EqualError(t, versions.Check(4, versionedMessage(4, 4)),
+		"protocol version 4 is not supported, the highest supported version is 3")
+	assert.Equal(t, []uint64{4}, versions.Incompatible())
+
+	// Nodes 1, 3 and 4 support version 2, but only nodes 1 and 4 support version 3
+	assert.Equal(t, uint32(2), versions.Next(3, nil))
+	assert.Equal(t, uint32(1), versions.Next(4, nil))
+	assert.Equal(t, uint32(3), versions.Next(3, map[uint64]uint64{1: 2, 2: 1, 3: 1, 4: 1}))
+
+	checkpoint.Set(types.Proposal{Metadata: bft.MarshalOrPanic(&protos.ViewMetadata{LatestSequence: 1, ProtocolVersion: 2})}, nil)
+	assert.Equal(t, uint32(2), versions.Active())
+	assert.Equal(t, uint32(2), versions.Stamp(prepare).ProtocolVersion)
+	assert.EqualError(t, versions.Check(2, prepare), "sender supports protocol versions up to 1, but version 2 is in effect")
+	assert.NoError(t, versions.Check(4, versionedMessage(2, 4)))
+	assert.Equal(t, []uint64{2}, versions.Incompatible())
+
+	assert.NoError(t, versions.Verify(2))
+	assert.NoError(t, versions.Verify(3))
+	assert.EqualError(t, versions.Verify(1), "protocol version 1 is lower than the version in effect 2")
+	assert.EqualError(t, versions.Verify(0), "protocol version 1 is lower than the version in effect 2")
+	assert.EqualError(t, versions.Verify(4), "protocol version 4 is not supported")
+}
+
+func TestProtocolVersionsGateFeatures(t *testing.T) {
+	basicLog, err := zap.NewDevelopment()
+	assert.NoError(t, err)
+
+	var versions *bft.ProtocolVersions
+	assert.True(t, versions.Supports(bft.PrePrepareChunksVersion))
+
+	checkpoint := &types.Checkpoint{}
+	versions = &bft.ProtocolVersions{SelfID: 1, Checkpoint: checkpoint, Logger: basicLog.Sugar()}
+	assert.False(t, versions.Supports(bft.CompactPrePrepareVersion))
+
+	var sent []uint64
+	var broadcast []*protos.Message
+	comm := &mocks.CommMock{}
+	comm.On("Nodes").Return([]uint64{1, 2, 3, 4})
+	comm.On("SendConsensus", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		sent = append(sent, args.Get(0).(uint64))
+	})
+	comm.On("BroadcastConsensus", mock.Anything).Run(func(args mock.Arguments) {
+		broadcast = append(broadcast, args.Get(0).(*protos.Message))
+	})
+	ecc := &bft.ErasureCodedComm{
+		Comm:             comm,
+		SelfID:           1,
+		MinProposalSize:  100,
+		ProtocolVersions: versions,
+		Logger:           basicLog.Sugar(),
+	}
+
+	pp := proto.Clone(prePrepare).(*protos.Message)
+	pp.GetPrePrepare().Proposal.Payload = make([]byte, 1000)
+
+	// Nodes of the first version can't reconstruct chunks, so the pre-prepare is sent whole
+	ecc.BroadcastConsensus(pp)
+	assert.Equal(t, []*protos.Message{pp}, broadcast)
+	assert.Empty(t, sent)
+
+	checkpoint.Set(types.Proposal{Metadata: bft.MarshalOrPanic(&protos.ViewMetadata{LatestSequence: 1, ProtocolVersion: 2})}, nil)
+	assert.True(t, versions.Supports(bft.CompactPrePrepareVersion))
+	assert.False(t, versions.Supports(bft.PrePrepareChunksVersion))
+
+	checkpoint.Set(types.Proposal{Metadata: bft.MarshalOrPanic(&protos.ViewMetadata{LatestSequence: 2, ProtocolVersion: 3})}, nil)
+	assert.True(t, versions.Supports(bft.PrePrepareChunksVersion))
+	ecc.BroadcastConsensus(pp)
+	assert.Len(t, broadcast, 1)
+	assert.Equal(t, []uint64{2, 3, 4}, sent)
+}
+
+func TestMessageAuthenticationCoversProtocolVersions(t *testing.T) {
+	authenticator := &auth.HMACAuthenticator{SelfID: 1, Keys: map[uint64][]byte{1: []byte("secret")}}
+	versions := &bft.ProtocolVersions{Checkpoint: &types.Checkpoint{}, MaxVersion: 2}
+
+	authenticated := bft.AuthenticateMessage(authenticator, 1, versions.Stamp(prepare))
+	assert.NoError(t, bft.VerifyMessageAuthentication(authenticator, 1, authenticated))
+
+	authenticated.MaxProtocolVersion = 3
+	assert.Error(t, bft.VerifyMessageAuthentication(authenticator, 1, authenticated))
+}
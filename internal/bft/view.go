@@ -8,6 +8,7 @@ package bft
 import (
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/SmartBFT-Go/consensus/pkg/api"
 	"github.com/SmartBFT-Go/consensus/pkg/types"
@@ -25,6 +26,14 @@ const (
 	ABORT
 )
 
+// MetadataVersion is the version of the format of the metadata of proposals.
+// Proposals with metadata of any other version are rejected.
+// Only upgraded nodes detect a mismatch: nodes which predate metadata versions ignore the version,
+// the application metadata and the timestamp, so they accept the proposals of upgraded leaders as if these
+// weren't there, while upgraded nodes reject the proposals of old leaders, which carry no version.
+// The version isn't switched by protocol versions, so all nodes should be upgraded together.
+const MetadataVersion = 1
+
 //go:generate mockery -dir . -name State -case underscore -output ./mocks/
 
 type State interface {
@@ -58,13 +67,57 @@ type View struct {
 	ProposalSequence uint64
 	State            State
 	Phase            Phase
+	FlowControl      FlowControl
+	RejectionHandler api.MessageRejectionHandler
+	Halter           Halter
+	// If positive, the leader is rotated after this number of decisions in a view,
+	// and the metadata of each proposal counts the decisions in its view before it.
+	DecisionsPerLeader uint64
+	DecisionsInView    uint64
+	// If set, the metadata of each proposal carries the blacklist of nodes skipped by leader selection,
+	// and the pre-prepare carries the signatures on the last decision of the blacklisted nodes.
+	LeaderBlacklisting bool
+	LeaderRotation     api.LeaderRotation
+	Checkpoint         *types.Checkpoint
+	// If set, attaches application bytes to the metadata of proposals and verifies them
+	MetadataExtender api.MetadataExtender
+	// If positive, the metadata of each proposal carries a timestamp,
+	// which is accepted only within this skew from the local clock
+	TimestampSkew time.Duration
+	// If the cluster tolerates only crash failures, commits are not signed
+	FaultModel types.FaultModel
+	// If set, the voting weight of each node, and the quorum is a weight
+	Weights map[uint64]uint64
+	// If set, the metadata of each proposal carries the protocol version,
+	// which the leader raises once a quorum supports a higher version
+	ProtocolVersions *ProtocolVersions
+	// If set, is passed proposals once they are prepared
+	Speculation *Speculation
+	// If positive, prepares carry signatures on the proposal, and a proposal prepared
+	// by all the nodes within this timeout is committed without waiting for commits
+	FastPathTimeout time.Duration
+	// If set, a proposal adopted by a new view is the only proposal accepted with its sequence
+	InFlight *InFlightData
+	// If positive, votes are sent to the collector, which broadcasts certificates of a quorum of votes,
+	// and are broadcast to all nodes if a phase isn't completed within this timeout
+	CollectorTimeout time.Duration
+	// The node votes are sent to if CollectorTimeout is positive, the leader if zero
+	Collector uint64
+	// If set, the leader sends the requests of proposals instead of their payloads
+	Dissemination *Dissemination
+	// If set, verifies the signatures on commits by its workers instead of by a goroutine per commit
+	VerificationPool *VerificationPool
 	// Runtime
 	lastVotedProposalByID map[uint64]protos.Commit
-	incMsgs               chan *incMsg
+	incMsgs               *Inbox
+	seqLogger             *scopedLogger
 	myProposalSig         *types.Signature
 	inFlightProposal      *types.Proposal
 	inFlightRequests      []types.RequestInfo
 	lastBroadcastSent     *protos.Message
+	// The signatures in prepares collected on the fast path, which is open until fastPathTimeout fires
+	fastPathSignatures []types.Signature
+	fastPathTimeout    <-chan time.Time
 	// Current sequence sent prepare and commit
 	currPrepareSent *protos.Message
 	currCommitSent  *protos.Message
@@ -72,6 +125,9 @@ type View struct {
 	// to help lagging replicas catch up
 	prevPrepareSent *protos.Message
 	prevCommitSent  *protos.Message
+	// One more than the latest sequence in which votes were sent to all nodes,
+	// because their senders didn't get a certificate from the collector in time
+	fallbackSeq uint64
 	// Current proposal
 	prePrepare chan *protos.Message
 	prepares   *voteSet
@@ -88,7 +144,11 @@ type View struct {
 
 func (v *View) Start() {
 	v.stopOnce = sync.Once{}
-	v.incMsgs = make(chan *incMsg, 10*v.N) // TODO channel size should be configured
+	if _, isStructured := v.Logger.(api.StructuredLogger); isStructured {
+		v.seqLogger = newScopedLogger(v.Logger, "seq", v.ProposalSequence)
+		v.Logger = v.seqLogger
+	}
+	v.incMsgs = NewInbox(v.FlowControl, reportDroppedMessage(v.Logger, v.RejectionHandler))
 	v.abortChan = make(chan struct{})
 	v.lastVotedProposalByID = make(map[uint64]protos.Commit)
 	v.viewEnded.Add(1)
@@ -143,12 +203,28 @@ func (v *View) setupVotes() {
 	v.nextCommits.clear(v.N)
 }
 
+// HandleMessage passes a message to the view, without blocking.
+// The message is dropped if the queue of its sender is full, or the view isn't started.
 func (v *View) HandleMessage(sender uint64, m *protos.Message) {
-	msg := &incMsg{sender: sender, Message: m}
-	select {
-	case <-v.abortChan:
+	if v.incMsgs == nil {
+		v.Logger.Debugf("Dropping message from %d, as the view isn't started", sender)
 		return
-	case v.incMsgs <- msg:
+	}
+	if v.stopped() {
+		return
+	}
+	v.incMsgs.Put(sender, m)
+}
+
+// Drained returns a channel which is closed once the messages passed to the view so far are processed.
+func (v *View) Drained() <-chan struct{} {
+	return v.incMsgs.Drained()
+}
+
+func (v *View) processNextMsg() {
+	if sender, m, ok := v.incMsgs.Get(); ok {
+		defer v.incMsgs.Done()
+		v.processMsg(sender, m)
 	}
 }
 
@@ -196,12 +272,22 @@ func (v *View) processMsg(sender uint64, m *protos.Message) {
 		return
 	}
 
+	if cert := m.GetVoteCertificate(); cert != nil {
+		v.processVoteCertificate(sender, cert, msgForNextProposal)
+		return
+	}
+
 	// Else, it's a prepare or a commit.
 	// Ignore votes from ourselves.
 	if sender == v.SelfID {
 		return
 	}
 
+	// In the linear communication mode votes are sent to nodes other than the collector only on fall back.
+	if v.CollectorTimeout > 0 && !v.isCollector() {
+		v.assistFallback(sender, m, msgProposalSeq)
+	}
+
 	if prp := m.GetPrepare(); prp != nil {
 		if msgForNextProposal {
 			v.nextPrepares.registerVote(sender, m)
@@ -227,8 +313,8 @@ func (v *View) run() {
 		select {
 		case <-v.abortChan:
 			return
-		case msg := <-v.incMsgs:
-			v.processMsg(msg.sender, msg.Message)
+		case <-v.incMsgs.Ready():
+			v.processNextMsg()
 		default:
 			v.doPhase()
 		}
@@ -238,10 +324,10 @@ func (v *View) run() {
 func (v *View) doPhase() {
 	switch v.Phase {
 	case PROPOSED:
-		v.Comm.BroadcastConsensus(v.lastBroadcastSent) // broadcast here serves also recovery
+		v.sendVote(v.lastBroadcastSent) // sending here serves also recovery
 		v.Phase = v.processPrepares()
 	case PREPARED:
-		v.Comm.BroadcastConsensus(v.lastBroadcastSent)
+		v.sendVote(v.lastBroadcastSent)
 		v.Phase = v.prepared()
 	case COMMITTED:
 		v.Phase = v.processProposal()
@@ -300,8 +386,10 @@ func (v *View) processProposal() Phase {
 	v.inFlightProposal = nil
 	v.inFlightRequests = nil
 	v.lastBroadcastSent = nil
+	v.myProposalSig = nil
 
 	var proposal types.Proposal
+	var prevCommitSignatures []*protos.Signature
 	var receivedProposal *protos.Message
 
 	var gotPrePrepare bool
@@ -309,12 +397,13 @@ func (v *View) processProposal() Phase {
 		select {
 		case <-v.abortChan:
 			return ABORT
-		case msg := <-v.incMsgs:
-			v.processMsg(msg.sender, msg.Message)
+		case <-v.incMsgs.Ready():
+			v.processNextMsg()
 		case msg := <-v.prePrepare:
 			gotPrePrepare = true
 			receivedProposal = msg
 			prop := msg.GetPrePrepare().Proposal
+			prevCommitSignatures = msg.GetPrePrepare().PrevCommitSignatures
 			proposal = types.Proposal{
 				VerificationSequence: int64(prop.VerificationSequence),
 				Metadata:             prop.Metadata,
@@ -324,7 +413,38 @@ func (v *View) processProposal() Phase {
 		}
 	}
 
-	requests, err := v.verifyProposal(proposal)
+	if pp := receivedProposal.GetPrePrepare(); pp.Digest != "" {
+		assembled, aborted, err := v.assembleProposal(pp)
+		if aborted {
+			return ABORT
+		}
+		if err != nil {
+			v.Logger.Warnf("%d couldn't assemble the proposal of %d: %v", v.SelfID, v.LeaderID, err)
+			v.FailureDetector.Complain(false)
+			v.Sync.Sync()
+			v.stop()
+			return ABORT
+		}
+		proposal = *assembled
+		// We record the pre-prepare with the payload
+		receivedProposal = &protos.Message{
+			Content: &protos.Message_PrePrepare{
+				PrePrepare: &protos.PrePrepare{
+					View: pp.View,
+					Seq:  pp.Seq,
+					Proposal: &protos.Proposal{
+						Header:               proposal.Header,
+						Payload:              proposal.Payload,
+						Metadata:             proposal.Metadata,
+						VerificationSequence: uint64(proposal.VerificationSequence),
+					},
+					PrevCommitSignatures: pp.PrevCommitSignatures,
+				},
+			},
+		}
+	}
+
+	requests, err := v.verifyProposal(proposal, prevCommitSignatures)
 	if err != nil {
 		v.Logger.Warnf("%d received bad proposal from %d: %v", v.SelfID, v.LeaderID, err)
 		v.FailureDetector.Complain(false)
@@ -336,6 +456,17 @@ func (v *View) processProposal() Phase {
 	seq := v.ProposalSequence
 
 	prepareMessage := v.createPrepare(seq, proposal)
+	if v.FastPathTimeout > 0 {
+		v.myProposalSig = v.signProposal(proposal)
+		prepareMessage.GetPrepare().FastPathSignature = &protos.Signature{
+			Signer: v.myProposalSig.Id,
+			Value:  v.myProposalSig.Value,
+			Msg:    v.myProposalSig.Msg,
+		}
+	}
+	if v.CollectorTimeout > 0 && v.FaultModel != types.CrashFaults {
+		prepareMessage.GetPrepare().Signature = v.Signer.Sign(signedPrepareContent(prepareMessage.GetPrepare()))
+	}
 
 	// We are about to send a prepare for a pre-prepare,
 	// so we record the pre-prepare.
@@ -354,7 +485,13 @@ func (v *View) processProposal() Phase {
 	v.inFlightProposal = &proposal
 	v.inFlightRequests = requests
 
-	if v.SelfID == v.LeaderID {
+	if v.SelfID == v.LeaderID && v.Dissemination != nil && v.ProtocolVersions.Supports(CompactPrePrepareVersion) {
+		v.Comm.BroadcastConsensus(&protos.Message{
+			Content: &protos.Message_PrePrepare{
+				PrePrepare: v.Dissemination.Compact(receivedProposal.GetPrePrepare(), requests),
+			},
+		})
+	} else if v.SelfID == v.LeaderID {
 		v.Comm.BroadcastConsensus(receivedProposal)
 	}
 
@@ -362,6 +499,40 @@ func (v *View) processProposal() Phase {
 	return PROPOSED
 }
 
+// assembleProposal assembles the proposal of a pre-prepare which carries the requests of the proposal
+// instead of its payload, while fetching the requests we don't hold and processing incoming messages.
+func (v *View) assembleProposal(pp *protos.PrePrepare) (proposal *types.Proposal, aborted bool, err error) {
+	if v.Dissemination == nil {
+		return nil, false, errors.New("pre-prepare has no payload but requests aren't disseminated")
+	}
+
+	proposal, missing, err := v.Dissemination.Assemble(pp)
+	if err != nil || len(missing) == 0 {
+		return proposal, false, err
+	}
+
+	v.Logger.Debugf("Missing %d requests of proposal with seq %d, fetching them from %d", len(missing), pp.Seq, v.LeaderID)
+	v.Dissemination.Fetch(v.LeaderID, missing)
+	fetchTimeout := time.After(v.Dissemination.fetchTimeout())
+	for {
+		select {
+		case <-v.abortChan:
+			return nil, true, nil
+		case <-v.incMsgs.Ready():
+			v.processNextMsg()
+		case <-fetchTimeout:
+			v.Logger.Warnf("Missing %d requests of proposal with seq %d, fetching them from all nodes", len(missing), pp.Seq)
+			v.Dissemination.FetchFromAll(missing)
+			fetchTimeout = time.After(v.Dissemination.fetchTimeout())
+		case <-v.Dissemination.Arrived():
+			proposal, missing, err = v.Dissemination.Assemble(pp)
+			if err != nil || len(missing) == 0 {
+				return proposal, false, err
+			}
+		}
+	}
+}
+
 func (v *View) createPrepare(seq uint64, proposal types.Proposal) *protos.Message {
 	return &protos.Message{
 		Content: &protos.Message_Prepare{
@@ -378,13 +549,33 @@ func (v *View) processPrepares() Phase {
 	proposal := v.inFlightProposal
 	expectedDigest := proposal.Digest()
 
+	// On the fast path we keep collecting signed prepares while we collect commits,
+	// until all nodes prepared the proposal, a quorum committed it, or the fast path times out.
+	v.fastPathSignatures = nil
+	v.fastPathTimeout = nil
+	if v.FastPathTimeout > 0 {
+		v.fastPathTimeout = time.After(v.FastPathTimeout)
+	}
+
+	var collectorTimeout <-chan time.Time
+	if v.CollectorTimeout > 0 {
+		collectorTimeout = time.After(v.CollectorTimeout)
+	}
+
 	var voterIDs []uint64
-	for len(voterIDs) < v.Quorum-1 {
+	var signedPrepares []*protos.Signature
+	for votingWeight(v.Weights, voterIDs...)+votingWeight(v.Weights, v.SelfID) < v.Quorum {
 		select {
 		case <-v.abortChan:
 			return ABORT
-		case msg := <-v.incMsgs:
-			v.processMsg(msg.sender, msg.Message)
+		case <-v.incMsgs.Ready():
+			v.processNextMsg()
+		case <-v.fastPathTimeout:
+			v.fastPathTimedOut()
+		case <-collectorTimeout:
+			v.Logger.Warnf("Prepares weren't collected within %v, broadcasting our prepare", v.CollectorTimeout)
+			v.Comm.BroadcastConsensus(v.lastBroadcastSent)
+			collectorTimeout = nil
 		case vote := <-v.prepares.votes:
 			prepare := vote.GetPrepare()
 			if prepare.Digest != expectedDigest {
@@ -392,19 +583,29 @@ func (v *View) processPrepares() Phase {
 				v.Logger.Warnf("Got wrong digest at processPrepares for prepare with seq %d, expecting %v but got %v, we are in seq %d", prepare.Seq, expectedDigest, prepare.Digest, seq)
 				continue
 			}
+			if v.isCollector() {
+				if err := v.verifyPrepareSignature(vote.sender, prepare); err != nil {
+					v.Logger.Warnf("Couldn't verify the signature of the prepare of %d: %v", vote.sender, err)
+					continue
+				}
+				signedPrepares = append(signedPrepares, signedPrepare(vote.sender, prepare))
+			}
 			voterIDs = append(voterIDs, vote.sender)
+			v.collectFastPathSignature(vote.sender, prepare, proposal)
 		}
 	}
 
 	v.Logger.Infof("%d collected %d prepares from %v", v.SelfID, len(voterIDs), voterIDs)
 
-	// SignProposal returns a types.Signature with the following 3 fields:
-	// Id: The integer that represents this node.
-	// Value: The signature, encoded according to the specific signature specification.
-	// Msg: A succinct representation of the proposal that binds this proposal unequivocally.
+	if v.isCollector() {
+		signedPrepares = append(signedPrepares, signedPrepare(v.SelfID, v.lastBroadcastSent.GetPrepare()))
+		v.broadcastVoteCertificate(&protos.VoteCertificate{Prepares: signedPrepares})
+	}
 
-	// The block proof consists of the aggregation of all these signatures from 2f+1 commits of different nodes.
-	v.myProposalSig = v.Signer.SignProposal(*proposal)
+	// On the fast path we already signed the proposal in our prepare.
+	if v.myProposalSig == nil {
+		v.myProposalSig = v.signProposal(*proposal)
+	}
 
 	seq := v.ProposalSequence
 
@@ -437,9 +638,222 @@ func (v *View) processPrepares() Phase {
 	v.lastBroadcastSent = commitMsg
 
 	v.Logger.Infof("Processed prepares for proposal with seq %d", seq)
+	if v.Speculation != nil {
+		v.Speculation.Prepared(*proposal, seq)
+	}
+
+	if v.fastPathTimeout != nil && v.preparedByAll(v.fastPathSignatures) {
+		v.Logger.Infof("All nodes prepared proposal with seq %d, committing it on the fast path", seq)
+		// Nodes which didn't collect all prepares commit the proposal with our commit
+		v.Comm.BroadcastConsensus(commitMsg)
+		v.decide(proposal, v.fastPathSignatures, v.inFlightRequests)
+		return COMMITTED
+	}
 	return PREPARED
 }
 
+// collectFastPathSignature collects the signature in the prepare if the fast path is still open.
+func (v *View) collectFastPathSignature(sender uint64, prepare *protos.Prepare, proposal *types.Proposal) {
+	if v.fastPathTimeout == nil {
+		return
+	}
+	if signature, valid := v.verifyFastPathSignature(sender, prepare, proposal); valid {
+		v.fastPathSignatures = append(v.fastPathSignatures, signature)
+	}
+}
+
+func (v *View) fastPathTimedOut() {
+	v.Logger.Debugf("Not all nodes prepared within %v, waiting for commits", v.FastPathTimeout)
+	v.fastPathTimeout = nil
+}
+
+// signProposal returns our signature on the proposal, which is sent in commits, and in prepares on the fast path.
+// The block proof consists of the aggregation of all these signatures from 2f+1 commits of different nodes.
+// SignProposal returns a types.Signature with the following 3 fields:
+// Id: The integer that represents this node.
+// Value: The signature, encoded according to the specific signature specification.
+// Msg: A succinct representation of the proposal that binds this proposal unequivocally.
+func (v *View) signProposal(proposal types.Proposal) *types.Signature {
+	if v.FaultModel == types.CrashFaults {
+		return &types.Signature{Id: v.SelfID}
+	}
+	return v.Signer.SignProposal(proposal)
+}
+
+// preparedByAll returns whether we and the nodes whose signatures we collected on the fast path are all the nodes.
+func (v *View) preparedByAll(fastPathSignatures []types.Signature) bool {
+	signers := []uint64{v.SelfID}
+	for _, signature := range fastPathSignatures {
+		signers = append(signers, signature.Id)
+	}
+	if len(v.Weights) > 0 {
+		return uint64(votingWeight(v.Weights, signers...)) == types.TotalWeight(v.Weights)
+	}
+	return uint64(len(signers)) == v.N
+}
+
+func (v *View) verifyFastPathSignature(sender uint64, prepare *protos.Prepare, proposal *types.Proposal) (types.Signature, bool) {
+	sig := prepare.FastPathSignature
+	if sig == nil || sig.Signer != sender {
+		v.Logger.Debugf("Prepare of %d isn't signed for the fast path", sender)
+		return types.Signature{}, false
+	}
+	signature := types.Signature{Id: sig.Signer, Value: sig.Value, Msg: sig.Msg}
+	if err := consenterSigVerifier(v.Verifier, v.FaultModel).VerifyConsenterSig(signature, *proposal); err != nil {
+		v.Logger.Warnf("Couldn't verify %d's signature in its prepare: %v", sender, err)
+		return types.Signature{}, false
+	}
+	return signature, true
+}
+
+// sendVote sends our vote to the collector in the linear communication mode, and to all nodes otherwise.
+func (v *View) sendVote(m *protos.Message) {
+	if v.CollectorTimeout <= 0 {
+		v.Comm.BroadcastConsensus(m)
+		return
+	}
+	if v.fallbackSeq == v.ProposalSequence+1 {
+		v.Comm.BroadcastConsensus(m)
+		return
+	}
+	if collector := v.collector(); collector != v.SelfID {
+		v.Comm.SendConsensus(collector, m)
+	}
+}
+
+// assistFallback sends our vote to a node which fell back to sending its vote to all nodes,
+// as we might have sent our vote only to the collector, and we send our votes to all nodes for the rest of the sequence.
+func (v *View) assistFallback(sender uint64, m *protos.Message, seq uint64) {
+	if v.fallbackSeq < seq+1 {
+		v.fallbackSeq = seq + 1
+	}
+	if seq != v.ProposalSequence {
+		return
+	}
+	ours := v.currPrepareSent
+	if prp := m.GetPrepare(); prp != nil && prp.Assist {
+		return
+	}
+	if cmt := m.GetCommit(); cmt != nil {
+		if cmt.Assist {
+			return
+		}
+		ours = v.currCommitSent
+	}
+	if ours != nil {
+		v.Comm.SendConsensus(sender, ours)
+	}
+}
+
+func (v *View) collector() uint64 {
+	if v.Collector == 0 {
+		return v.LeaderID
+	}
+	return v.Collector
+}
+
+func (v *View) isCollector() bool {
+	return v.CollectorTimeout > 0 && v.collector() == v.SelfID
+}
+
+func (v *View) broadcastVoteCertificate(cert *protos.VoteCertificate) {
+	cert.View = v.Number
+	cert.Seq = v.ProposalSequence
+	cert.Digest = v.inFlightProposal.Digest()
+	v.Comm.BroadcastConsensus(&protos.Message{
+		Content: &protos.Message_VoteCertificate{
+			VoteCertificate: cert,
+		},
+	})
+}
+
+// processVoteCertificate registers the votes in a certificate of the collector as if their signers sent them.
+// The prepares are verified before they are registered, and the commits are verified when they are processed.
+func (v *View) processVoteCertificate(sender uint64, cert *protos.VoteCertificate, msgForNextProposal bool) {
+	logger := WithFields(v.Logger, "sender", sender)
+	if sender != v.collector() {
+		logger.Warnf("Got a vote certificate but the collector is %d", v.collector())
+		return
+	}
+
+	prepares, commits := v.prepares, v.commits
+	if msgForNextProposal {
+		prepares, commits = v.nextPrepares, v.nextCommits
+	}
+
+	var prepareVotes []*protos.Message
+	for _, sig := range cert.Prepares {
+		prepare := &protos.Prepare{}
+		if err := proto.Unmarshal(sig.Msg, prepare); err != nil {
+			logger.Warnf("Got a vote certificate with a malformed prepare of %d: %v", sig.Signer, err)
+			return
+		}
+		if prepare.View != cert.View || prepare.Seq != cert.Seq || prepare.Digest != cert.Digest {
+			logger.Warnf("Got a vote certificate with a prepare of %d for another proposal", sig.Signer)
+			return
+		}
+		prepare.Signature = sig.Value
+		if err := v.verifyPrepareSignature(sig.Signer, prepare); err != nil {
+			logger.Warnf("Got a vote certificate with an invalid prepare of %d: %v", sig.Signer, err)
+			return
+		}
+		prepareVotes = append(prepareVotes, &protos.Message{
+			Content: &protos.Message_Prepare{Prepare: prepare},
+		})
+	}
+
+	for i, sig := range cert.Prepares {
+		if sig.Signer != v.SelfID {
+			prepares.registerVote(sig.Signer, prepareVotes[i])
+		}
+	}
+
+	for _, sig := range cert.Commits {
+		if sig.Signer == v.SelfID {
+			continue
+		}
+		commits.registerVote(sig.Signer, &protos.Message{
+			Content: &protos.Message_Commit{
+				Commit: &protos.Commit{
+					View:      cert.View,
+					Seq:       cert.Seq,
+					Digest:    cert.Digest,
+					Signature: sig,
+				},
+			},
+		})
+	}
+}
+
+// signedPrepareContent returns the content of the prepare which is signed in the linear communication mode.
+func signedPrepareContent(prepare *protos.Prepare) []byte {
+	return MarshalOrPanic(&protos.Prepare{
+		View:              prepare.View,
+		Seq:               prepare.Seq,
+		Digest:            prepare.Digest,
+		FastPathSignature: prepare.FastPathSignature,
+	})
+}
+
+func signedPrepare(signer uint64, prepare *protos.Prepare) *protos.Signature {
+	return &protos.Signature{
+		Signer: signer,
+		Value:  prepare.Signature,
+		Msg:    signedPrepareContent(prepare),
+	}
+}
+
+func (v *View) verifyPrepareSignature(signer uint64, prepare *protos.Prepare) error {
+	if v.FaultModel == types.CrashFaults {
+		return nil
+	}
+	return v.Verifier.VerifySignature(types.Signature{
+		Id:    signer,
+		Value: prepare.Signature,
+		Msg:   signedPrepareContent(prepare),
+	})
+}
+
 func (v *View) processCommits(proposal *types.Proposal) ([]types.Signature, Phase) {
 	var signatures []types.Signature
 
@@ -450,19 +864,49 @@ func (v *View) processCommits(proposal *types.Proposal) ([]types.Signature, Phas
 		v:              v,
 	}
 
+	var collectorTimeout <-chan time.Time
+	if v.CollectorTimeout > 0 {
+		collectorTimeout = time.After(v.CollectorTimeout)
+	}
+
 	var voterIDs []uint64
 
-	for len(signatures) < v.Quorum-1 {
+	for votingWeight(v.Weights, voterIDs...)+votingWeight(v.Weights, v.SelfID) < v.Quorum {
 		select {
 		case <-v.abortChan:
 			return nil, ABORT
-		case msg := <-v.incMsgs:
-			v.processMsg(msg.sender, msg.Message)
+		case <-v.incMsgs.Ready():
+			v.processNextMsg()
+		case <-v.fastPathTimeout:
+			v.fastPathTimedOut()
+		case vote := <-v.prepares.votes:
+			// The prepares of the nodes which prepared after the quorum race the commits on the fast path
+			if prepare := vote.GetPrepare(); prepare.Digest == signatureCollector.expectedDigest {
+				v.collectFastPathSignature(vote.sender, prepare, proposal)
+			}
+			if v.fastPathTimeout == nil || !v.preparedByAll(v.fastPathSignatures) {
+				continue
+			}
+			v.Logger.Infof("All nodes prepared proposal with seq %d, committing it on the fast path", v.ProposalSequence)
+			if v.CollectorTimeout > 0 {
+				// Nodes which didn't collect all prepares commit the proposal with our commit
+				v.Comm.BroadcastConsensus(v.lastBroadcastSent)
+			}
+			return v.fastPathSignatures, COMMITTED
+		case <-collectorTimeout:
+			v.Logger.Warnf("Commits weren't collected within %v, broadcasting our commit", v.CollectorTimeout)
+			v.Comm.BroadcastConsensus(v.lastBroadcastSent)
+			collectorTimeout = nil
 		case vote := <-v.commits.votes:
+			// Commits which arrived meanwhile are verified along with it, in a batch if the verifier supports it.
+			votes := []*protos.Message{vote.Message}
+			for len(v.commits.votes) > 0 {
+				votes = append(votes, (<-v.commits.votes).Message)
+			}
 			// Valid votes end up written into the 'validVotes' channel.
-			go func(vote *protos.Message) {
-				signatureCollector.verifyVote(vote)
-			}(vote.Message)
+			v.verify(func() {
+				signatureCollector.verifyVotes(votes)
+			})
 		case signature := <-signatureCollector.validVotes:
 			signatures = append(signatures, signature)
 			voterIDs = append(voterIDs, signature.Id)
@@ -471,10 +915,22 @@ func (v *View) processCommits(proposal *types.Proposal) ([]types.Signature, Phas
 
 	v.Logger.Infof("%d collected %d commits from %v", v.SelfID, len(signatures), voterIDs)
 
+	if v.isCollector() {
+		var commits []*protos.Signature
+		for _, signature := range append(signatures, *v.myProposalSig) {
+			commits = append(commits, &protos.Signature{
+				Signer: signature.Id,
+				Value:  signature.Value,
+				Msg:    signature.Msg,
+			})
+		}
+		v.broadcastVoteCertificate(&protos.VoteCertificate{Commits: commits})
+	}
+
 	return signatures, COMMITTED
 }
 
-func (v *View) verifyProposal(proposal types.Proposal) ([]types.RequestInfo, error) {
+func (v *View) verifyProposal(proposal types.Proposal, prevCommitSignatures []*protos.Signature) ([]types.RequestInfo, error) {
 	// Verify proposal has correct structure and contains authorized requests.
 	requests, err := v.Verifier.VerifyProposal(proposal)
 	if err != nil {
@@ -482,13 +938,35 @@ func (v *View) verifyProposal(proposal types.Proposal) ([]types.RequestInfo, err
 		return nil, err
 	}
 
+	// A proposal adopted by a new view is the only proposal accepted with its sequence.
+	var adopted bool
+	if v.InFlight != nil {
+		if adoptedProposal := v.InFlight.Adopted(v.ProposalSequence); adoptedProposal != nil {
+			if proposal.Digest() != adoptedProposal.Digest() {
+				v.Logger.Warnf("Expected the proposal adopted by the new view with digest %s but got %s", adoptedProposal.Digest(), proposal.Digest())
+				return nil, errors.New("proposal is not the adopted in flight proposal")
+			}
+			adopted = true
+		}
+	}
+
 	// Verify proposal's metadata is valid.
 	md := &protos.ViewMetadata{}
 	if err := proto.Unmarshal(proposal.Metadata, md); err != nil {
 		return nil, err
 	}
 
-	if md.ViewId != v.Number {
+	if md.Version != MetadataVersion {
+		v.Logger.Errorf("Expected metadata version %d but got %d, the leader might run a different version", MetadataVersion, md.Version)
+		return nil, errors.Errorf("unsupported metadata version %d", md.Version)
+	}
+
+	// An adopted proposal was proposed in an earlier view, so it is verified against that view.
+	if adopted && md.ViewId >= v.Number {
+		v.Logger.Warnf("Expected the adopted proposal to be of a view before %d but got %d", v.Number, md.ViewId)
+		return nil, errors.New("invalid view number")
+	}
+	if !adopted && md.ViewId != v.Number {
 		v.Logger.Warnf("Expected view number %d but got %d", v.Number, md.ViewId)
 		return nil, errors.New("invalid view number")
 	}
@@ -498,6 +976,40 @@ func (v *View) verifyProposal(proposal types.Proposal) ([]types.RequestInfo, err
 		return nil, errors.New("invalid proposal sequence")
 	}
 
+	if v.DecisionsPerLeader > 0 {
+		if expected := v.decisionsInView(md.ViewId); md.DecisionsInView != expected {
+			v.Logger.Warnf("Expected %d decisions in view but got %d", expected, md.DecisionsInView)
+			return nil, errors.New("invalid decisions in view")
+		}
+	}
+
+	if adopted {
+		if err := v.verifyAdoptedBlacklist(md.BlackList, md.ViewId); err != nil {
+			return nil, err
+		}
+	} else if err := v.verifyBlacklist(md.BlackList, prevCommitSignatures); err != nil {
+		return nil, err
+	}
+
+	if err := v.verifyTimestamp(md.Timestamp, adopted); err != nil {
+		return nil, err
+	}
+
+	if v.ProtocolVersions != nil {
+		if err := v.ProtocolVersions.Verify(md.ProtocolVersion); err != nil {
+			v.Logger.Warnf("Received a proposal of an incompatible protocol version: %v", err)
+			return nil, err
+		}
+	}
+
+	if v.MetadataExtender != nil {
+		if err := v.MetadataExtender.VerifyApplicationMetadata(md.ViewId, md.LatestSequence, md.ApplicationMetadata); err != nil {
+			return nil, errors.Wrap(err, "invalid application metadata")
+		}
+	} else if len(md.ApplicationMetadata) > 0 {
+		return nil, errors.New("application metadata is not expected")
+	}
+
 	expectedSeq := v.Verifier.VerificationSequence()
 	if uint64(proposal.VerificationSequence) != expectedSeq {
 		v.Logger.Warnf("Expected verification sequence %d but got %d", expectedSeq, proposal.VerificationSequence)
@@ -512,6 +1024,10 @@ func (v *View) handlePrevSeqMessage(msgProposalSeq, sender uint64, m *protos.Mes
 		v.Logger.Warnf("Got pre-prepare for sequence %d but we're in sequence %d", msgProposalSeq, v.ProposalSequence)
 		return
 	}
+	if m.GetVoteCertificate() != nil {
+		v.Logger.Debugf("Got vote certificate for sequence %d but we're in sequence %d", msgProposalSeq, v.ProposalSequence)
+		return
+	}
 	msgType := "prepare"
 	if m.GetCommit() != nil {
 		msgType = "commit"
@@ -560,8 +1076,8 @@ func (v *View) discoverIfSyncNeeded(sender uint64, m *protos.Message) {
 	// In each such a threshold of f+1 votes there is at least
 	// a single honest node that prepared for a proposal
 	// which we apparently missed.
-	_, f := computeQuorum(v.N)
-	threshold := f + 1
+	_, f := computeWeightedQuorum(v.N, v.Weights, v.FaultModel)
+	threshold := trustThreshold(f, v.FaultModel)
 
 	v.lastVotedProposalByID[sender] = *commit
 
@@ -569,19 +1085,23 @@ func (v *View) discoverIfSyncNeeded(sender uint64, m *protos.Message) {
 		commit.Seq, commit.View, sender, v.ProposalSequence, v.Number)
 
 	// If we haven't reached a threshold of proposals yet, abort.
-	if len(v.lastVotedProposalByID) < threshold {
+	totalWeight := 0
+	for voter := range v.lastVotedProposalByID {
+		totalWeight += votingWeight(v.Weights, voter)
+	}
+	if totalWeight < threshold {
 		return
 	}
 
 	// Make a histogram out of all current seen votes.
 	countsByVotes := make(map[proposalInfo]int)
-	for _, vote := range v.lastVotedProposalByID {
+	for voter, vote := range v.lastVotedProposalByID {
 		info := proposalInfo{
 			digest: vote.Digest,
 			view:   vote.View,
 			seq:    vote.Seq,
 		}
-		countsByVotes[info]++
+		countsByVotes[info] += votingWeight(v.Weights, voter)
 	}
 
 	// Check if there is a <digest, view, seq> that collected a threshold of votes,
@@ -616,30 +1136,50 @@ type voteVerifier struct {
 	validVotes     chan types.Signature
 }
 
-func (vv *voteVerifier) verifyVote(vote *protos.Message) {
-	commit := vote.GetCommit()
-	if commit.Digest != vv.expectedDigest {
-		vv.v.Logger.Warnf("Got wrong digest at processCommits for seq %d", commit.Seq)
+func (vv *voteVerifier) verifyVotes(votes []*protos.Message) {
+	var signatures []types.Signature
+	for _, vote := range votes {
+		commit := vote.GetCommit()
+		if commit.Digest != vv.expectedDigest {
+			vv.v.Logger.Warnf("Got wrong digest at processCommits for seq %d", commit.Seq)
+			continue
+		}
+		signatures = append(signatures, types.Signature{
+			Id:    commit.Signature.Signer,
+			Value: commit.Signature.Value,
+			Msg:   commit.Signature.Msg,
+		})
+	}
+	if len(signatures) == 0 {
 		return
 	}
 
-	err := vv.v.Verifier.VerifyConsenterSig(types.Signature{
-		Id:    commit.Signature.Signer,
-		Value: commit.Signature.Value,
-		Msg:   commit.Signature.Msg,
-	}, *vv.proposal)
-	if err != nil {
-		vv.v.Logger.Warnf("Couldn't verify %d's signature: %v", commit.Signature.Signer, err)
-		return
+	verifier := consenterSigVerifier(vv.v.Verifier, vv.v.FaultModel)
+	var errs []error
+	if vv.v.VerificationPool != nil {
+		errs = vv.v.VerificationPool.verifyConsenterSigs(verifier, signatures, *vv.proposal)
+	} else {
+		errs, _ = verifyConsenterSigs(verifier, signatures, *vv.proposal)
 	}
 
-	vv.validVotes <- types.Signature{
-		Id:    commit.Signature.Signer,
-		Value: commit.Signature.Value,
-		Msg:   commit.Signature.Msg,
+	for i, signature := range signatures {
+		if errs[i] != nil {
+			vv.v.Logger.Warnf("Couldn't verify %d's signature: %v", signature.Id, errs[i])
+			continue
+		}
+		vv.validVotes <- signature
 	}
 }
 
+// verify runs the verification by the verification pool, and by a goroutine of its own if there is none.
+func (v *View) verify(task func()) {
+	if v.VerificationPool == nil {
+		go task()
+		return
+	}
+	v.VerificationPool.Go(task)
+}
+
 func (v *View) decide(proposal *types.Proposal, signatures []types.Signature, requests []types.RequestInfo) {
 	v.Logger.Infof("Deciding on seq %d", v.ProposalSequence)
 	// first make preparations for the next sequence so that the view will be ready to continue right after delivery
@@ -652,8 +1192,12 @@ func (v *View) startNextSeq() {
 	prevSeq := v.ProposalSequence
 
 	v.ProposalSequence++
+	v.DecisionsInView++
 
 	nextSeq := v.ProposalSequence
+	if v.seqLogger != nil {
+		v.seqLogger.scope("seq", nextSeq)
+	}
 
 	v.Logger.Infof("Sequence: %d-->%d", prevSeq, nextSeq)
 
@@ -685,10 +1229,27 @@ func (v *View) GetMetadata() []byte {
 	md := &protos.ViewMetadata{
 		ViewId:         v.Number,
 		LatestSequence: propSeq,
+		Version:        MetadataVersion,
+	}
+	if v.DecisionsPerLeader > 0 {
+		md.DecisionsInView = v.DecisionsInView
+	}
+	if v.LeaderBlacklisting {
+		md.BlackList = v.computeBlacklist(signersOf(v.blacklistedSignatures()))
+	}
+	if v.TimestampSkew > 0 {
+		md.Timestamp = v.nextTimestamp()
+	}
+	if v.ProtocolVersions != nil {
+		md.ProtocolVersion = v.ProtocolVersions.Next(v.Quorum, v.Weights)
+	}
+	if v.MetadataExtender != nil {
+		md.ApplicationMetadata = v.MetadataExtender.ApplicationMetadata(v.Number, propSeq)
 	}
 	metadata, err := proto.Marshal(md)
 	if err != nil {
-		v.Logger.Panicf("Failed marshaling metadata: %v", err)
+		haltOrPanic(v.Halter, v.Logger, errors.Wrap(err, "failed marshaling metadata"))
+		return nil
 	}
 	return metadata
 }
@@ -710,6 +1271,9 @@ func (v *View) Propose(proposal types.Proposal) {
 			},
 		},
 	}
+	if v.LeaderBlacklisting {
+		msg.GetPrePrepare().PrevCommitSignatures = v.blacklistedSignatures()
+	}
 	// Send the proposal to yourself in order to pre-prepare yourself and record
 	// it in the WAL before sending it to other nodes.
 	v.HandleMessage(v.LeaderID, msg)
@@ -739,3 +1303,186 @@ func (v *View) stopped() bool {
 		return false
 	}
 }
+
+// blacklistedSignatures returns the signatures on the last decision of the nodes in its blacklist.
+func (v *View) blacklistedSignatures() []*protos.Signature {
+	prevMD, err := v.lastDecisionMetadata()
+	if err != nil {
+		return nil
+	}
+	_, prevSignatures := v.Checkpoint.Get()
+
+	var signatures []*protos.Signature
+	for _, sig := range prevSignatures {
+		if containsID(prevMD.BlackList, sig.Signer) {
+			signatures = append(signatures, sig)
+		}
+	}
+	return signatures
+}
+
+func (v *View) computeBlacklist(prevCommitSigners []uint64) []uint64 {
+	prevMD, err := v.lastDecisionMetadata()
+	if err != nil {
+		return nil
+	}
+	_, f := computeWeightedQuorum(v.N, v.Weights, v.FaultModel)
+	bl := blacklist{
+		prevMD:             prevMD,
+		currView:           v.Number,
+		leaderID:           v.LeaderID,
+		nodes:              sortedNodes(v.Comm.Nodes()),
+		rotation:           v.LeaderRotation,
+		decisionsPerLeader: v.DecisionsPerLeader,
+		f:                  f,
+		weights:            v.Weights,
+	}
+	return bl.computeUpdate(prevCommitSigners)
+}
+
+func (v *View) verifyBlacklist(blacklist []uint64, prevCommitSignatures []*protos.Signature) error {
+	if !v.LeaderBlacklisting {
+		if len(blacklist) > 0 {
+			return errors.New("blacklist is not expected")
+		}
+		return nil
+	}
+
+	prevProposal, _ := v.Checkpoint.Get()
+	for _, sig := range prevCommitSignatures {
+		err := consenterSigVerifier(v.Verifier, v.FaultModel).VerifyConsenterSig(types.Signature{
+			Id:    sig.Signer,
+			Value: sig.Value,
+			Msg:   sig.Msg,
+		}, types.Proposal{
+			Header:               prevProposal.Header,
+			Payload:              prevProposal.Payload,
+			Metadata:             prevProposal.Metadata,
+			VerificationSequence: int64(prevProposal.VerificationSequence),
+		})
+		if err != nil {
+			v.Logger.Warnf("Couldn't verify %d's signature on the last decision: %v", sig.Signer, err)
+			return errors.New("invalid previous commit signature")
+		}
+	}
+
+	expected := v.computeBlacklist(signersOf(prevCommitSignatures))
+	if !equalIDs(expected, blacklist) {
+		v.Logger.Warnf("Expected blacklist %v but got %v", expected, blacklist)
+		return errors.New("invalid blacklist")
+	}
+	return nil
+}
+
+// verifyAdoptedBlacklist verifies the blacklist of a proposal adopted by a new view against the view it was proposed in.
+// The signers of the previous decision that the leader of that view collected are unknown, so only the nodes it could
+// have blacklisted are verified: the nodes in the blacklist of the previous decision and the leaders deposed since,
+// as long as the leader of that view isn't blacklisted, and the blacklisted nodes weigh at most f.
+func (v *View) verifyAdoptedBlacklist(proposed []uint64, view uint64) error {
+	if !v.LeaderBlacklisting {
+		if len(proposed) > 0 {
+			return errors.New("blacklist is not expected")
+		}
+		return nil
+	}
+
+	prevMD, err := v.lastDecisionMetadata()
+	if err != nil {
+		return err
+	}
+	_, f := computeWeightedQuorum(v.N, v.Weights, v.FaultModel)
+	nodes := sortedNodes(v.Comm.Nodes())
+	bl := blacklist{
+		prevMD:             prevMD,
+		currView:           view,
+		leaderID:           getLeaderID(view, v.LeaderRotation, nodes, prevMD.BlackList),
+		nodes:              nodes,
+		rotation:           v.LeaderRotation,
+		decisionsPerLeader: v.DecisionsPerLeader,
+		f:                  f,
+		weights:            v.Weights,
+	}
+	candidates := bl.candidates()
+	for _, id := range proposed {
+		if id == bl.leaderID || !containsID(candidates, id) {
+			v.Logger.Warnf("Node %d can't be blacklisted by the leader of view %d, the candidates are %v", id, view, candidates)
+			return errors.New("invalid blacklist")
+		}
+	}
+	if votingWeight(v.Weights, proposed...) > f {
+		v.Logger.Warnf("Blacklist %v weighs more than %d", proposed, f)
+		return errors.New("invalid blacklist")
+	}
+	return nil
+}
+
+// decisionsInView returns the number of decisions made in the given view before the proposal,
+// according to the last decision.
+func (v *View) decisionsInView(view uint64) uint64 {
+	if view == v.Number {
+		return v.DecisionsInView
+	}
+	prevMD, err := v.lastDecisionMetadata()
+	if err != nil || prevMD.LatestSequence == 0 || prevMD.ViewId != view {
+		return 0
+	}
+	return prevMD.DecisionsInView + 1
+}
+
+func signersOf(signatures []*protos.Signature) []uint64 {
+	var signers []uint64
+	for _, sig := range signatures {
+		signers = append(signers, sig.Signer)
+	}
+	return signers
+}
+
+// nextTimestamp returns the local time, unless it isn't after the timestamp of the last decision.
+func (v *View) nextTimestamp() int64 {
+	now := time.Now().UnixNano()
+	if prevMD, err := v.lastDecisionMetadata(); err == nil && now <= prevMD.Timestamp {
+		return prevMD.Timestamp + 1
+	}
+	return now
+}
+
+// verifyTimestamp verifies the timestamp is after the last decision, and within the allowed skew from the local time.
+// An adopted proposal was proposed before the view change, so its timestamp is only required not to be ahead of the local time.
+func (v *View) verifyTimestamp(timestamp int64, adopted bool) error {
+	if v.TimestampSkew <= 0 {
+		if timestamp != 0 {
+			return errors.New("timestamp is not expected")
+		}
+		return nil
+	}
+
+	proposed := time.Unix(0, timestamp)
+	now := time.Now()
+	if (!adopted && proposed.Before(now.Add(-v.TimestampSkew))) || proposed.After(now.Add(v.TimestampSkew)) {
+		v.Logger.Warnf("Timestamp %v is more than %v away from the local time %v", proposed, v.TimestampSkew, now)
+		return errors.New("timestamp is outside the allowed skew")
+	}
+
+	prevMD, err := v.lastDecisionMetadata()
+	if err != nil {
+		return err
+	}
+	if prev := prevMD.Timestamp; timestamp <= prev {
+		v.Logger.Warnf("Timestamp %v is not after the timestamp of the last decision %v", proposed, time.Unix(0, prev))
+		return errors.New("timestamp is not after the last decision")
+	}
+	return nil
+}
+
+// lastDecisionMetadata returns the metadata of the last decision,
+// and halts the node if it can't be unmarshaled, as the checkpoint is corrupted.
+func (v *View) lastDecisionMetadata() (*protos.ViewMetadata, error) {
+	md := &protos.ViewMetadata{}
+	prevProposal, _ := v.Checkpoint.Get()
+	if err := proto.Unmarshal(prevProposal.Metadata, md); err != nil {
+		err = errors.Wrap(err, "failed unmarshaling the metadata of the last decision")
+		haltOrPanic(v.Halter, v.Logger, err)
+		return nil, err
+	}
+	return md, nil
+}
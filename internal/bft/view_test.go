@@ -14,9 +14,11 @@ import (
 	"sync"
 	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/SmartBFT-Go/consensus/internal/bft"
 	"github.com/SmartBFT-Go/consensus/internal/bft/mocks"
+	"github.com/SmartBFT-Go/consensus/pkg/api"
 	"github.com/SmartBFT-Go/consensus/pkg/types"
 	"github.com/SmartBFT-Go/consensus/pkg/wal"
 	protos "github.com/SmartBFT-Go/consensus/smartbftprotos"
@@ -32,6 +34,7 @@ var (
 		Header:  []byte{0},
 		Payload: []byte{1},
 		Metadata: bft.MarshalOrPanic(&protos.ViewMetadata{
+			Version:        bft.MetadataVersion,
 			LatestSequence: 0,
 			ViewId:         1,
 		}),
@@ -58,6 +61,7 @@ var (
 					Header:  []byte{0},
 					Payload: []byte{1},
 					Metadata: bft.MarshalOrPanic(&protos.ViewMetadata{
+						Version:        bft.MetadataVersion,
 						LatestSequence: 0,
 						ViewId:         1,
 					}),
@@ -137,10 +141,197 @@ func TestViewBasic(t *testing.T) {
 		Number:           1,
 		ProposalSequence: 0,
 	}
+	// Messages passed before the view is started are dropped
+	view.HandleMessage(1, prepare)
 	view.Start()
+	<-view.Drained()
 	view.Abort()
 }
 
+func TestViewTimestampAfterLastDecision(t *testing.T) {
+	basicLog, err := zap.NewDevelopment()
+	assert.NoError(t, err)
+	view := &bft.View{
+		Logger:        basicLog.Sugar(),
+		N:             4,
+		LeaderID:      1,
+		Quorum:        3,
+		Number:        1,
+		TimestampSkew: time.Minute,
+		Checkpoint:    &types.Checkpoint{},
+	}
+
+	md := &protos.ViewMetadata{}
+	assert.NoError(t, proto.Unmarshal(view.GetMetadata(), md))
+	assert.InDelta(t, time.Now().UnixNano(), md.Timestamp, float64(time.Second))
+
+	// The clock of the leader is behind the timestamp of the last decision
+	lastDecisionTimestamp := time.Now().Add(time.Second).UnixNano()
+	view.Checkpoint.Set(types.Proposal{
+		Metadata: bft.MarshalOrPanic(&protos.ViewMetadata{Timestamp: lastDecisionTimestamp}),
+	}, nil)
+	assert.NoError(t, proto.Unmarshal(view.GetMetadata(), md))
+	assert.Equal(t, lastDecisionTimestamp+1, md.Timestamp)
+}
+
+func TestViewHaltsOnCorruptedCheckpoint(t *testing.T) {
+	basicLog, err := zap.NewDevelopment()
+	assert.NoError(t, err)
+	halter := &mocks.Halter{}
+	var haltErr error
+	halter.On("Halt", mock.Anything).Run(func(args mock.Arguments) {
+		haltErr = args.Error(0)
+	})
+	view := &bft.View{
+		Logger:        basicLog.Sugar(),
+		N:             4,
+		LeaderID:      1,
+		Quorum:        3,
+		Number:        1,
+		TimestampSkew: time.Minute,
+		Checkpoint:    &types.Checkpoint{},
+		Halter:        halter,
+	}
+	view.Checkpoint.Set(types.Proposal{Metadata: []byte{1, 2, 3}}, nil)
+
+	// The node halts instead of panicking, and the leader proposes with the local time
+	md := &protos.ViewMetadata{}
+	assert.NoError(t, proto.Unmarshal(view.GetMetadata(), md))
+	assert.InDelta(t, time.Now().UnixNano(), md.Timestamp, float64(time.Second))
+	halter.AssertCalled(t, "Halt", mock.Anything)
+	assert.Contains(t, haltErr.Error(), "failed unmarshaling the metadata of the last decision")
+}
+
+func TestViewApplicationMetadata(t *testing.T) {
+	basicLog, err := zap.NewDevelopment()
+	assert.NoError(t, err)
+	extender := &mocks.MetadataExtenderMock{}
+	extender.On("ApplicationMetadata", uint64(1), uint64(5)).Return([]byte{1, 2, 3})
+	view := &bft.View{
+		Logger:           basicLog.Sugar(),
+		N:                4,
+		LeaderID:         1,
+		Quorum:           3,
+		Number:           1,
+		ProposalSequence: 5,
+		MetadataExtender: extender,
+	}
+
+	md := &protos.ViewMetadata{}
+	assert.NoError(t, proto.Unmarshal(view.GetMetadata(), md))
+	assert.Equal(t, &protos.ViewMetadata{
+		Version:             bft.MetadataVersion,
+		ViewId:              1,
+		LatestSequence:      5,
+		ApplicationMetadata: []byte{1, 2, 3},
+	}, md)
+}
+
+func TestViewVerifiesAdoptedProposal(t *testing.T) {
+	basicLog, err := zap.NewDevelopment()
+	assert.NoError(t, err)
+	log := basicLog.Sugar()
+
+	for _, testCase := range []struct {
+		description string
+		metadata    *protos.ViewMetadata
+		accepted    bool
+	}{
+		{
+			description: "proposed in an earlier view",
+			metadata:    &protos.ViewMetadata{Version: bft.MetadataVersion, ViewId: 1, LatestSequence: 1, Timestamp: time.Now().Add(-2 * time.Minute).UnixNano()},
+			accepted:    true,
+		},
+		{
+			description: "deposed leader blacklisted",
+			metadata:    &protos.ViewMetadata{Version: bft.MetadataVersion, ViewId: 1, LatestSequence: 1, Timestamp: time.Now().UnixNano(), BlackList: []uint64{1}},
+			accepted:    true,
+		},
+		{
+			description: "proposed in the current view",
+			metadata:    &protos.ViewMetadata{Version: bft.MetadataVersion, ViewId: 2, LatestSequence: 1, Timestamp: time.Now().UnixNano()},
+		},
+		{
+			description: "unsupported metadata version",
+			metadata:    &protos.ViewMetadata{ViewId: 1, LatestSequence: 1, Timestamp: time.Now().UnixNano()},
+		},
+		{
+			description: "timestamp ahead of the local time",
+			metadata:    &protos.ViewMetadata{Version: bft.MetadataVersion, ViewId: 1, LatestSequence: 1, Timestamp: time.Now().Add(2 * time.Minute).UnixNano()},
+		},
+		{
+			description: "node which wasn't deposed blacklisted",
+			metadata:    &protos.ViewMetadata{Version: bft.MetadataVersion, ViewId: 1, LatestSequence: 1, Timestamp: time.Now().UnixNano(), BlackList: []uint64{4}},
+		},
+	} {
+		t.Run(testCase.description, func(t *testing.T) {
+			adopted := types.Proposal{
+				Header:               []byte{0},
+				Payload:              []byte{1},
+				Metadata:             bft.MarshalOrPanic(testCase.metadata),
+				VerificationSequence: 1,
+			}
+			inFlight := &bft.InFlightData{}
+			inFlight.StoreAdopted(&adopted)
+
+			outcome := make(chan bool, 1)
+			comm := &mocks.CommMock{}
+			comm.On("Nodes").Return([]uint64{1, 2, 3, 4})
+			comm.On("BroadcastConsensus", mock.Anything).Run(func(args mock.Arguments) {
+				if args.Get(0).(*protos.Message).GetPrepare() != nil {
+					outcome <- true
+				}
+			})
+			fd := &mocks.FailureDetector{}
+			fd.On("Complain", false).Run(func(args mock.Arguments) {
+				outcome <- false
+			})
+			synchronizer := &mocks.Synchronizer{}
+			synchronizer.On("Sync")
+			verifier := &mocks.VerifierMock{}
+			verifier.On("VerificationSequence").Return(uint64(1))
+			verifier.On("VerifyProposal", mock.Anything).Return(nil, nil)
+
+			view := &bft.View{
+				State:              &bft.StateRecorder{},
+				Logger:             log,
+				N:                  4,
+				LeaderID:           3,
+				SelfID:             2,
+				Quorum:             3,
+				Number:             2,
+				ProposalSequence:   1,
+				Comm:               comm,
+				FailureDetector:    fd,
+				Sync:               synchronizer,
+				Verifier:           verifier,
+				Checkpoint:         &types.Checkpoint{},
+				TimestampSkew:      time.Minute,
+				LeaderBlacklisting: true,
+				InFlight:           inFlight,
+			}
+			view.Start()
+			defer view.Abort()
+
+			view.HandleMessage(3, &protos.Message{
+				Content: &protos.Message_PrePrepare{
+					PrePrepare: &protos.PrePrepare{
+						View: 2,
+						Seq:  1,
+						Proposal: &protos.Proposal{
+							Header:               adopted.Header,
+							Payload:              adopted.Payload,
+							Metadata:             adopted.Metadata,
+							VerificationSequence: uint64(adopted.VerificationSequence),
+						},
+					},
+				},
+			})
+			assert.Equal(t, testCase.accepted, <-outcome)
+		})
+	}
+}
+
 func TestBadPrePrepare(t *testing.T) {
 	// Ensure that a prePrepare with a wrong view number sent by the leader causes a view abort,
 	// and that if the same message is from a follower then it is simply ignored.
@@ -159,6 +350,10 @@ func TestBadPrePrepare(t *testing.T) {
 		corruptProposal       func(*protos.PrePrepare)
 		assert                func()
 		verifyProposalReturns error
+		decisionsPerLeader    uint64
+		metadataExtender      api.MetadataExtender
+		timestampSkew         time.Duration
+		lastDecisionTimestamp int64
 	}{
 		{
 			description: "wrong view number",
@@ -244,6 +439,7 @@ func TestBadPrePrepare(t *testing.T) {
 			},
 			corruptProposal: func(proposal *protos.PrePrepare) {
 				proposal.Proposal.Metadata = bft.MarshalOrPanic(&protos.ViewMetadata{
+					Version:        bft.MetadataVersion,
 					LatestSequence: 0,
 					ViewId:         2,
 				})
@@ -265,6 +461,7 @@ func TestBadPrePrepare(t *testing.T) {
 			},
 			corruptProposal: func(proposal *protos.PrePrepare) {
 				proposal.Proposal.Metadata = bft.MarshalOrPanic(&protos.ViewMetadata{
+					Version:        bft.MetadataVersion,
 					LatestSequence: 1,
 					ViewId:         1,
 				})
@@ -276,6 +473,192 @@ func TestBadPrePrepare(t *testing.T) {
 				fd.AssertCalled(t, "Complain", false)
 			},
 		},
+		{
+			description:        "wrong decisions in view in metadata",
+			expectedErr:        "received bad proposal from 1: invalid decisions in view",
+			sender:             1,
+			decisionsPerLeader: 2,
+			setup: func() {
+				syncWG.Add(1)
+				fdWG.Add(1)
+			},
+			corruptProposal: func(proposal *protos.PrePrepare) {
+				proposal.Proposal.Metadata = bft.MarshalOrPanic(&protos.ViewMetadata{
+					Version:         bft.MetadataVersion,
+					LatestSequence:  0,
+					ViewId:          1,
+					DecisionsInView: 1,
+				})
+			},
+			assert: func() {
+				syncWG.Wait()
+				synchronizer.AssertCalled(t, "Sync")
+				fdWG.Wait()
+				fd.AssertCalled(t, "Complain", false)
+			},
+		},
+		{
+			description: "unexpected blacklist in metadata",
+			expectedErr: "received bad proposal from 1: blacklist is not expected",
+			sender:      1,
+			setup: func() {
+				syncWG.Add(1)
+				fdWG.Add(1)
+			},
+			corruptProposal: func(proposal *protos.PrePrepare) {
+				proposal.Proposal.Metadata = bft.MarshalOrPanic(&protos.ViewMetadata{
+					Version:        bft.MetadataVersion,
+					LatestSequence: 0,
+					ViewId:         1,
+					BlackList:      []uint64{2},
+				})
+			},
+			assert: func() {
+				syncWG.Wait()
+				synchronizer.AssertCalled(t, "Sync")
+				fdWG.Wait()
+				fd.AssertCalled(t, "Complain", false)
+			},
+		},
+		{
+			description: "unsupported metadata version",
+			expectedErr: "received bad proposal from 1: unsupported metadata version 2",
+			sender:      1,
+			setup: func() {
+				syncWG.Add(1)
+				fdWG.Add(1)
+			},
+			corruptProposal: func(proposal *protos.PrePrepare) {
+				proposal.Proposal.Metadata = bft.MarshalOrPanic(&protos.ViewMetadata{
+					Version:        bft.MetadataVersion + 1,
+					LatestSequence: 0,
+					ViewId:         1,
+				})
+			},
+			assert: func() {
+				syncWG.Wait()
+				synchronizer.AssertCalled(t, "Sync")
+				fdWG.Wait()
+				fd.AssertCalled(t, "Complain", false)
+			},
+		},
+		{
+			description: "unexpected application metadata",
+			expectedErr: "received bad proposal from 1: application metadata is not expected",
+			sender:      1,
+			setup: func() {
+				syncWG.Add(1)
+				fdWG.Add(1)
+			},
+			corruptProposal: func(proposal *protos.PrePrepare) {
+				proposal.Proposal.Metadata = bft.MarshalOrPanic(&protos.ViewMetadata{
+					Version:             bft.MetadataVersion,
+					LatestSequence:      0,
+					ViewId:              1,
+					ApplicationMetadata: []byte{1, 2, 3},
+				})
+			},
+			assert: func() {
+				syncWG.Wait()
+				synchronizer.AssertCalled(t, "Sync")
+				fdWG.Wait()
+				fd.AssertCalled(t, "Complain", false)
+			},
+		},
+		{
+			description: "bad application metadata",
+			expectedErr: "received bad proposal from 1: invalid application metadata: unknown tenant",
+			sender:      1,
+			metadataExtender: func() api.MetadataExtender {
+				extender := &mocks.MetadataExtenderMock{}
+				extender.On("VerifyApplicationMetadata", uint64(1), uint64(0), []byte(nil)).Return(errors.New("unknown tenant"))
+				return extender
+			}(),
+			setup: func() {
+				syncWG.Add(1)
+				fdWG.Add(1)
+			},
+			corruptProposal: func(proposal *protos.PrePrepare) {},
+			assert: func() {
+				syncWG.Wait()
+				synchronizer.AssertCalled(t, "Sync")
+				fdWG.Wait()
+				fd.AssertCalled(t, "Complain", false)
+			},
+		},
+		{
+			description:   "unexpected timestamp",
+			expectedErr:   "received bad proposal from 1: timestamp is not expected",
+			sender:        1,
+			timestampSkew: 0,
+			setup: func() {
+				syncWG.Add(1)
+				fdWG.Add(1)
+			},
+			corruptProposal: func(proposal *protos.PrePrepare) {
+				proposal.Proposal.Metadata = bft.MarshalOrPanic(&protos.ViewMetadata{
+					Version:        bft.MetadataVersion,
+					LatestSequence: 0,
+					ViewId:         1,
+					Timestamp:      time.Now().UnixNano(),
+				})
+			},
+			assert: func() {
+				syncWG.Wait()
+				synchronizer.AssertCalled(t, "Sync")
+				fdWG.Wait()
+				fd.AssertCalled(t, "Complain", false)
+			},
+		},
+		{
+			description:   "timestamp outside the allowed skew",
+			expectedErr:   "received bad proposal from 1: timestamp is outside the allowed skew",
+			sender:        1,
+			timestampSkew: time.Minute,
+			setup: func() {
+				syncWG.Add(1)
+				fdWG.Add(1)
+			},
+			corruptProposal: func(proposal *protos.PrePrepare) {
+				proposal.Proposal.Metadata = bft.MarshalOrPanic(&protos.ViewMetadata{
+					Version:        bft.MetadataVersion,
+					LatestSequence: 0,
+					ViewId:         1,
+					Timestamp:      time.Now().Add(-time.Hour).UnixNano(),
+				})
+			},
+			assert: func() {
+				syncWG.Wait()
+				synchronizer.AssertCalled(t, "Sync")
+				fdWG.Wait()
+				fd.AssertCalled(t, "Complain", false)
+			},
+		},
+		{
+			description:           "timestamp not after the last decision",
+			expectedErr:           "received bad proposal from 1: timestamp is not after the last decision",
+			sender:                1,
+			timestampSkew:         time.Minute,
+			lastDecisionTimestamp: time.Now().Add(time.Second).UnixNano(),
+			setup: func() {
+				syncWG.Add(1)
+				fdWG.Add(1)
+			},
+			corruptProposal: func(proposal *protos.PrePrepare) {
+				proposal.Proposal.Metadata = bft.MarshalOrPanic(&protos.ViewMetadata{
+					Version:        bft.MetadataVersion,
+					LatestSequence: 0,
+					ViewId:         1,
+					Timestamp:      time.Now().UnixNano(),
+				})
+			},
+			assert: func() {
+				syncWG.Wait()
+				synchronizer.AssertCalled(t, "Sync")
+				fdWG.Wait()
+				fd.AssertCalled(t, "Complain", false)
+			},
+		},
 		{
 			description: "corrupt metadata in proposal",
 			expectedErr: "received bad proposal from 1: proto: smartbftprotos.ViewMetadata: illegal tag 0 (wire type 1)",
@@ -336,7 +719,15 @@ func TestBadPrePrepare(t *testing.T) {
 				ProposalSequence: 0,
 				Sync:             synchronizer,
 				FailureDetector:  fd,
+
+				DecisionsPerLeader: testCase.decisionsPerLeader,
+				MetadataExtender:   testCase.metadataExtender,
+				TimestampSkew:      testCase.timestampSkew,
+				Checkpoint:         &types.Checkpoint{},
 			}
+			view.Checkpoint.Set(types.Proposal{
+				Metadata: bft.MarshalOrPanic(&protos.ViewMetadata{Timestamp: testCase.lastDecisionTimestamp}),
+			}, nil)
 			view.Start()
 
 			proposalSentByLeader := proto.Clone(prePrepare).(*protos.Message)
@@ -520,6 +911,7 @@ func TestNormalPath(t *testing.T) {
 	prePrepareNextGet := prePrepareNext.GetPrePrepare()
 	prePrepareNextGet.Seq = 1
 	prePrepareNextGet.GetProposal().Metadata = bft.MarshalOrPanic(&protos.ViewMetadata{
+		Version:        bft.MetadataVersion,
 		LatestSequence: 1,
 		ViewId:         1,
 	})
@@ -631,6 +1023,7 @@ func TestNormalPath(t *testing.T) {
 	dProp = <-decidedProposal
 	secondProposal := proposal
 	secondProposal.Metadata = bft.MarshalOrPanic(&protos.ViewMetadata{
+		Version:        bft.MetadataVersion,
 		LatestSequence: 1,
 		ViewId:         1,
 	})
@@ -704,6 +1097,7 @@ func TestTwoSequences(t *testing.T) {
 		Header:  []byte{0},
 		Payload: []byte{1},
 		Metadata: bft.MarshalOrPanic(&protos.ViewMetadata{
+			Version:        bft.MetadataVersion,
 			LatestSequence: 1,
 			ViewId:         1,
 		}),
@@ -1101,6 +1495,7 @@ func TestTwoPrePreparesInARow(t *testing.T) {
 	prePrepareNextGet := prePrepareNext.GetPrePrepare()
 	prePrepareNextGet.Seq = 1
 	prePrepareNextGet.GetProposal().Metadata = bft.MarshalOrPanic(&protos.ViewMetadata{
+		Version:        bft.MetadataVersion,
 		LatestSequence: 1,
 		ViewId:         1,
 	})
@@ -1249,6 +1644,7 @@ func TestViewLaggingCatchup(t *testing.T) {
 			Header:  []byte{0},
 			Payload: []byte{1},
 			Metadata: bft.MarshalOrPanic(&protos.ViewMetadata{
+				Version:        bft.MetadataVersion,
 				LatestSequence: 1,
 				ViewId:         1,
 			}),
@@ -1405,3 +1801,267 @@ func newView(t *testing.T, selfID uint64, network map[uint64]*testedView) *teste
 
 	return tv
 }
+
+func TestFastPath(t *testing.T) {
+	basicLog, err := zap.NewDevelopment()
+	assert.NoError(t, err)
+	log := basicLog.Sugar()
+
+	fastPathPrepare := func(sender uint64) *protos.Message {
+		return &protos.Message{
+			Content: &protos.Message_Prepare{
+				Prepare: &protos.Prepare{
+					View:   1,
+					Seq:    0,
+					Digest: digest,
+					FastPathSignature: &protos.Signature{
+						Signer: sender,
+						Value:  []byte{4},
+					},
+				},
+			},
+		}
+	}
+
+	for _, testCase := range []struct {
+		description        string
+		fastPathTimeout    time.Duration
+		prepareSenders     []uint64
+		commits            []*protos.Message
+		expectedSignatures []uint64
+	}{
+		{
+			description:        "prepared by all",
+			fastPathTimeout:    time.Minute,
+			prepareSenders:     []uint64{2, 3, 4},
+			expectedSignatures: []uint64{2, 3, 4, 1},
+		},
+		{
+			description:        "fast path times out",
+			fastPathTimeout:    100 * time.Millisecond,
+			prepareSenders:     []uint64{2, 3},
+			commits:            []*protos.Message{commit2, commit3},
+			expectedSignatures: []uint64{2, 3, 1},
+		},
+		{
+			description:        "committed before all prepared",
+			fastPathTimeout:    time.Minute,
+			prepareSenders:     []uint64{2, 3},
+			commits:            []*protos.Message{commit2, commit3},
+			expectedSignatures: []uint64{2, 3, 1},
+		},
+	} {
+		testCase := testCase
+		t.Run(testCase.description, func(t *testing.T) {
+			comm := &mocks.CommMock{}
+			comm.On("BroadcastConsensus", mock.Anything)
+			decider := &mocks.Decider{}
+			decided := make(chan []types.Signature, 1)
+			decider.On("Decide", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+				decided <- args.Get(1).([]types.Signature)
+			})
+			verifier := &mocks.VerifierMock{}
+			verifier.On("VerificationSequence").Return(uint64(1))
+			verifier.On("VerifyProposal", mock.Anything).Return(nil, nil)
+			verifier.On("VerifyConsenterSig", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+			verifier.On("VerifySignature", mock.Anything).Return(nil)
+			signer := &mocks.SignerMock{}
+			signer.On("SignProposal", mock.Anything).Return(&types.Signature{Id: 1, Value: []byte{4}})
+
+			view := &bft.View{
+				State:            &bft.StateRecorder{},
+				Logger:           log,
+				N:                4,
+				LeaderID:         1,
+				SelfID:           1,
+				Quorum:           3,
+				Number:           1,
+				ProposalSequence: 0,
+				Comm:             comm,
+				Decider:          decider,
+				Verifier:         verifier,
+				Signer:           signer,
+				FastPathTimeout:  testCase.fastPathTimeout,
+			}
+			view.Start()
+			defer view.Abort()
+
+			view.Propose(proposal)
+			for _, sender := range testCase.prepareSenders {
+				view.HandleMessage(sender, fastPathPrepare(sender))
+			}
+			for _, commit := range testCase.commits {
+				view.HandleMessage(commit.GetCommit().Signature.Signer, commit)
+			}
+
+			signatures := <-decided
+			var signers []uint64
+			for _, signature := range signatures {
+				signers = append(signers, signature.Id)
+			}
+			assert.ElementsMatch(t, testCase.expectedSignatures, signers)
+		})
+	}
+}
+
+func TestLinearCommunication(t *testing.T) {
+	basicLog, err := zap.NewDevelopment()
+	assert.NoError(t, err)
+	log := basicLog.Sugar()
+
+	signedPrepare := func(signer uint64) *protos.Signature {
+		return &protos.Signature{
+			Signer: signer,
+			Value:  []byte{5},
+			Msg:    bft.MarshalOrPanic(&protos.Prepare{View: 1, Seq: 0, Digest: digest}),
+		}
+	}
+	certificate := func(prepares, commits []*protos.Signature) *protos.Message {
+		return &protos.Message{
+			Content: &protos.Message_VoteCertificate{
+				VoteCertificate: &protos.VoteCertificate{
+					View:     1,
+					Seq:      0,
+					Digest:   digest,
+					Prepares: prepares,
+					Commits:  commits,
+				},
+			},
+		}
+	}
+
+	type sent struct {
+		target uint64 // zero if the message was broadcast
+		msg    *protos.Message
+	}
+
+	setup := func(selfID uint64, collectorTimeout time.Duration) (*bft.View, chan sent, chan []types.Signature) {
+		sentMsgs := make(chan sent, 100)
+		comm := &mocks.CommMock{}
+		comm.On("BroadcastConsensus", mock.Anything).Run(func(args mock.Arguments) {
+			sentMsgs <- sent{msg: args.Get(0).(*protos.Message)}
+		})
+		comm.On("SendConsensus", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			sentMsgs <- sent{target: args.Get(0).(uint64), msg: args.Get(1).(*protos.Message)}
+		})
+		decider := &mocks.Decider{}
+		decided := make(chan []types.Signature, 1)
+		decider.On("Decide", mock.Anything, mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			decided <- args.Get(1).([]types.Signature)
+		})
+		verifier := &mocks.VerifierMock{}
+		verifier.On("VerificationSequence").Return(uint64(1))
+		verifier.On("VerifyProposal", mock.Anything).Return(nil, nil)
+		verifier.On("VerifyConsenterSig", mock.Anything, mock.Anything, mock.Anything).Return(nil)
+		verifier.On("VerifySignature", mock.Anything).Return(nil)
+		signer := &mocks.SignerMock{}
+		signer.On("Sign", mock.Anything).Return([]byte{5})
+		signer.On("SignProposal", mock.Anything).Return(&types.Signature{Id: selfID, Value: []byte{4}})
+
+		view := &bft.View{
+			State:            &bft.StateRecorder{},
+			Logger:           log,
+			N:                4,
+			LeaderID:         1,
+			SelfID:           selfID,
+			Quorum:           3,
+			Number:           1,
+			ProposalSequence: 0,
+			Comm:             comm,
+			Decider:          decider,
+			Verifier:         verifier,
+			Signer:           signer,
+			CollectorTimeout: collectorTimeout,
+		}
+		return view, sentMsgs, decided
+	}
+
+	nextVote := func(sentMsgs chan sent) sent {
+		for s := range sentMsgs {
+			if s.msg.GetPrePrepare() == nil {
+				return s
+			}
+		}
+		return sent{}
+	}
+
+	t.Run("collector", func(t *testing.T) {
+		view, sentMsgs, decided := setup(1, time.Minute)
+		view.Start()
+		defer view.Abort()
+
+		view.Propose(proposal)
+		prepare2 := proto.Clone(prepare).(*protos.Message)
+		prepare2.GetPrepare().Signature = []byte{5}
+		view.HandleMessage(2, prepare2)
+		view.HandleMessage(3, prepare2)
+
+		cert := nextVote(sentMsgs)
+		assert.Zero(t, cert.target)
+		var signers []uint64
+		for _, sig := range cert.msg.GetVoteCertificate().Prepares {
+			signers = append(signers, sig.Signer)
+		}
+		assert.ElementsMatch(t, []uint64{1, 2, 3}, signers)
+
+		view.HandleMessage(2, commit2)
+		view.HandleMessage(3, commit3)
+
+		cert = nextVote(sentMsgs)
+		assert.Zero(t, cert.target)
+		assert.Len(t, cert.msg.GetVoteCertificate().Commits, 3)
+		assert.Len(t, <-decided, 3)
+	})
+
+	t.Run("follower", func(t *testing.T) {
+		view, sentMsgs, decided := setup(2, time.Minute)
+		view.Start()
+		defer view.Abort()
+
+		view.HandleMessage(1, prePrepare)
+		vote := nextVote(sentMsgs)
+		assert.Equal(t, uint64(1), vote.target)
+		assert.NotNil(t, vote.msg.GetPrepare())
+
+		view.HandleMessage(1, certificate([]*protos.Signature{signedPrepare(1), signedPrepare(3)}, nil))
+		vote = nextVote(sentMsgs)
+		assert.Equal(t, uint64(1), vote.target)
+		assert.NotNil(t, vote.msg.GetCommit())
+
+		view.HandleMessage(1, certificate(nil, []*protos.Signature{commit1.GetCommit().Signature, commit3.GetCommit().Signature}))
+		assert.Len(t, <-decided, 3)
+	})
+
+	t.Run("collector times out", func(t *testing.T) {
+		view, sentMsgs, decided := setup(2, 100*time.Millisecond)
+		view.Start()
+		defer view.Abort()
+
+		view.HandleMessage(1, prePrepare)
+		vote := nextVote(sentMsgs)
+		assert.Equal(t, uint64(1), vote.target)
+
+		// No certificate arrives, so the prepare is sent to all nodes
+		vote = nextVote(sentMsgs)
+		assert.Zero(t, vote.target)
+		assert.NotNil(t, vote.msg.GetPrepare())
+
+		// The nodes which fell back get our prepare, and we send our commit to all nodes
+		view.HandleMessage(1, prepare)
+		view.HandleMessage(3, prepare)
+		var assisted []uint64
+		for i := 0; i < 2; i++ {
+			vote = nextVote(sentMsgs)
+			assert.True(t, vote.msg.GetPrepare().Assist)
+			assisted = append(assisted, vote.target)
+		}
+		assert.ElementsMatch(t, []uint64{1, 3}, assisted)
+		vote = nextVote(sentMsgs)
+		assert.Zero(t, vote.target)
+		assert.NotNil(t, vote.msg.GetCommit())
+
+		view.HandleMessage(1, commit1)
+		view.HandleMessage(3, commit3)
+		assert.Len(t, <-decided, 3)
+	})
+}
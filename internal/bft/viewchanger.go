@@ -49,6 +49,13 @@ type ViewChanger struct {
 
 	Controller    ViewController
 	RequestsTimer RequestsTimer
+	Halter        Halter
+	Decisions     *DecisionHistory
+
+	LeaderRotation api.LeaderRotation
+	FaultModel     types.FaultModel
+	// If set, the voting weight of each node, and the quorum is a weight
+	Weights map[uint64]uint64
 
 	Ticker              <-chan time.Time
 	lastTick            time.Time
@@ -58,8 +65,11 @@ type ViewChanger struct {
 	startViewChangeTime time.Time
 	checkTimeout        bool
 
+	FlowControl      FlowControl
+	RejectionHandler api.MessageRejectionHandler
+
 	// Runtime
-	incMsgs         chan *incMsg
+	incMsgs         *Inbox
 	viewChangeMsgs  *voteSet
 	viewDataMsgs    *voteSet
 	currView        uint64
@@ -67,21 +77,26 @@ type ViewChanger struct {
 	leader          uint64
 	startChangeChan chan bool
 	informChan      chan uint64
+	// The blacklist of the last decision when the current view began, which selects the leaders of view changes,
+	// as nodes that changed to the current view by the same new view agree on it, even if their checkpoints differ
+	blacklist []uint64
 
 	stopOnce sync.Once
 	stopChan chan struct{}
 	vcDone   sync.WaitGroup
+
+	scopedLogger *scopedLogger
 }
 
 // Start the view changer
 func (v *ViewChanger) Start(startViewNumber uint64) {
-	v.incMsgs = make(chan *incMsg, 10*v.N) // TODO channel size should be configured
+	v.incMsgs = NewInbox(v.FlowControl, reportDroppedMessage(v.Logger, v.RejectionHandler))
 	v.startChangeChan = make(chan bool, 1)
 	v.informChan = make(chan uint64)
 
-	v.nodes = v.Comm.Nodes()
+	v.nodes = sortedNodes(v.Comm.Nodes())
 
-	v.quorum, v.f = computeQuorum(v.N)
+	v.quorum, v.f = computeWeightedQuorum(v.N, v.Weights, v.FaultModel)
 
 	v.stopChan = make(chan struct{})
 	v.stopOnce = sync.Once{}
@@ -91,8 +106,13 @@ func (v *ViewChanger) Start(startViewNumber uint64) {
 
 	// set without locking
 	v.currView = startViewNumber
+	if _, isStructured := v.Logger.(api.StructuredLogger); isStructured {
+		v.scopedLogger = newScopedLogger(v.Logger, "view", v.currView)
+		v.Logger = v.scopedLogger
+	}
 	v.nextView = v.currView
-	v.leader = getLeaderID(v.currView, v.N, v.nodes)
+	v.blacklist = blacklistOf(v.Checkpoint)
+	v.leader = v.leaderOf(v.currView)
 
 	v.lastTick = time.Now()
 	v.lastResend = v.lastTick
@@ -143,14 +163,24 @@ func (v *ViewChanger) Stop() {
 	v.vcDone.Wait()
 }
 
-// HandleMessage passes a message to the view changer
+// HandleMessage passes a message to the view changer, without blocking.
+// The message is dropped if the queue of its sender is full, or the view changer isn't started.
 func (v *ViewChanger) HandleMessage(sender uint64, m *protos.Message) {
-	msg := &incMsg{sender: sender, Message: m}
+	if v.incMsgs == nil {
+		WithFields(v.Logger, "sender", sender).Debugf("Dropping message, as the view changer isn't started")
+		return
+	}
 	select {
 	case <-v.stopChan:
 		return
-	case v.incMsgs <- msg:
+	default:
 	}
+	v.incMsgs.Put(sender, m)
+}
+
+// Drained returns a channel which is closed once the messages passed to the view changer so far are processed.
+func (v *ViewChanger) Drained() <-chan struct{} {
+	return v.incMsgs.Drained()
 }
 
 func (v *ViewChanger) run() {
@@ -160,8 +190,11 @@ func (v *ViewChanger) run() {
 			return
 		case stopView := <-v.startChangeChan:
 			v.startViewChange(stopView)
-		case msg := <-v.incMsgs:
-			v.processMsg(msg.sender, msg.Message)
+		case <-v.incMsgs.Ready():
+			if sender, m, ok := v.incMsgs.Get(); ok {
+				v.processMsg(sender, m)
+				v.incMsgs.Done()
+			}
 		case now := <-v.Ticker:
 			v.lastTick = now
 			v.checkIfResendViewChange(now)
@@ -189,7 +222,7 @@ func (v *ViewChanger) checkIfResendViewChange(now time.Time) {
 		v.Comm.BroadcastConsensus(msg)
 	}
 	v.lastResend = now // update last resend time
-	v.Logger.Debugf("Node %d resent a view change message with next view %d", v.SelfID, v.nextView)
+	v.Logger.Debugf("Resent a view change message with next view %d", v.nextView)
 }
 
 func (v *ViewChanger) checkIfTimeout(now time.Time) {
@@ -200,7 +233,7 @@ func (v *ViewChanger) checkIfTimeout(now time.Time) {
 	if nextTimeout.After(now) { // check if timeout has passed
 		return
 	}
-	v.Logger.Debugf("Node %d got a view change timeout", v.SelfID)
+	v.Logger.Debugf("Got a view change timeout")
 	v.checkTimeout = false // stop timeout for now, a new one will start when a new view change begins
 	// the timeout has passed, something went wrong, try sync and complain
 	v.Synchronizer.Sync()
@@ -208,23 +241,25 @@ func (v *ViewChanger) checkIfTimeout(now time.Time) {
 }
 
 func (v *ViewChanger) processMsg(sender uint64, m *protos.Message) {
+	logger := WithFields(v.Logger, "sender", sender)
 	// viewChange message
 	if vc := m.GetViewChange(); vc != nil {
-		v.Logger.Debugf("Node %d is processing a view change message from %d", v.SelfID, sender)
+		logger.Debugf("Processing a view change message")
 		// check view number
 		if vc.NextView != v.currView+1 { // accept view change only to immediate next view number
-			v.Logger.Warnf("Node %d got viewChange message %v from %d with view %d, expected view %d", v.SelfID, m, sender, vc.NextView, v.currView+1)
+			logger.Warnf("Got viewChange message %v with view %d, expected view %d", m, vc.NextView, v.currView+1)
 			return
 		}
+		prevWeight := v.viewChangeMsgs.weight(v.Weights)
 		v.viewChangeMsgs.registerVote(sender, m)
-		v.processViewChangeMsg()
+		v.processViewChangeMsg(prevWeight)
 		return
 	}
 
 	//viewData message
 	if vd := m.GetViewData(); vd != nil {
-		v.Logger.Debugf("Node %d is processing a view data message from %d", v.SelfID, sender)
-		if !v.validateViewDataMsg(vd, sender) {
+		logger.Debugf("Processing a view data message")
+		if !v.validateViewDataMsg(vd, sender, logger) {
 			return
 		}
 		v.viewDataMsgs.registerVote(sender, m)
@@ -234,9 +269,9 @@ func (v *ViewChanger) processMsg(sender uint64, m *protos.Message) {
 
 	// newView message
 	if nv := m.GetNewView(); nv != nil {
-		v.Logger.Debugf("Node %d is processing a new view message from %d", v.SelfID, sender)
+		logger.Debugf("Processing a new view message")
 		if sender != v.leader {
-			v.Logger.Warnf("Node %d got newView message %v from %d, expected sender to be %d the next leader", v.SelfID, m, sender, v.leader)
+			logger.Warnf("Got newView message %v, expected sender to be %d the next leader", m, v.leader)
 			return
 		}
 		v.processNewViewMsg(nv)
@@ -252,11 +287,20 @@ func (v *ViewChanger) InformNewView(view uint64) {
 	}
 }
 
+// scopeLogger scopes the entries with the current view.
+func (v *ViewChanger) scopeLogger() {
+	if v.scopedLogger != nil {
+		v.scopedLogger.scope("view", v.currView)
+	}
+}
+
 func (v *ViewChanger) informNewView(view uint64) {
-	v.Logger.Debugf("Node %d was informed of a new view %d", v.SelfID, view)
+	v.Logger.Debugf("Was informed of a new view %d", view)
 	v.currView = view
+	v.scopeLogger()
 	v.nextView = v.currView
-	v.leader = getLeaderID(v.currView, v.N, v.nodes)
+	v.blacklist = blacklistOf(v.Checkpoint)
+	v.leader = v.leaderOf(v.currView)
 	v.viewChangeMsgs.clear(v.N)
 	v.viewDataMsgs.clear(v.N)
 	v.checkTimeout = false
@@ -283,7 +327,7 @@ func (v *ViewChanger) startViewChange(stopView bool) {
 		},
 	}
 	v.Comm.BroadcastConsensus(msg)
-	v.Logger.Debugf("Node %d started view change, last view is %d", v.SelfID, v.currView)
+	v.Logger.Debugf("Started view change, last view is %d", v.currView)
 	if stopView {
 		v.Controller.AbortView() // abort the current view when joining view change
 	}
@@ -291,15 +335,18 @@ func (v *ViewChanger) startViewChange(stopView bool) {
 	v.checkTimeout = true
 }
 
-func (v *ViewChanger) processViewChangeMsg() {
-	if uint64(len(v.viewChangeMsgs.voted)) == uint64(v.f+1) { // join view change
-		v.Logger.Debugf("Node %d is joining view change, last view is %d", v.SelfID, v.currView)
+func (v *ViewChanger) processViewChangeMsg(prevWeight int) {
+	weight := v.viewChangeMsgs.weight(v.Weights)
+	threshold := trustThreshold(v.f, v.FaultModel)
+	if prevWeight < threshold && weight >= threshold { // join view change
+		v.Logger.Debugf("Joining view change, last view is %d", v.currView)
 		v.startViewChange(true)
 	}
 	// TODO add view change try timeout
-	if len(v.viewChangeMsgs.voted) >= v.quorum-1 && v.nextView > v.currView { // send view data
+	if weight+votingWeight(v.Weights, v.SelfID) >= v.quorum && v.nextView > v.currView { // send view data
 		v.currView = v.nextView
-		v.leader = getLeaderID(v.currView, v.N, v.nodes)
+		v.scopeLogger()
+		v.leader = v.leaderOf(v.currView)
 		v.RequestsTimer.RestartTimers()
 		v.viewChangeMsgs.clear(v.N)
 		v.viewDataMsgs.clear(v.N) // clear because currView changed
@@ -311,7 +358,7 @@ func (v *ViewChanger) processViewChangeMsg() {
 		} else {
 			v.Comm.SendConsensus(v.leader, msg)
 		}
-		v.Logger.Debugf("Node %d sent view data msg, with next view %d, to the new leader %d", v.SelfID, v.currView, v.leader)
+		v.Logger.Debugf("Sent view data msg to the new leader %d", v.leader)
 	}
 }
 
@@ -326,6 +373,9 @@ func (v *ViewChanger) prepareViewDataMsg() *protos.Message {
 		InFlightProposal:       inFlight,
 		InFlightPrepared:       prepared,
 	}
+	if inFlight != nil {
+		vd.InFlightSignature = v.InFlight.InFlightSignature()
+	}
 	vdBytes := MarshalOrPanic(vd)
 	sig := v.Signer.Sign(vdBytes)
 	msg := &protos.Message{
@@ -343,15 +393,15 @@ func (v *ViewChanger) prepareViewDataMsg() *protos.Message {
 func (v *ViewChanger) getInFlight(lastDecision *protos.Proposal) *protos.Proposal {
 	inFlight := v.InFlight.InFlightProposal()
 	if inFlight == nil {
-		v.Logger.Debugf("Node %d's in flight proposal is not set", v.SelfID)
+		v.Logger.Debugf("The in flight proposal is not set")
 		return nil
 	}
 	if inFlight.Metadata == nil {
-		v.Logger.Panicf("Node %d's in flight proposal metadata is not set", v.SelfID)
+		v.Logger.Panicf("The in flight proposal metadata is not set")
 	}
 	inFlightMetadata := &protos.ViewMetadata{}
 	if err := proto.Unmarshal(inFlight.Metadata, inFlightMetadata); err != nil {
-		v.Logger.Panicf("Node %d is unable to unmarshal its own in flight metadata, err: %v", v.SelfID, err)
+		v.Logger.Panicf("Unable to unmarshal our own in flight metadata, err: %v", err)
 	}
 	proposal := &protos.Proposal{
 		Header:               inFlight.Header,
@@ -360,60 +410,62 @@ func (v *ViewChanger) getInFlight(lastDecision *protos.Proposal) *protos.Proposa
 		VerificationSequence: uint64(inFlight.VerificationSequence),
 	}
 	if lastDecision == nil {
-		v.Logger.Panicf("Node %d's checkpoint is not set with the last decision", v.SelfID)
+		v.Logger.Panicf("The checkpoint is not set with the last decision")
 	}
 	if lastDecision.Metadata == nil {
 		return proposal // this is the first proposal after genesis
 	}
 	lastDecisionMetadata := &protos.ViewMetadata{}
 	if err := proto.Unmarshal(lastDecision.Metadata, lastDecisionMetadata); err != nil {
-		v.Logger.Panicf("Node %d is unable to unmarshal its own last decision metadata from checkpoint, err: %v", v.SelfID, err)
+		v.Logger.Panicf("Unable to unmarshal our own last decision metadata from checkpoint, err: %v", err)
 	}
 	if inFlightMetadata.LatestSequence == lastDecisionMetadata.LatestSequence {
-		v.Logger.Debugf("Node %d's in flight proposal and the last decision has the same sequence: %d", v.SelfID, inFlightMetadata.LatestSequence)
+		v.Logger.Debugf("The in flight proposal and the last decision have the same sequence: %d", inFlightMetadata.LatestSequence)
 		return nil // this is not an actual in flight proposal
 	}
 	if inFlightMetadata.LatestSequence != lastDecisionMetadata.LatestSequence+1 {
-		v.Logger.Panicf("Node %d's in flight proposal sequence is %d while its last decision sequence is %d", v.SelfID, inFlightMetadata.LatestSequence, lastDecisionMetadata.LatestSequence)
+		v.Logger.Panicf("The in flight proposal sequence is %d while the last decision sequence is %d", inFlightMetadata.LatestSequence, lastDecisionMetadata.LatestSequence)
 	}
 	return proposal
 }
 
-func (v *ViewChanger) validateViewDataMsg(vd *protos.SignedViewData, sender uint64) bool {
+func (v *ViewChanger) validateViewDataMsg(vd *protos.SignedViewData, sender uint64, logger api.Logger) bool {
 	if vd.Signer != sender {
-		v.Logger.Warnf("Node %d got viewData message %v from %d, but signer %d is not the sender %d", v.SelfID, vd, sender, vd.Signer, sender)
+		logger.Warnf("Got viewData message %v, but signer %d is not the sender", vd, vd.Signer)
 		return false
 	}
 	if err := v.Verifier.VerifySignature(types.Signature{Id: vd.Signer, Value: vd.Signature, Msg: vd.RawViewData}); err != nil {
-		v.Logger.Warnf("Node %d got viewData message %v from %d, but signature is invalid, error: %v", v.SelfID, vd, sender, err)
+		logger.Warnf("Got viewData message %v, but signature is invalid, error: %v", vd, err)
 		return false
 	}
 	rvd := &protos.ViewData{}
 	if err := proto.Unmarshal(vd.RawViewData, rvd); err != nil {
-		v.Logger.Errorf("Node %d was unable to unmarshal viewData message from %d, error: %v", v.SelfID, sender, err)
+		logger.Errorf("Unable to unmarshal viewData message, error: %v", err)
 		return false
 	}
 	if rvd.NextView != v.currView {
-		v.Logger.Warnf("Node %d got viewData message %v from %d, but %d is in view %d", v.SelfID, rvd, sender, v.SelfID, v.currView)
+		logger.Warnf("Got viewData message %v, but we are in view %d", rvd, v.currView)
 		return false
 	}
-	if getLeaderID(rvd.NextView, v.N, v.nodes) != v.SelfID { // check if I am the next leader
-		v.Logger.Warnf("Node %d got viewData message %v from %d, but %d is not the next leader", v.SelfID, rvd, sender, v.SelfID)
+	if v.leaderOf(rvd.NextView) != v.SelfID { // check if I am the next leader
+		logger.Warnf("Got viewData message %v, but we are not the next leader", rvd)
 		return false
 	}
-	err, lastSequence := ValidateLastDecision(rvd, v.quorum, v.N, v.Verifier)
+	err, lastSequence := ValidateLastDecision(rvd, v.quorum, v.N, consenterSigVerifier(v.Verifier, v.FaultModel), v.Weights)
 	if err != nil {
-		v.Logger.Warnf("Node %d got viewData message %v from %d, but the last decision is invalid, reason: %v", v.SelfID, rvd, sender, err)
+		logger.Warnf("Got viewData message %v, but the last decision is invalid, reason: %v", rvd, err)
 		return false
 	}
 	if err := ValidateInFlight(rvd.InFlightProposal, lastSequence); err != nil {
-		v.Logger.Warnf("Node %d got viewData message %v from %d, but the in flight proposal is invalid, reason: %v", v.SelfID, rvd, sender, err)
+		logger.Warnf("Got viewData message %v, but the in flight proposal is invalid, reason: %v", rvd, err)
 		return false
 	}
 	return true
 }
 
-func ValidateLastDecision(vd *protos.ViewData, quorum int, N uint64, verifier api.Verifier) (err error, lastSequence uint64) {
+// ValidateLastDecision validates the last decision in the view data is signed by a quorum.
+// If the nodes have voting weights, the quorum is a weight.
+func ValidateLastDecision(vd *protos.ViewData, quorum int, N uint64, verifier api.Verifier, weights map[uint64]uint64) (err error, lastSequence uint64) {
 	if vd.LastDecision == nil {
 		return errors.Errorf("the last decision is not set"), 0
 	}
@@ -428,35 +480,9 @@ func ValidateLastDecision(vd *protos.ViewData, quorum int, N uint64, verifier ap
 	if md.ViewId >= vd.NextView {
 		return errors.Errorf("last decision view %d is greater or equal to requested next view %d", md.ViewId, vd.NextView), 0
 	}
-	numSigs := len(vd.LastDecisionSignatures)
-	if numSigs < quorum {
-		return errors.Errorf("there are only %d last decision signatures", numSigs), 0
-	}
-	nodesMap := make(map[uint64]struct{}, N)
-	validSig := 0
-	for _, sig := range vd.LastDecisionSignatures {
-		if _, exist := nodesMap[sig.Signer]; exist {
-			continue // seen signature from this node already
-		}
-		nodesMap[sig.Signer] = struct{}{}
-		signature := types.Signature{
-			Id:    sig.Signer,
-			Value: sig.Value,
-			Msg:   sig.Msg,
-		}
-		proposal := types.Proposal{
-			Header:               vd.LastDecision.Header,
-			Payload:              vd.LastDecision.Payload,
-			Metadata:             vd.LastDecision.Metadata,
-			VerificationSequence: int64(vd.LastDecision.VerificationSequence),
-		}
-		if err := verifier.VerifyConsenterSig(signature, proposal); err != nil {
-			return errors.Errorf("last decision signature is invalid, error: %v", err), 0
-		}
-		validSig++
-	}
-	if validSig < quorum {
-		return errors.Errorf("there are only %d valid last decision signatures", validSig), 0
+	cert := certificateFromProtos(vd.LastDecision, vd.LastDecisionSignatures)
+	if err := verifyCertificate(cert, quorum, verifier, weights); err != nil {
+		return errors.Wrap(err, "invalid last decision"), 0
 	}
 	return nil, md.LatestSequence
 }
@@ -479,7 +505,7 @@ func ValidateInFlight(inFlightProposal *protos.Proposal, lastSequence uint64) er
 }
 
 func (v *ViewChanger) processViewDataMsg() {
-	if len(v.viewDataMsgs.voted) >= v.quorum { // need enough (quorum) data to continue
+	if v.viewDataMsgs.weight(v.Weights) >= v.quorum { // need enough (quorum) data to continue
 		signedMsgs := make([]*protos.SignedViewData, 0)
 		close(v.viewDataMsgs.votes)
 		for vote := range v.viewDataMsgs.votes {
@@ -495,7 +521,7 @@ func (v *ViewChanger) processViewDataMsg() {
 		v.Comm.BroadcastConsensus(msg)
 		v.processMsg(v.SelfID, msg) // also send to myself
 		v.viewDataMsgs.clear(v.N)
-		v.Logger.Debugf("Node %d sent a new view msg", v.SelfID)
+		v.Logger.Debugf("Sent a new view msg")
 	}
 }
 
@@ -506,6 +532,7 @@ func (v *ViewChanger) processNewViewMsg(msg *protos.NewView) {
 	var maxLastDecisionSequence uint64
 	var maxLastDecision *protos.Proposal
 	var maxLastDecisionSigs []*protos.Signature
+	var validViewData []*protos.SignedViewData
 	for _, svd := range signed {
 		if _, exist := nodesMap[svd.Signer]; exist {
 			continue // seen data from this node already
@@ -513,32 +540,38 @@ func (v *ViewChanger) processNewViewMsg(msg *protos.NewView) {
 		nodesMap[svd.Signer] = struct{}{}
 
 		if err := v.Verifier.VerifySignature(types.Signature{Id: svd.Signer, Value: svd.Signature, Msg: svd.RawViewData}); err != nil {
-			v.Logger.Warnf("Node %d is processing newView message, but signature of viewData %v is invalid, error: %v", v.SelfID, svd, err)
+			v.Logger.Warnf("Processing newView message, but signature of viewData %v is invalid, error: %v", svd, err)
 			continue
 		}
 
 		vd := &protos.ViewData{}
 		if err := proto.Unmarshal(svd.RawViewData, vd); err != nil {
-			v.Logger.Errorf("Node %d was unable to unmarshal viewData from the newView message, error: %v", v.SelfID, err)
+			v.Logger.Errorf("Unable to unmarshal viewData from the newView message, error: %v", err)
 			continue
 		}
 
 		if vd.NextView != v.currView {
-			v.Logger.Warnf("Node %d is processing newView message, but nextView of viewData %v is %d, while the currView is %d", v.SelfID, vd, vd.NextView, v.currView)
+			v.Logger.Warnf("Processing newView message, but nextView of viewData %v is %d, while the currView is %d", vd, vd.NextView, v.currView)
 			continue
 		}
 
-		err, lastSequence := ValidateLastDecision(vd, v.quorum, v.N, v.Verifier)
+		err, lastSequence := ValidateLastDecision(vd, v.quorum, v.N, consenterSigVerifier(v.Verifier, v.FaultModel), v.Weights)
 		if err != nil {
-			v.Logger.Warnf("Node %d is processing newView message, but the last decision in viewData %v is invalid, reason: %v", v.SelfID, vd, err)
+			v.Logger.Warnf("Processing newView message, but the last decision in viewData %v is invalid, reason: %v", vd, err)
 			continue
 		}
 
 		if err := ValidateInFlight(vd.InFlightProposal, lastSequence); err != nil {
-			v.Logger.Warnf("Node %d is processing newView message, but the in flight in viewData %v is invalid, reason: %v", v.SelfID, vd, err)
+			v.Logger.Warnf("Processing newView message, but the in flight in viewData %v is invalid, reason: %v", vd, err)
 			continue
 		}
 
+		if evidence := v.Decisions.Check(lastSequence, certificateFromProtos(vd.LastDecision, vd.LastDecisionSignatures)); evidence != nil {
+			v.Logger.Errorf("Processing newView message, but the last decision in viewData %v conflicts with a delivered decision", vd)
+			haltOrPanic(v.Halter, v.Logger, evidence)
+			return
+		}
+
 		v.Logger.Debugf("Current max sequence is %d and this viewData %v last decision sequence is %d", maxLastDecisionSequence, vd, lastSequence)
 		if lastSequence > maxLastDecisionSequence {
 			maxLastDecisionSequence = lastSequence
@@ -546,73 +579,173 @@ func (v *ViewChanger) processNewViewMsg(msg *protos.NewView) {
 			maxLastDecisionSigs = vd.LastDecisionSignatures
 		}
 
-		valid++
+		valid += votingWeight(v.Weights, svd.Signer)
+		validViewData = append(validViewData, svd)
 	}
 	if valid >= v.quorum {
-		// TODO handle in flight
+		if v.InFlight != nil {
+			adopted, err := v.adoptInFlight(validViewData, maxLastDecisionSequence)
+			if err != nil {
+				v.Logger.Warnf("Processing newView message, but %v", err)
+				return
+			}
+			v.InFlight.StoreAdopted(adopted)
+		}
 		v.Logger.Debugf("Changing to view %d with sequence %d and last decision %v", v.currView, maxLastDecisionSequence+1, maxLastDecision)
-		v.commitLastDecision(maxLastDecisionSequence, maxLastDecision, maxLastDecisionSigs)
+		if err := v.commitLastDecision(maxLastDecisionSequence, maxLastDecision, maxLastDecisionSigs); err != nil {
+			haltOrPanic(v.Halter, v.Logger, err)
+			return
+		}
+		v.blacklist = nil
+		if maxLastDecision != nil {
+			v.blacklist = metadataBlacklist(maxLastDecision.Metadata)
+		}
 		v.Controller.ViewChanged(v.currView, maxLastDecisionSequence+1)
 		v.checkTimeout = false
 	}
 }
 
-func (v *ViewChanger) commitLastDecision(lastDecisionSequence uint64, lastDecision *protos.Proposal, lastDecisionSigs []*protos.Signature) {
-	myLastDecision, _ := v.Checkpoint.Get()
-	if lastDecisionSequence == 0 {
-		return
+// leaderOf returns the leader of the given view, which collects the view data of the view change to it.
+func (v *ViewChanger) leaderOf(view uint64) uint64 {
+	return getLeaderID(view, v.LeaderRotation, v.nodes, v.blacklist)
+}
+
+// adoptInFlight returns the in flight proposal following the last decision which might have been committed,
+// or nil if there is none.
+// A proposal committed on the fast path was signed in prepares by all the nodes, so more than f of the nodes whose
+// view data is in the new view report it along with their signature, and no other proposal with its sequence is
+// signed by more than f nodes in later views.
+// A proposal committed on the slow path was prepared by a quorum, so it is adopted if it is held by more than f of
+// the nodes, and a quorum of the nodes either prepared it or didn't prepare any proposal. If no proposal is adopted,
+// a quorum of the nodes must not have prepared an in flight proposal, otherwise the new view can't tell whether
+// one was committed, and an error is returned.
+func (v *ViewChanger) adoptInFlight(validViewData []*protos.SignedViewData, lastSequence uint64) (*types.Proposal, error) {
+	signers := make(map[string][]uint64)
+	holders := make(map[string][]uint64)
+	preparers := make(map[string][]uint64)
+	proposals := make(map[string]*types.Proposal)
+	var notPrepared []uint64
+	for _, svd := range validViewData {
+		vd := &protos.ViewData{}
+		if err := proto.Unmarshal(svd.RawViewData, vd); err != nil {
+			continue
+		}
+		if vd.InFlightProposal == nil || ValidateInFlight(vd.InFlightProposal, lastSequence) != nil {
+			notPrepared = append(notPrepared, svd.Signer)
+			continue
+		}
+		proposal := types.Proposal{
+			Header:               vd.InFlightProposal.Header,
+			Payload:              vd.InFlightProposal.Payload,
+			Metadata:             vd.InFlightProposal.Metadata,
+			VerificationSequence: int64(vd.InFlightProposal.VerificationSequence),
+		}
+		digest := proposal.Digest()
+		proposals[digest] = &proposal
+		holders[digest] = append(holders[digest], svd.Signer)
+		if vd.InFlightPrepared {
+			preparers[digest] = append(preparers[digest], svd.Signer)
+		} else {
+			notPrepared = append(notPrepared, svd.Signer)
+		}
+
+		sig := vd.InFlightSignature
+		if sig == nil || sig.Signer != svd.Signer {
+			continue
+		}
+		signature := types.Signature{Id: sig.Signer, Value: sig.Value, Msg: sig.Msg}
+		if err := consenterSigVerifier(v.Verifier, v.FaultModel).VerifyConsenterSig(signature, proposal); err != nil {
+			v.Logger.Warnf("Node %d's signature on its in flight proposal is invalid: %v", svd.Signer, err)
+			continue
+		}
+		signers[digest] = append(signers[digest], svd.Signer)
+	}
+
+	// If several proposals are signed by more than f nodes, none of them was committed on the fast path,
+	// so any of them can be adopted, as long as all nodes adopt the same one.
+	if adopted := v.mostSupported(signers); adopted != "" {
+		v.Logger.Infof("Adopting the in flight proposal with sequence %d signed by %v", lastSequence+1, signers[adopted])
+		return proposals[adopted], nil
+	}
+
+	// A quorum doesn't argue with a proposal if its nodes either prepared it or didn't prepare any proposal.
+	candidates := make(map[string][]uint64)
+	for digest, nodes := range preparers {
+		if votingWeight(v.Weights, append(notPrepared, nodes...)...) >= v.quorum {
+			candidates[digest] = holders[digest]
+		}
+	}
+	if adopted := v.mostSupported(candidates); adopted != "" {
+		v.Logger.Infof("Adopting the in flight proposal with sequence %d prepared by %v", lastSequence+1, preparers[adopted])
+		return proposals[adopted], nil
+	}
+
+	if votingWeight(v.Weights, notPrepared...) < v.quorum {
+		return nil, errors.Errorf("the in flight proposals with sequence %d can neither be adopted nor ignored", lastSequence+1)
 	}
-	proposal := types.Proposal{
-		Header:               lastDecision.Header,
-		Metadata:             lastDecision.Metadata,
-		Payload:              lastDecision.Payload,
-		VerificationSequence: int64(lastDecision.VerificationSequence),
-	}
-	signatures := make([]types.Signature, 0)
-	for _, sig := range lastDecisionSigs {
-		signature := types.Signature{
-			Id:    sig.Signer,
-			Value: sig.Value,
-			Msg:   sig.Msg,
+	return nil, nil
+}
+
+// mostSupported returns the digest of the proposal whose supporters weigh the most, if they weigh more than f,
+// or an empty string if there is none. Ties are broken by the digest, so all nodes pick the same proposal.
+func (v *ViewChanger) mostSupported(supporters map[string][]uint64) string {
+	var adopted string
+	for digest, nodes := range supporters {
+		weight := votingWeight(v.Weights, nodes...)
+		if weight <= v.f {
+			continue
+		}
+		if adopted == "" || weight > votingWeight(v.Weights, supporters[adopted]...) ||
+			(weight == votingWeight(v.Weights, supporters[adopted]...) && digest < adopted) {
+			adopted = digest
 		}
-		signatures = append(signatures, signature)
 	}
+	return adopted
+}
+
+func (v *ViewChanger) commitLastDecision(lastDecisionSequence uint64, lastDecision *protos.Proposal, lastDecisionSigs []*protos.Signature) error {
+	myLastDecision, _ := v.Checkpoint.Get()
+	if lastDecisionSequence == 0 {
+		return nil
+	}
+	cert := certificateFromProtos(lastDecision, lastDecisionSigs)
 	if myLastDecision.Metadata == nil { // I am at genesis proposal
 		if lastDecisionSequence == 1 { // and one decision behind
-			v.deliverDecision(proposal, signatures)
-			return
+			return v.deliverDecision(lastDecisionSequence, cert)
 		}
 		v.Synchronizer.Sync()
-		return
+		return nil
 	}
 	md := &protos.ViewMetadata{}
 	if err := proto.Unmarshal(myLastDecision.Metadata, md); err != nil {
-		v.Logger.Panicf("Node %d is unable to unmarshal its own last decision metadata from checkpoint, err: %v", v.SelfID, err)
+		return errors.Wrap(err, "unable to unmarshal the last decision metadata from the checkpoint")
 	}
 	if md.LatestSequence == lastDecisionSequence-1 { // I am one decision behind
-		v.deliverDecision(proposal, signatures)
-		return
+		return v.deliverDecision(lastDecisionSequence, cert)
 	}
 	if md.LatestSequence < lastDecisionSequence { // I am far behind
 		v.Synchronizer.Sync()
-		return
+		return nil
 	}
 	if md.LatestSequence > lastDecisionSequence+1 {
-		v.Logger.Panicf("Node %d has a checkpoint for sequence %d which is much greater than the last decision sequence %d", v.SelfID, md.LatestSequence, lastDecisionSequence)
+		return errors.Errorf("checkpoint is for sequence %d which is much greater than the last decision sequence %d", md.LatestSequence, lastDecisionSequence)
 	}
+	return nil
 }
 
-func (v *ViewChanger) deliverDecision(proposal types.Proposal, signatures []types.Signature) {
-	v.Logger.Debugf("Delivering to app the last decision proposal %v", proposal)
-	v.Application.Deliver(proposal, signatures)
-	v.Checkpoint.Set(proposal, signatures)
-	requests, err := v.Verifier.VerifyProposal(proposal)
+func (v *ViewChanger) deliverDecision(seq uint64, cert types.DecisionCertificate) error {
+	v.Logger.Debugf("Delivering to app the last decision proposal %v", cert.Proposal)
+	v.Application.Deliver(cert.Proposal, cert.Signatures)
+	v.Checkpoint.Set(cert.Proposal, cert.Signatures)
+	v.Decisions.Record(seq, cert)
+	requests, err := v.Verifier.VerifyProposal(cert.Proposal)
 	if err != nil {
-		v.Logger.Panicf("Node %d is unable to verify the last decision proposal, err: %v", v.SelfID, err)
+		return errors.Wrap(err, "unable to verify the last decision proposal")
 	}
 	for _, reqInfo := range requests {
 		if err := v.RequestsTimer.RemoveRequest(reqInfo); err != nil {
 			v.Logger.Warnf("Error during remove of request %s from the pool, err: %v", reqInfo, err)
 		}
 	}
+	return nil
 }
@@ -33,6 +33,7 @@ var (
 		},
 	}
 	metadata = bft.MarshalOrPanic(&protos.ViewMetadata{
+		Version:        bft.MetadataVersion,
 		LatestSequence: 1,
 		ViewId:         0,
 	})
@@ -76,9 +77,13 @@ func TestViewChangerBasic(t *testing.T) {
 		N:      4,
 		Comm:   comm,
 		Ticker: make(chan time.Time),
+		Logger: zap.NewNop().Sugar(),
 	}
 
+	// Messages passed before the view changer is started are dropped
+	vc.HandleMessage(1, viewChangeMsg)
 	vc.Start(0)
+	<-vc.Drained()
 
 	vc.Stop()
 	vc.Stop()
@@ -360,6 +365,132 @@ func TestNewViewProcess(t *testing.T) {
 	vc.Stop()
 }
 
+func TestNewViewAdoptsPreparedInFlight(t *testing.T) {
+	inFlightProposal := func(payload byte) *protos.Proposal {
+		return &protos.Proposal{
+			Header:  []byte{0},
+			Payload: []byte{payload},
+			Metadata: bft.MarshalOrPanic(&protos.ViewMetadata{
+				Version:        bft.MetadataVersion,
+				LatestSequence: 2,
+				ViewId:         1,
+			}),
+			VerificationSequence: 1,
+		}
+	}
+	type inFlight struct {
+		proposal *protos.Proposal
+		prepared bool
+	}
+
+	for _, test := range []struct {
+		description string
+		inFlight    []inFlight
+		adopted     *protos.Proposal
+		rejected    bool
+	}{
+		{
+			description: "prepared by a quorum",
+			inFlight:    []inFlight{{inFlightProposal(1), true}, {inFlightProposal(1), true}, {inFlightProposal(1), true}},
+			adopted:     inFlightProposal(1),
+		},
+		{
+			description: "prepared by one and held by another",
+			inFlight:    []inFlight{{inFlightProposal(1), true}, {inFlightProposal(1), false}, {}},
+			adopted:     inFlightProposal(1),
+		},
+		{
+			description: "not prepared",
+			inFlight:    []inFlight{{inFlightProposal(1), false}, {inFlightProposal(2), false}, {}},
+		},
+		{
+			description: "prepared by one only",
+			inFlight:    []inFlight{{inFlightProposal(1), true}, {}, {}},
+			rejected:    true,
+		},
+		{
+			description: "different proposals prepared",
+			inFlight:    []inFlight{{inFlightProposal(1), true}, {inFlightProposal(2), true}, {}},
+			rejected:    true,
+		},
+	} {
+		t.Run(test.description, func(t *testing.T) {
+			basicLog, err := zap.NewDevelopment()
+			assert.NoError(t, err)
+			rejected := make(chan struct{}, 1)
+			log := basicLog.WithOptions(zap.Hooks(func(entry zapcore.Entry) error {
+				if strings.Contains(entry.Message, "can neither be adopted nor ignored") {
+					rejected <- struct{}{}
+				}
+				return nil
+			})).Sugar()
+			comm := &mocks.CommMock{}
+			comm.On("Nodes").Return([]uint64{0, 1, 2, 3})
+			verifier := &mocks.VerifierMock{}
+			verifier.On("VerifySignature", mock.Anything).Return(nil)
+			verifier.On("VerifyConsenterSig", mock.Anything, mock.Anything).Return(nil)
+			viewChanged := make(chan struct{}, 1)
+			controller := &mocks.ViewController{}
+			controller.On("ViewChanged", uint64(2), uint64(2)).Run(func(args mock.Arguments) {
+				viewChanged <- struct{}{}
+			})
+			checkpoint := types.Checkpoint{}
+			checkpoint.Set(lastDecision, lastDecisionSignatures)
+			inFlightData := &bft.InFlightData{}
+
+			vc := &bft.ViewChanger{
+				SelfID:     0,
+				N:          4,
+				Comm:       comm,
+				Logger:     log,
+				Verifier:   verifier,
+				Controller: controller,
+				Ticker:     make(chan time.Time),
+				Checkpoint: &checkpoint,
+				InFlight:   inFlightData,
+			}
+
+			vc.Start(2)
+
+			var signed []*protos.SignedViewData
+			for i, inFlight := range test.inFlight {
+				vd2 := proto.Clone(vd).(*protos.ViewData)
+				vd2.NextView = 2
+				vd2.InFlightProposal = inFlight.proposal
+				vd2.InFlightPrepared = inFlight.prepared
+				signed = append(signed, &protos.SignedViewData{
+					RawViewData: bft.MarshalOrPanic(vd2),
+					Signer:      uint64(i),
+				})
+			}
+			vc.HandleMessage(2, &protos.Message{
+				Content: &protos.Message_NewView{
+					NewView: &protos.NewView{
+						SignedViewData: signed,
+					},
+				},
+			})
+
+			if test.rejected {
+				<-rejected
+				vc.Stop()
+				controller.AssertNotCalled(t, "ViewChanged", mock.Anything, mock.Anything)
+				return
+			}
+
+			<-viewChanged
+			vc.Stop()
+			adopted := inFlightData.Adopted(2)
+			if test.adopted == nil {
+				assert.Nil(t, adopted)
+				return
+			}
+			assert.NotNil(t, adopted)
+			assert.Equal(t, test.adopted.Payload, adopted.Payload)
+		})
+	}
+}
+
 func TestNormalProcess(t *testing.T) {
 	// Test a full view change process
 
@@ -461,7 +592,7 @@ func TestBadViewDataMessage(t *testing.T) {
 		},
 		{
 			description:           "wrong view",
-			expectedMessageLogged: "is in view",
+			expectedMessageLogged: "but we are in view",
 			mutateViewData: func(m *protos.Message) {
 				vd := &protos.ViewData{
 					NextView: 10,
@@ -474,7 +605,7 @@ func TestBadViewDataMessage(t *testing.T) {
 		},
 		{
 			description:           "wrong leader",
-			expectedMessageLogged: "is not the next leader",
+			expectedMessageLogged: "but we are not the next leader",
 			mutateViewData: func(m *protos.Message) {
 			},
 			mutateVerifySig: func(verifierMock *mocks.VerifierMock) {
@@ -695,6 +826,7 @@ func TestCommitLastDecision(t *testing.T) {
 
 	nextViewData := proto.Clone(vd).(*protos.ViewData)
 	nextViewData.LastDecision.Metadata = bft.MarshalOrPanic(&protos.ViewMetadata{
+		Version:        bft.MetadataVersion,
 		LatestSequence: 2,
 		ViewId:         0,
 	})
@@ -721,6 +853,271 @@ func TestCommitLastDecision(t *testing.T) {
 
 }
 
+func TestViewChangeLeaderByNewViewBlacklist(t *testing.T) {
+	comm := &mocks.CommMock{}
+	comm.On("Nodes").Return([]uint64{0, 1, 2, 3})
+	msgChan := make(chan *protos.Message)
+	comm.On("BroadcastConsensus", mock.Anything).Run(func(args mock.Arguments) {
+		msgChan <- args.Get(0).(*protos.Message)
+	})
+	sentTo := make(chan uint64)
+	comm.On("SendConsensus", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		sentTo <- args.Get(0).(uint64)
+	})
+	basicLog, err := zap.NewDevelopment()
+	assert.NoError(t, err)
+	log := basicLog.Sugar()
+	signer := &mocks.SignerMock{}
+	signer.On("Sign", mock.Anything).Return([]byte{1, 2, 3})
+	verifier := &mocks.VerifierMock{}
+	verifier.On("VerifySignature", mock.Anything).Return(nil)
+	verifier.On("VerifyConsenterSig", mock.Anything, mock.Anything).Return(nil)
+	verifier.On("VerifyProposal", mock.Anything, mock.Anything).Return(nil, nil)
+	controller := &mocks.ViewController{}
+	viewChanged := make(chan uint64)
+	controller.On("ViewChanged", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		viewChanged <- args.Get(0).(uint64)
+	}).Return(nil).Once()
+	controller.On("AbortView")
+	reqTimer := &mocks.RequestsTimer{}
+	reqTimer.On("StopTimers")
+	reqTimer.On("RestartTimers")
+	checkpoint := types.Checkpoint{}
+	checkpoint.Set(lastDecision, lastDecisionSignatures)
+	app := &mocks.ApplicationMock{}
+	app.On("Deliver", mock.Anything, mock.Anything)
+
+	vc := &bft.ViewChanger{
+		SelfID:        1,
+		N:             4,
+		Comm:          comm,
+		Logger:        log,
+		Verifier:      verifier,
+		Controller:    controller,
+		Signer:        signer,
+		RequestsTimer: reqTimer,
+		Ticker:        make(chan time.Time),
+		InFlight:      &bft.InFlightData{},
+		Checkpoint:    &checkpoint,
+		Application:   app,
+	}
+
+	vc.Start(0)
+
+	vc.HandleMessage(2, viewChangeMsg)
+	vc.HandleMessage(3, viewChangeMsg)
+	m := <-msgChan
+	assert.NotNil(t, m.GetViewChange())
+
+	// The last decision in the new view blacklists node 2, but our checkpoint isn't updated by delivering it
+	nextViewData := proto.Clone(vd).(*protos.ViewData)
+	nextViewData.LastDecision.Metadata = bft.MarshalOrPanic(&protos.ViewMetadata{
+		Version:        bft.MetadataVersion,
+		LatestSequence: 2,
+		ViewId:         0,
+		BlackList:      []uint64{2},
+	})
+	viewData := proto.Clone(viewDataMsg1).(*protos.Message)
+	viewData.GetViewData().RawViewData = bft.MarshalOrPanic(nextViewData)
+	vc.HandleMessage(0, viewData)
+	msg2 := proto.Clone(viewData).(*protos.Message)
+	msg2.GetViewData().Signer = 2
+	vc.HandleMessage(2, msg2)
+	m = <-msgChan
+	assert.NotNil(t, m.GetNewView())
+	assert.Equal(t, uint64(1), <-viewChanged)
+
+	// The view data of the next view change is sent to node 3, as node 2 is blacklisted by the new view
+	nextViewChange := proto.Clone(viewChangeMsg).(*protos.Message)
+	nextViewChange.GetViewChange().NextView = 2
+	vc.HandleMessage(0, nextViewChange)
+	vc.HandleMessage(3, nextViewChange)
+	m = <-msgChan
+	assert.NotNil(t, m.GetViewChange())
+	assert.Equal(t, uint64(3), <-sentTo)
+
+	vc.Stop()
+}
+
+func TestCommitLastDecisionHalts(t *testing.T) {
+	comm := &mocks.CommMock{}
+	comm.On("Nodes").Return([]uint64{0, 1, 2, 3})
+	msgChan := make(chan *protos.Message)
+	comm.On("BroadcastConsensus", mock.Anything).Run(func(args mock.Arguments) {
+		m := args.Get(0).(*protos.Message)
+		msgChan <- m
+	})
+	basicLog, err := zap.NewDevelopment()
+	assert.NoError(t, err)
+	log := basicLog.Sugar()
+	signer := &mocks.SignerMock{}
+	signer.On("Sign", mock.Anything).Return([]byte{1, 2, 3})
+	verifier := &mocks.VerifierMock{}
+	verifier.On("VerifySignature", mock.Anything).Return(nil)
+	verifier.On("VerifyConsenterSig", mock.Anything, mock.Anything).Return(nil)
+	verifier.On("VerifyProposal", mock.Anything).Return(nil, errors.New("bad proposal"))
+	controller := &mocks.ViewController{}
+	controller.On("AbortView")
+	reqTimer := &mocks.RequestsTimer{}
+	reqTimer.On("StopTimers")
+	reqTimer.On("RestartTimers")
+	checkpoint := types.Checkpoint{}
+	checkpoint.Set(lastDecision, lastDecisionSignatures)
+	app := &mocks.ApplicationMock{}
+	app.On("Deliver", mock.Anything, mock.Anything)
+	halted := make(chan error, 1)
+	halter := &mocks.Halter{}
+	halter.On("Halt", mock.Anything).Run(func(args mock.Arguments) {
+		halted <- args.Get(0).(error)
+	})
+
+	vc := &bft.ViewChanger{
+		SelfID:        1,
+		N:             4,
+		Comm:          comm,
+		Logger:        log,
+		Verifier:      verifier,
+		Controller:    controller,
+		Signer:        signer,
+		RequestsTimer: reqTimer,
+		Halter:        halter,
+		Ticker:        make(chan time.Time),
+		InFlight:      &bft.InFlightData{},
+		Checkpoint:    &checkpoint,
+		Application:   app,
+	}
+
+	vc.Start(0)
+
+	vc.HandleMessage(2, viewChangeMsg)
+	vc.HandleMessage(3, viewChangeMsg)
+	m := <-msgChan
+	assert.NotNil(t, m.GetViewChange())
+
+	nextViewData := proto.Clone(vd).(*protos.ViewData)
+	nextViewData.LastDecision.Metadata = bft.MarshalOrPanic(&protos.ViewMetadata{
+		Version:        bft.MetadataVersion,
+		LatestSequence: 2,
+		ViewId:         0,
+	})
+	nextViewDateBytes := bft.MarshalOrPanic(nextViewData)
+	viewData := proto.Clone(viewDataMsg1).(*protos.Message)
+	viewData.GetViewData().RawViewData = nextViewDateBytes
+
+	vc.HandleMessage(0, viewData)
+	msg2 := proto.Clone(viewData).(*protos.Message)
+	msg2.GetViewData().Signer = 2
+	vc.HandleMessage(2, msg2)
+	m = <-msgChan
+	assert.NotNil(t, m.GetNewView())
+
+	err = <-halted
+	assert.EqualError(t, err, "unable to verify the last decision proposal: bad proposal")
+
+	vc.Stop()
+
+	app.AssertNumberOfCalls(t, "Deliver", 1)
+	controller.AssertNotCalled(t, "ViewChanged", mock.Anything, mock.Anything)
+}
+
+func TestNewViewWithConflictingLastDecision(t *testing.T) {
+	comm := &mocks.CommMock{}
+	comm.On("Nodes").Return([]uint64{0, 1, 2, 3})
+	msgChan := make(chan *protos.Message)
+	comm.On("BroadcastConsensus", mock.Anything).Run(func(args mock.Arguments) {
+		m := args.Get(0).(*protos.Message)
+		msgChan <- m
+	})
+	basicLog, err := zap.NewDevelopment()
+	assert.NoError(t, err)
+	log := basicLog.Sugar()
+	signer := &mocks.SignerMock{}
+	signer.On("Sign", mock.Anything).Return([]byte{1, 2, 3})
+	verifier := &mocks.VerifierMock{}
+	verifier.On("VerifySignature", mock.Anything).Return(nil)
+	verifier.On("VerifyConsenterSig", mock.Anything, mock.Anything).Return(nil)
+	controller := &mocks.ViewController{}
+	controller.On("AbortView")
+	reqTimer := &mocks.RequestsTimer{}
+	reqTimer.On("StopTimers")
+	reqTimer.On("RestartTimers")
+	checkpoint := types.Checkpoint{}
+	checkpoint.Set(lastDecision, lastDecisionSignatures)
+	app := &mocks.ApplicationMock{}
+	halted := make(chan error, 1)
+	halter := &mocks.Halter{}
+	halter.On("Halt", mock.Anything).Run(func(args mock.Arguments) {
+		halted <- args.Get(0).(error)
+	})
+
+	// This node delivered a different proposal at sequence 2, signed by nodes 1, 2 and 3
+	delivered := types.DecisionCertificate{
+		Proposal: types.Proposal{Payload: []byte{42}},
+		Signatures: []types.Signature{
+			{Id: 1, Value: []byte{4}},
+			{Id: 2, Value: []byte{4}},
+			{Id: 3, Value: []byte{4}},
+		},
+	}
+	decisions := bft.NewDecisionHistory(0)
+	decisions.Record(2, delivered)
+
+	vc := &bft.ViewChanger{
+		SelfID:        1,
+		N:             4,
+		Comm:          comm,
+		Logger:        log,
+		Verifier:      verifier,
+		Controller:    controller,
+		Signer:        signer,
+		RequestsTimer: reqTimer,
+		Halter:        halter,
+		Decisions:     decisions,
+		Ticker:        make(chan time.Time),
+		InFlight:      &bft.InFlightData{},
+		Checkpoint:    &checkpoint,
+		Application:   app,
+	}
+
+	vc.Start(0)
+
+	vc.HandleMessage(2, viewChangeMsg)
+	vc.HandleMessage(3, viewChangeMsg)
+	m := <-msgChan
+	assert.NotNil(t, m.GetViewChange())
+
+	nextViewData := proto.Clone(vd).(*protos.ViewData)
+	nextViewData.LastDecision.Metadata = bft.MarshalOrPanic(&protos.ViewMetadata{
+		Version:        bft.MetadataVersion,
+		LatestSequence: 2,
+		ViewId:         0,
+	})
+	nextViewDateBytes := bft.MarshalOrPanic(nextViewData)
+	viewData := proto.Clone(viewDataMsg1).(*protos.Message)
+	viewData.GetViewData().RawViewData = nextViewDateBytes
+
+	vc.HandleMessage(0, viewData)
+	msg2 := proto.Clone(viewData).(*protos.Message)
+	msg2.GetViewData().Signer = 2
+	vc.HandleMessage(2, msg2)
+	m = <-msgChan
+	assert.NotNil(t, m.GetNewView())
+
+	err = <-halted
+	vc.Stop()
+
+	evidence, isEvidence := err.(*types.ForkEvidence)
+	assert.True(t, isEvidence)
+	assert.Equal(t, uint64(2), evidence.Sequence)
+	assert.Equal(t, delivered, evidence.Delivered)
+	assert.Equal(t, nextViewData.LastDecision.Metadata, evidence.Conflicting.Proposal.Metadata)
+	assert.Equal(t, lastDecisionSignatures, evidence.Conflicting.Signatures)
+	assert.Equal(t, []uint64{1, 2}, evidence.Signers)
+
+	app.AssertNotCalled(t, "Deliver", mock.Anything, mock.Anything)
+	controller.AssertNotCalled(t, "ViewChanged", mock.Anything, mock.Anything)
+}
+
 func TestInFlightProposalInViewData(t *testing.T) {
 
 	for _, test := range []struct {
@@ -750,6 +1147,7 @@ func TestInFlightProposalInViewData(t *testing.T) {
 				inFlight := &bft.InFlightData{}
 				proposal := lastDecision
 				proposal.Metadata = bft.MarshalOrPanic(&protos.ViewMetadata{
+					Version:        bft.MetadataVersion,
 					LatestSequence: 2,
 					ViewId:         0,
 				})
@@ -862,6 +1260,7 @@ func TestValidateLastDecision(t *testing.T) {
 				NextView: 1,
 				LastDecision: &protos.Proposal{
 					Metadata: bft.MarshalOrPanic(&protos.ViewMetadata{
+						Version:        bft.MetadataVersion,
 						LatestSequence: 1,
 						ViewId:         1,
 					}),
@@ -924,7 +1323,7 @@ func TestValidateLastDecision(t *testing.T) {
 		t.Run(test.description, func(t *testing.T) {
 			verifier := &mocks.VerifierMock{}
 			test.mutateVerify(verifier)
-			err, seq := bft.ValidateLastDecision(test.viewData, 3, 4, verifier)
+			err, seq := bft.ValidateLastDecision(test.viewData, 3, 4, verifier, nil)
 			if test.valid {
 				assert.NoError(t, err)
 			} else {
@@ -6,6 +6,8 @@
 package api
 
 import (
+	"time"
+
 	bft "github.com/SmartBFT-Go/consensus/pkg/types"
 	protos "github.com/SmartBFT-Go/consensus/smartbftprotos"
 )
@@ -14,6 +16,26 @@ type Application interface {
 	Deliver(proposal bft.Proposal, signature []bft.Signature)
 }
 
+// SpeculativeApplication is an Application which executes proposals once they are prepared,
+// ahead of their delivery, to hide the latency of their execution.
+//
+// Once the node prepares a proposal, it is passed to Prepared along with its sequence.
+// Afterwards, either the proposal is passed to Deliver, which confirms its execution,
+// or Rollback is invoked with its sequence, which means the proposal is not going to be delivered
+// and its execution must be discarded. The latter happens once a different proposal is prepared
+// or delivered with that sequence, or the node synchronizes to that sequence or beyond it.
+// A view change alone doesn't roll back a prepared proposal, as the next view may still commit it.
+// Executions of prepared proposals are not tracked across restarts, and should be discarded when the node starts.
+// Prepared and Rollback are invoked in order on a goroutine other than the one Deliver is invoked on, but always before
+// the Deliver that follows them. Prepared may block to execute the proposal, which delays the delivery that follows it.
+type SpeculativeApplication interface {
+	Application
+	// Prepared is invoked once the proposal with the given sequence is prepared.
+	Prepared(proposal bft.Proposal, seq uint64)
+	// Rollback is invoked once the proposal prepared with the given sequence is not going to be delivered.
+	Rollback(seq uint64)
+}
+
 type Comm interface {
 	SendConsensus(targetID uint64, m *protos.Message)
 	SendTransaction(targetID uint64, request []byte)
@@ -34,6 +56,8 @@ type Signer interface {
 }
 
 type Verifier interface {
+	// VerifyProposal returns the requests of the proposal in the order they appear in its payload,
+	// as followers assemble the proposal from its requests in this order when requests are disseminated.
 	VerifyProposal(proposal bft.Proposal) ([]bft.RequestInfo, error)
 	VerifyRequest(val []byte) (bft.RequestInfo, error)
 	VerifyConsenterSig(signature bft.Signature, prop bft.Proposal) error
@@ -41,6 +65,17 @@ type Verifier interface {
 	VerificationSequence() uint64
 }
 
+// BatchVerifier is a Verifier which verifies many signatures or requests at once,
+// which is cheaper than verifying them one by one with some signature schemes.
+// If the Verifier is a BatchVerifier, signatures on commits and requests are verified in batches.
+type BatchVerifier interface {
+	Verifier
+	// VerifyConsenterSigs returns the error of verifying each of the signatures, nil for valid signatures.
+	VerifyConsenterSigs(signatures []bft.Signature, prop bft.Proposal) []error
+	// VerifyRequests returns the info of each of the requests and the error of verifying it, nil for valid requests.
+	VerifyRequests(requests [][]byte) ([]bft.RequestInfo, []error)
+}
+
 type RequestInspector interface {
 	RequestID(req []byte) bft.RequestInfo
 }
@@ -49,6 +84,14 @@ type Synchronizer interface {
 	Sync() (protos.ViewMetadata, uint64)
 }
 
+// DecisionSynchronizer is a Synchronizer which exposes the last decision it synchronized to,
+// so that the decision can be checked against the decisions the node delivered before.
+type DecisionSynchronizer interface {
+	Synchronizer
+	// LastSyncedDecision returns the proposal and signatures of the decision the last Sync synchronized to.
+	LastSyncedDecision() (bft.Proposal, []bft.Signature)
+}
+
 type Logger interface {
 	Debugf(template string, args ...interface{})
 	Infof(template string, args ...interface{})
@@ -56,3 +99,64 @@ type Logger interface {
 	Warnf(template string, args ...interface{})
 	Panicf(template string, args ...interface{})
 }
+
+// StructuredLogger is a Logger which can attach contextual fields to the entries it logs.
+type StructuredLogger interface {
+	Logger
+	// With returns a child logger which adds the given alternating keys and values
+	// to every entry, in addition to the fields of its parent.
+	With(keysAndValues ...interface{}) StructuredLogger
+}
+
+// LeaderRotation selects the leader of each view.
+// All nodes must select the same leader for the same view.
+type LeaderRotation interface {
+	// Leader returns the leader of the given view among the nodes, which are sorted in ascending order.
+	Leader(view uint64, nodes []uint64) uint64
+}
+
+// MetadataExtender attaches opaque application bytes to the metadata of proposals,
+// and validates the bytes attached to the metadata of proposals made by other nodes.
+type MetadataExtender interface {
+	// ApplicationMetadata returns the bytes to attach to the metadata of the proposal with the given view and sequence.
+	ApplicationMetadata(view, seq uint64) []byte
+	// VerifyApplicationMetadata verifies the bytes attached to the metadata of the proposal with the given view and sequence.
+	VerifyApplicationMetadata(view, seq uint64, metadata []byte) error
+}
+
+// MessageRejectionHandler is notified about incoming messages which
+// are dropped by the library before they are processed.
+type MessageRejectionHandler interface {
+	OnMessageRejected(sender uint64, m *protos.Message, reason error)
+}
+
+// FatalErrorHandler is notified when the node halts because of a fatal error.
+type FatalErrorHandler interface {
+	OnFatalError(err *bft.FatalError)
+}
+
+// MessageAuthenticator authenticates consensus messages exchanged between nodes,
+// either by signing them or by computing MACs with pairwise keys.
+type MessageAuthenticator interface {
+	// Authenticate returns a tag authenticating the serialized message we send to the target node.
+	Authenticate(target uint64, msg []byte) []byte
+	// VerifyAuthentication verifies the tag of the serialized message we received from the sender.
+	VerifyAuthentication(sender uint64, msg []byte, tag []byte) error
+}
+
+// RequestTracer is notified about the lifecycle events of requests,
+// from their submission until they are committed or removed from the pool.
+// It is called synchronously, and therefore should not block.
+type RequestTracer interface {
+	TraceRequest(event bft.RequestEvent)
+}
+
+// Journal records the inputs of a node and the decisions it delivers,
+// so they can later be replayed into a fresh node.
+type Journal interface {
+	RecordMessage(sender uint64, m *protos.Message)
+	RecordRequest(sender uint64, req []byte)
+	RecordSchedulerTick(t time.Time)
+	RecordViewChangerTick(t time.Time)
+	RecordDelivery(proposal bft.Proposal, signatures []bft.Signature)
+}
@@ -0,0 +1,15 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package api
+
+// WithFields returns a child of the logger which adds the given alternating keys and values
+// to every entry, if the logger is a StructuredLogger. Otherwise, the logger is returned as is.
+func WithFields(logger Logger, keysAndValues ...interface{}) Logger {
+	if sl, isStructured := logger.(StructuredLogger); isStructured {
+		return sl.With(keysAndValues...)
+	}
+	return logger
+}
@@ -0,0 +1,81 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package auth
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+
+	"github.com/SmartBFT-Go/consensus/pkg/api"
+	"github.com/SmartBFT-Go/consensus/pkg/types"
+	"github.com/pkg/errors"
+)
+
+// SignatureAuthenticator authenticates messages by signing them with the api.Signer,
+// and verifies them with the api.Verifier.
+// A signed message can be verified by any node, not only by the one it was sent to.
+type SignatureAuthenticator struct {
+	Signer   api.Signer
+	Verifier api.Verifier
+}
+
+// Authenticate signs the message.
+func (sa *SignatureAuthenticator) Authenticate(_ uint64, msg []byte) []byte {
+	return sa.Signer.Sign(msg)
+}
+
+// VerifyAuthentication verifies the signature of the sender over the message.
+func (sa *SignatureAuthenticator) VerifyAuthentication(sender uint64, msg []byte, tag []byte) error {
+	return sa.Verifier.VerifySignature(types.Signature{
+		Id:    sender,
+		Value: tag,
+		Msg:   msg,
+	})
+}
+
+// HMACAuthenticator authenticates messages with HMAC-SHA256,
+// keyed by a secret shared between each pair of nodes.
+type HMACAuthenticator struct {
+	SelfID uint64
+	// Keys maps the ID of each node to the secret we share with it.
+	Keys map[uint64][]byte
+}
+
+// Authenticate computes the MAC of the message we send to the target.
+// It returns nil if we share no key with the target, which fails the verification on its side.
+func (ha *HMACAuthenticator) Authenticate(target uint64, msg []byte) []byte {
+	key, exists := ha.Keys[target]
+	if !exists {
+		return nil
+	}
+	return computeMAC(key, ha.SelfID, target, msg)
+}
+
+// VerifyAuthentication verifies the MAC of the message the sender sent us.
+func (ha *HMACAuthenticator) VerifyAuthentication(sender uint64, msg []byte, tag []byte) error {
+	key, exists := ha.Keys[sender]
+	if !exists {
+		return errors.Errorf("no key is shared with node %d", sender)
+	}
+	if !hmac.Equal(tag, computeMAC(key, sender, ha.SelfID, msg)) {
+		return errors.Errorf("invalid MAC of node %d", sender)
+	}
+	return nil
+}
+
+// computeMAC binds the MAC to the direction of the message,
+// so it cannot be replayed back to its sender.
+func computeMAC(key []byte, sender, target uint64, msg []byte) []byte {
+	ids := make([]byte, 16)
+	binary.BigEndian.PutUint64(ids, sender)
+	binary.BigEndian.PutUint64(ids[8:], target)
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write(ids)
+	mac.Write(msg)
+	return mac.Sum(nil)
+}
@@ -0,0 +1,63 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package auth_test
+
+import (
+	"testing"
+
+	"github.com/SmartBFT-Go/consensus/internal/bft/mocks"
+	"github.com/SmartBFT-Go/consensus/pkg/api"
+	"github.com/SmartBFT-Go/consensus/pkg/auth"
+	"github.com/SmartBFT-Go/consensus/pkg/types"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	_ api.MessageAuthenticator = &auth.SignatureAuthenticator{}
+	_ api.MessageAuthenticator = &auth.HMACAuthenticator{}
+)
+
+func TestHMACAuthenticator(t *testing.T) {
+	keys := map[uint64]map[uint64][]byte{
+		1: {2: []byte("secret12"), 3: []byte("secret13")},
+		2: {1: []byte("secret12")},
+		3: {1: []byte("secret13")},
+	}
+	nodes := make(map[uint64]*auth.HMACAuthenticator)
+	for id, k := range keys {
+		nodes[id] = &auth.HMACAuthenticator{SelfID: id, Keys: k}
+	}
+
+	msg := []byte("message")
+	tag := nodes[1].Authenticate(2, msg)
+	assert.NoError(t, nodes[2].VerifyAuthentication(1, msg, tag))
+
+	// Tampered message
+	assert.EqualError(t, nodes[2].VerifyAuthentication(1, []byte("massage"), tag), "invalid MAC of node 1")
+	// Tag replayed back to its sender
+	assert.EqualError(t, nodes[1].VerifyAuthentication(2, msg, tag), "invalid MAC of node 2")
+	// Tag computed for another node
+	assert.EqualError(t, nodes[3].VerifyAuthentication(1, msg, tag), "invalid MAC of node 1")
+	// No key shared
+	assert.Nil(t, nodes[2].Authenticate(3, msg))
+	assert.EqualError(t, nodes[2].VerifyAuthentication(3, msg, tag), "no key is shared with node 3")
+}
+
+func TestSignatureAuthenticator(t *testing.T) {
+	signer := &mocks.SignerMock{}
+	signer.On("Sign", []byte("message")).Return([]byte("signature"))
+	verifier := &mocks.VerifierMock{}
+	verifier.On("VerifySignature", types.Signature{Id: 1, Value: []byte("signature"), Msg: []byte("message")}).Return(nil)
+	verifier.On("VerifySignature", types.Signature{Id: 2, Value: []byte("signature"), Msg: []byte("message")}).Return(errors.New("bad signature"))
+
+	sa := &auth.SignatureAuthenticator{Signer: signer, Verifier: verifier}
+
+	tag := sa.Authenticate(2, []byte("message"))
+	assert.Equal(t, []byte("signature"), tag)
+	assert.NoError(t, sa.VerifyAuthentication(1, []byte("message"), tag))
+	assert.EqualError(t, sa.VerifyAuthentication(2, []byte("message"), tag), "bad signature")
+}
@@ -0,0 +1,38 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package comm
+
+import (
+	protos "github.com/SmartBFT-Go/consensus/smartbftprotos"
+)
+
+// Channel is an api.Comm which sends the messages of a single consensus instance
+// over the connections of a Comm shared with other instances.
+// The messages are handled by the handler the remote nodes registered for the same channel ID.
+type Channel struct {
+	c  *Comm
+	id string
+}
+
+// ID returns the channel ID.
+func (ch *Channel) ID() string {
+	return ch.id
+}
+
+// Nodes returns the IDs of all nodes, including ourselves.
+func (ch *Channel) Nodes() []uint64 {
+	return ch.c.Nodes()
+}
+
+// SendConsensus enqueues the message to be sent to the given node on this channel, without blocking.
+func (ch *Channel) SendConsensus(targetID uint64, m *protos.Message) {
+	ch.c.sendConsensus(ch.id, targetID, m)
+}
+
+// SendTransaction enqueues the request to be sent to the given node on this channel, without blocking.
+func (ch *Channel) SendTransaction(targetID uint64, request []byte) {
+	ch.c.sendTransaction(ch.id, targetID, request)
+}
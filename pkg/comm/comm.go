@@ -0,0 +1,460 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package comm
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"sort"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/SmartBFT-Go/consensus/pkg/api"
+	protos "github.com/SmartBFT-Go/consensus/smartbftprotos"
+	"github.com/pkg/errors"
+)
+
+const (
+	DefaultConsensusQueueSize   = 200
+	DefaultTransactionQueueSize = 1000
+	DefaultMaxMessageSize       = 10 * 1024 * 1024
+	DefaultDialTimeout          = 5 * time.Second
+	DefaultWriteTimeout         = 10 * time.Second
+	DefaultMinBackoff           = 100 * time.Millisecond
+	DefaultMaxBackoff           = 10 * time.Second
+)
+
+// Handler receives the messages and requests sent by remote nodes.
+// It is implemented by consensus.Consensus.
+type Handler interface {
+	HandleMessage(sender uint64, m *protos.Message)
+	HandleRequest(sender uint64, req []byte)
+}
+
+// RemoteNode is a node of the cluster that messages can be sent to.
+type RemoteNode struct {
+	ID       uint64
+	Endpoint string
+	// Certificate is the DER encoded TLS certificate the node authenticates with,
+	// both as a server and as a client.
+	Certificate []byte
+}
+
+// Metrics counts the traffic of the Comm.
+type Metrics struct {
+	SentConsensus        uint64
+	SentTransactions     uint64
+	ReceivedConsensus    uint64
+	ReceivedTransactions uint64
+	DroppedConsensus     uint64
+	DroppedTransactions  uint64
+	ConnectionFailures   uint64
+	RejectedConnections  uint64
+	// UnroutedMessages counts the messages and requests received on channels no handler is registered for.
+	UnroutedMessages uint64
+	// FailedWrites counts the messages and requests whose write failed.
+	// Each is sent again once the connection is re-established.
+	FailedWrites uint64
+}
+
+// Comm is an api.Comm which sends framed protobuf messages over TCP streams with mutual TLS.
+// Each remote node is sent messages over a dedicated connection which is re-established
+// with an exponential backoff whenever it breaks.
+// Consensus messages and transactions are buffered in separate bounded queues per remote node,
+// and messages that don't fit in their queue are dropped.
+//
+// Several consensus instances, such as those of independent channels, can share the connections of a Comm.
+// Each instance sends through its own Channel, and is registered as the handler of its channel ID.
+// Messages sent without a channel ID are handled by the Handler.
+type Comm struct {
+	SelfID uint64
+	// ListenAddress is the address incoming connections are accepted on,
+	// unless Listener is set.
+	ListenAddress string
+	Listener      net.Listener
+	// Certificate is used both to accept connections and to connect to remote nodes.
+	Certificate tls.Certificate
+	// RootCAs verify the certificates of the remote nodes.
+	RootCAs     *x509.CertPool
+	RemoteNodes []RemoteNode
+	// Handler handles the messages that are not sent on any channel.
+	// It may be nil if handlers are registered for channels.
+	Handler Handler
+	Logger  api.Logger
+
+	// Zero values mean the defaults
+	ConsensusQueueSize   int
+	TransactionQueueSize int
+	MaxMessageSize       int
+	DialTimeout          time.Duration
+	WriteTimeout         time.Duration
+	MinBackoff           time.Duration
+	MaxBackoff           time.Duration
+
+	nodes       []uint64
+	nodesByCert map[[sha256.Size]byte]uint64
+	peers       map[uint64]*peer
+	metrics     Metrics
+	logger      api.Logger
+
+	routeLock sync.RWMutex
+	handlers  map[string]Handler
+
+	lock     sync.Mutex
+	conns    map[net.Conn]struct{}
+	stopChan chan struct{}
+	cancel   context.CancelFunc
+	running  sync.WaitGroup
+}
+
+// Start listens for incoming connections and starts connecting to the remote nodes.
+func (c *Comm) Start() error {
+	if c.Handler == nil && c.registeredChannels() == 0 {
+		return errors.New("no handler configured")
+	}
+	if c.Logger == nil {
+		return errors.New("no logger configured")
+	}
+	c.applyDefaults()
+	c.logger = api.WithFields(c.Logger, "node", c.SelfID)
+
+	c.nodes = []uint64{c.SelfID}
+	c.nodesByCert = make(map[[sha256.Size]byte]uint64, len(c.RemoteNodes))
+	c.peers = make(map[uint64]*peer, len(c.RemoteNodes))
+	c.conns = make(map[net.Conn]struct{})
+	c.stopChan = make(chan struct{})
+
+	for _, node := range c.RemoteNodes {
+		if node.ID == c.SelfID {
+			return errors.Errorf("remote node %d has our own ID", node.ID)
+		}
+		if _, exists := c.peers[node.ID]; exists {
+			return errors.Errorf("remote node %d is configured more than once", node.ID)
+		}
+		if len(node.Certificate) == 0 {
+			return errors.Errorf("remote node %d has no certificate", node.ID)
+		}
+		c.nodes = append(c.nodes, node.ID)
+		c.nodesByCert[sha256.Sum256(node.Certificate)] = node.ID
+		c.peers[node.ID] = c.newPeer(node)
+	}
+	sort.Slice(c.nodes, func(i, j int) bool {
+		return c.nodes[i] < c.nodes[j]
+	})
+
+	if c.Listener == nil {
+		listener, err := net.Listen("tcp", c.ListenAddress)
+		if err != nil {
+			return errors.Wrapf(err, "failed listening on %s", c.ListenAddress)
+		}
+		c.Listener = listener
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c.cancel = cancel
+
+	c.running.Add(1 + len(c.peers))
+	go c.accept()
+	for _, p := range c.peers {
+		go p.run(ctx)
+	}
+
+	return nil
+}
+
+// Stop closes all connections, both accepted and dialed, and waits for all goroutines to finish.
+func (c *Comm) Stop() {
+	select {
+	case <-c.stopChan:
+		return
+	default:
+	}
+	close(c.stopChan)
+	c.cancel()
+	c.Listener.Close()
+
+	c.lock.Lock()
+	for conn := range c.conns {
+		conn.Close()
+	}
+	c.lock.Unlock()
+
+	c.running.Wait()
+}
+
+// Addr returns the address incoming connections are accepted on.
+func (c *Comm) Addr() net.Addr {
+	return c.Listener.Addr()
+}
+
+// Nodes returns the IDs of all nodes, including ourselves.
+func (c *Comm) Nodes() []uint64 {
+	nodes := make([]uint64, len(c.nodes))
+	copy(nodes, c.nodes)
+	return nodes
+}
+
+// SendConsensus enqueues the message to be sent to the given node, without blocking.
+func (c *Comm) SendConsensus(targetID uint64, m *protos.Message) {
+	c.sendConsensus("", targetID, m)
+}
+
+// SendTransaction enqueues the request to be sent to the given node, without blocking.
+func (c *Comm) SendTransaction(targetID uint64, request []byte) {
+	c.sendTransaction("", targetID, request)
+}
+
+// Register routes the messages and requests received on the given channel to the handler.
+// The consensus instance of a channel should be registered before it is started,
+// and deregistered after it is stopped.
+func (c *Comm) Register(channelID string, handler Handler) error {
+	if channelID == "" {
+		return errors.New("empty channel ID")
+	}
+	if handler == nil {
+		return errors.Errorf("no handler for channel %s", channelID)
+	}
+
+	c.routeLock.Lock()
+	defer c.routeLock.Unlock()
+
+	if _, exists := c.handlers[channelID]; exists {
+		return errors.Errorf("channel %s is already registered", channelID)
+	}
+	if c.handlers == nil {
+		c.handlers = make(map[string]Handler)
+	}
+	c.handlers[channelID] = handler
+	return nil
+}
+
+// Deregister stops routing the messages and requests received on the given channel.
+// Messages received on it afterwards are dropped.
+func (c *Comm) Deregister(channelID string) {
+	c.routeLock.Lock()
+	defer c.routeLock.Unlock()
+	delete(c.handlers, channelID)
+}
+
+// Channel returns an api.Comm which sends messages on the given channel.
+func (c *Comm) Channel(channelID string) *Channel {
+	return &Channel{c: c, id: channelID}
+}
+
+func (c *Comm) sendConsensus(channelID string, targetID uint64, m *protos.Message) {
+	p, exists := c.peers[targetID]
+	if !exists {
+		c.logger.Warnf("Cannot send consensus message to unknown node %d", targetID)
+		return
+	}
+	select {
+	case p.consensus <- &protos.Frame{Content: &protos.Frame_Consensus{Consensus: m}, Channel: channelID}:
+	default:
+		atomic.AddUint64(&c.metrics.DroppedConsensus, 1)
+		p.logger.Warnf("Consensus queue is full, dropping message")
+	}
+}
+
+func (c *Comm) sendTransaction(channelID string, targetID uint64, request []byte) {
+	p, exists := c.peers[targetID]
+	if !exists {
+		c.logger.Warnf("Cannot send transaction to unknown node %d", targetID)
+		return
+	}
+	select {
+	case p.transactions <- &protos.Frame{Content: &protos.Frame_Transaction{Transaction: request}, Channel: channelID}:
+	default:
+		atomic.AddUint64(&c.metrics.DroppedTransactions, 1)
+		p.logger.Warnf("Transaction queue is full, dropping transaction")
+	}
+}
+
+// handlerOf returns the handler of the given channel, or nil if there is none.
+func (c *Comm) handlerOf(channelID string) Handler {
+	if channelID == "" {
+		return c.Handler
+	}
+	c.routeLock.RLock()
+	defer c.routeLock.RUnlock()
+	return c.handlers[channelID]
+}
+
+func (c *Comm) registeredChannels() int {
+	c.routeLock.RLock()
+	defer c.routeLock.RUnlock()
+	return len(c.handlers)
+}
+
+// Metrics returns a snapshot of the traffic counters.
+func (c *Comm) Metrics() Metrics {
+	return Metrics{
+		SentConsensus:        atomic.LoadUint64(&c.metrics.SentConsensus),
+		SentTransactions:     atomic.LoadUint64(&c.metrics.SentTransactions),
+		ReceivedConsensus:    atomic.LoadUint64(&c.metrics.ReceivedConsensus),
+		ReceivedTransactions: atomic.LoadUint64(&c.metrics.ReceivedTransactions),
+		DroppedConsensus:     atomic.LoadUint64(&c.metrics.DroppedConsensus),
+		DroppedTransactions:  atomic.LoadUint64(&c.metrics.DroppedTransactions),
+		ConnectionFailures:   atomic.LoadUint64(&c.metrics.ConnectionFailures),
+		RejectedConnections:  atomic.LoadUint64(&c.metrics.RejectedConnections),
+		UnroutedMessages:     atomic.LoadUint64(&c.metrics.UnroutedMessages),
+		FailedWrites:         atomic.LoadUint64(&c.metrics.FailedWrites),
+	}
+}
+
+func (c *Comm) applyDefaults() {
+	if c.ConsensusQueueSize <= 0 {
+		c.ConsensusQueueSize = DefaultConsensusQueueSize
+	}
+	if c.TransactionQueueSize <= 0 {
+		c.TransactionQueueSize = DefaultTransactionQueueSize
+	}
+	if c.MaxMessageSize <= 0 {
+		c.MaxMessageSize = DefaultMaxMessageSize
+	}
+	if c.DialTimeout <= 0 {
+		c.DialTimeout = DefaultDialTimeout
+	}
+	if c.WriteTimeout <= 0 {
+		c.WriteTimeout = DefaultWriteTimeout
+	}
+	if c.MinBackoff <= 0 {
+		c.MinBackoff = DefaultMinBackoff
+	}
+	if c.MaxBackoff < c.MinBackoff {
+		c.MaxBackoff = DefaultMaxBackoff
+		if c.MaxBackoff < c.MinBackoff {
+			c.MaxBackoff = c.MinBackoff
+		}
+	}
+}
+
+func (c *Comm) accept() {
+	defer c.running.Done()
+
+	tlsConfig := &tls.Config{
+		Certificates: []tls.Certificate{c.Certificate},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    c.RootCAs,
+		MinVersion:   tls.VersionTLS12,
+	}
+
+	for {
+		conn, err := c.Listener.Accept()
+		if err != nil {
+			select {
+			case <-c.stopChan:
+				return
+			default:
+			}
+			c.logger.Warnf("Failed accepting connection: %v", err)
+			continue
+		}
+
+		if !c.track(conn) {
+			conn.Close()
+			return
+		}
+		c.running.Add(1)
+		go c.serve(tls.Server(conn, tlsConfig), conn)
+	}
+}
+
+// track registers the accepted or dialed connection to be closed on Stop.
+// Returns false if the Comm is already stopped.
+func (c *Comm) track(conn net.Conn) bool {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+
+	select {
+	case <-c.stopChan:
+		return false
+	default:
+	}
+	c.conns[conn] = struct{}{}
+	return true
+}
+
+func (c *Comm) untrack(conn net.Conn) {
+	c.lock.Lock()
+	defer c.lock.Unlock()
+	delete(c.conns, conn)
+}
+
+func (c *Comm) serve(conn *tls.Conn, rawConn net.Conn) {
+	defer c.running.Done()
+	defer c.untrack(rawConn)
+	defer conn.Close()
+
+	sender, err := c.authenticate(conn)
+	if err != nil {
+		atomic.AddUint64(&c.metrics.RejectedConnections, 1)
+		c.logger.Warnf("Rejected connection from %s: %v", rawConn.RemoteAddr(), err)
+		return
+	}
+	logger := api.WithFields(c.logger, "sender", sender)
+	logger.Debugf("Accepted a connection")
+
+	for {
+		frame, err := readFrame(conn, c.MaxMessageSize)
+		if err != nil {
+			select {
+			case <-c.stopChan:
+			default:
+				logger.Debugf("Connection closed: %v", err)
+			}
+			return
+		}
+
+		if frame.GetContent() == nil {
+			logger.Warnf("Got an empty frame, closing connection")
+			return
+		}
+
+		handler := c.handlerOf(frame.Channel)
+		if handler == nil {
+			atomic.AddUint64(&c.metrics.UnroutedMessages, 1)
+			logger.Debugf("Got a message on unknown channel %q, dropping it", frame.Channel)
+			continue
+		}
+
+		switch frame.GetContent().(type) {
+		case *protos.Frame_Consensus:
+			atomic.AddUint64(&c.metrics.ReceivedConsensus, 1)
+			handler.HandleMessage(sender, frame.GetConsensus())
+		case *protos.Frame_Transaction:
+			atomic.AddUint64(&c.metrics.ReceivedTransactions, 1)
+			handler.HandleRequest(sender, frame.GetTransaction())
+		}
+	}
+}
+
+// authenticate completes the TLS handshake and returns the ID of the node
+// that the client certificate belongs to.
+func (c *Comm) authenticate(conn *tls.Conn) (uint64, error) {
+	if err := conn.SetDeadline(time.Now().Add(c.DialTimeout)); err != nil {
+		return 0, err
+	}
+	if err := conn.Handshake(); err != nil {
+		return 0, errors.Wrap(err, "TLS handshake failed")
+	}
+	if err := conn.SetDeadline(time.Time{}); err != nil {
+		return 0, err
+	}
+
+	certs := conn.ConnectionState().PeerCertificates
+	if len(certs) == 0 {
+		return 0, errors.New("no client certificate")
+	}
+	id, exists := c.nodesByCert[sha256.Sum256(certs[0].Raw)]
+	if !exists {
+		return 0, errors.Errorf("client certificate of %s doesn't belong to any node", certs[0].Subject)
+	}
+	return id, nil
+}
@@ -0,0 +1,397 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package comm_test
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"math/big"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/SmartBFT-Go/consensus/pkg/api"
+	"github.com/SmartBFT-Go/consensus/pkg/comm"
+	protos "github.com/SmartBFT-Go/consensus/smartbftprotos"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+var _ api.Comm = &comm.Comm{}
+
+const receiveTimeout = 10 * time.Second
+
+type received struct {
+	sender uint64
+	msg    *protos.Message
+	req    []byte
+}
+
+type handler chan received
+
+func (h handler) HandleMessage(sender uint64, m *protos.Message) {
+	h <- received{sender: sender, msg: m}
+}
+
+func (h handler) HandleRequest(sender uint64, req []byte) {
+	h <- received{sender: sender, req: req}
+}
+
+func (h handler) next(t *testing.T) received {
+	select {
+	case r := <-h:
+		return r
+	case <-time.After(receiveTimeout):
+		t.Fatal("did not receive anything")
+		return received{}
+	}
+}
+
+type ca struct {
+	key  *ecdsa.PrivateKey
+	cert *x509.Certificate
+	pool *x509.CertPool
+}
+
+func newCA(t *testing.T) *ca {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	assert.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	assert.NoError(t, err)
+	pool := x509.NewCertPool()
+	pool.AddCert(cert)
+	return &ca{key: key, cert: cert, pool: pool}
+}
+
+func (ca *ca) issue(t *testing.T, name string) tls.Certificate {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	assert.NoError(t, err)
+	serial, err := rand.Int(rand.Reader, big.NewInt(1<<62))
+	assert.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: serial,
+		Subject:      pkix.Name{CommonName: name},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+		IPAddresses:  []net.IP{net.ParseIP("127.0.0.1")},
+	}
+	der, err := x509.CreateCertificate(rand.Reader, template, ca.cert, &key.PublicKey, ca.key)
+	assert.NoError(t, err)
+	return tls.Certificate{Certificate: [][]byte{der}, PrivateKey: key}
+}
+
+// cluster creates Comms of nodes 1..n listening on loopback, which are not started yet.
+func cluster(t *testing.T, n int) ([]*comm.Comm, []handler) {
+	basicLog, err := zap.NewDevelopment()
+	assert.NoError(t, err)
+	ca := newCA(t)
+
+	var remoteNodes []comm.RemoteNode
+	var comms []*comm.Comm
+	var handlers []handler
+	for i := 1; i <= n; i++ {
+		listener, err := net.Listen("tcp", "127.0.0.1:0")
+		assert.NoError(t, err)
+		cert := ca.issue(t, fmt.Sprintf("node%d", i))
+		remoteNodes = append(remoteNodes, comm.RemoteNode{
+			ID:          uint64(i),
+			Endpoint:    listener.Addr().String(),
+			Certificate: cert.Certificate[0],
+		})
+		h := make(handler, 100)
+		handlers = append(handlers, h)
+		comms = append(comms, &comm.Comm{
+			SelfID:      uint64(i),
+			Listener:    listener,
+			Certificate: cert,
+			RootCAs:     ca.pool,
+			Handler:     h,
+			Logger:      basicLog.Sugar(),
+			MinBackoff:  10 * time.Millisecond,
+			MaxBackoff:  100 * time.Millisecond,
+		})
+	}
+
+	for _, c := range comms {
+		for _, node := range remoteNodes {
+			if node.ID != c.SelfID {
+				c.RemoteNodes = append(c.RemoteNodes, node)
+			}
+		}
+	}
+
+	return comms, handlers
+}
+
+func TestCommSendAndReceive(t *testing.T) {
+	comms, handlers := cluster(t, 3)
+	for _, c := range comms {
+		assert.NoError(t, c.Start())
+		defer c.Stop()
+	}
+
+	assert.Equal(t, []uint64{1, 2, 3}, comms[0].Nodes())
+
+	comms[0].SendConsensus(2, prepare)
+	r := handlers[1].next(t)
+	assert.Equal(t, uint64(1), r.sender)
+	assert.True(t, proto.Equal(prepare, r.msg))
+
+	comms[2].SendTransaction(1, []byte{1, 2, 3})
+	r = handlers[0].next(t)
+	assert.Equal(t, uint64(3), r.sender)
+	assert.Equal(t, []byte{1, 2, 3}, r.req)
+
+	assert.Equal(t, uint64(1), comms[0].Metrics().SentConsensus)
+	assert.Equal(t, uint64(1), comms[1].Metrics().ReceivedConsensus)
+	assert.Equal(t, uint64(1), comms[2].Metrics().SentTransactions)
+	assert.Equal(t, uint64(1), comms[0].Metrics().ReceivedTransactions)
+}
+
+func TestCommReconnect(t *testing.T) {
+	comms, handlers := cluster(t, 2)
+
+	// Node 2 is not up yet, so node 1 keeps retrying until it is
+	comms[1].ListenAddress = comms[1].Listener.Addr().String()
+	comms[1].Listener.Close()
+	comms[1].Listener = nil
+
+	assert.NoError(t, comms[0].Start())
+	defer comms[0].Stop()
+	comms[0].SendConsensus(2, prepare)
+
+	time.Sleep(100 * time.Millisecond)
+	assert.NotZero(t, comms[0].Metrics().ConnectionFailures)
+
+	assert.NoError(t, comms[1].Start())
+	defer comms[1].Stop()
+
+	r := handlers[1].next(t)
+	assert.Equal(t, uint64(1), r.sender)
+	assert.True(t, proto.Equal(prepare, r.msg))
+}
+
+func TestCommQueueOverflow(t *testing.T) {
+	comms, _ := cluster(t, 2)
+	comms[0].ConsensusQueueSize = 2
+	comms[0].TransactionQueueSize = 1
+
+	// Node 2 is never started, so nothing leaves the queues
+	assert.NoError(t, comms[0].Start())
+	defer comms[0].Stop()
+
+	for i := 0; i < 3; i++ {
+		comms[0].SendConsensus(2, prepare)
+		comms[0].SendTransaction(2, []byte{1})
+	}
+
+	assert.Equal(t, uint64(1), comms[0].Metrics().DroppedConsensus)
+	assert.Equal(t, uint64(2), comms[0].Metrics().DroppedTransactions)
+}
+
+func TestCommRejectsUnknownCertificate(t *testing.T) {
+	comms, handlers := cluster(t, 2)
+	// Node 2 doesn't know the certificate node 1 connects with
+	comms[1].RemoteNodes[0].Certificate = comms[1].Certificate.Certificate[0]
+	comms[1].RemoteNodes[0].ID = 3
+
+	for _, c := range comms {
+		assert.NoError(t, c.Start())
+		defer c.Stop()
+	}
+
+	for comms[1].Metrics().RejectedConnections == 0 {
+		comms[0].SendConsensus(2, prepare)
+		time.Sleep(10 * time.Millisecond)
+	}
+
+	select {
+	case r := <-handlers[1]:
+		t.Fatalf("received %v from an unknown node", r)
+	default:
+	}
+}
+
+func TestCommChannels(t *testing.T) {
+	comms, handlers := cluster(t, 2)
+
+	channelHandlers := make(map[string][]handler)
+	for _, channelID := range []string{"ch1", "ch2"} {
+		for _, c := range comms {
+			h := make(handler, 100)
+			assert.NoError(t, c.Register(channelID, h))
+			channelHandlers[channelID] = append(channelHandlers[channelID], h)
+		}
+	}
+	// Node 2 doesn't take part in the third channel
+	assert.NoError(t, comms[0].Register("ch3", make(handler, 100)))
+
+	// Channels suffice without a default handler
+	comms[1].Handler = nil
+	for _, c := range comms {
+		assert.NoError(t, c.Start())
+		defer c.Stop()
+	}
+
+	var _ api.Comm = comms[0].Channel("ch1")
+	comms[0].Channel("ch1").SendConsensus(2, prepare)
+	comms[0].Channel("ch2").SendTransaction(2, []byte{1, 2, 3})
+	comms[1].Channel("ch2").SendTransaction(1, []byte{4})
+
+	r := channelHandlers["ch1"][1].next(t)
+	assert.Equal(t, uint64(1), r.sender)
+	assert.True(t, proto.Equal(prepare, r.msg))
+	r = channelHandlers["ch2"][1].next(t)
+	assert.Equal(t, []byte{1, 2, 3}, r.req)
+	r = channelHandlers["ch2"][0].next(t)
+	assert.Equal(t, uint64(2), r.sender)
+	assert.Equal(t, []byte{4}, r.req)
+
+	// Messages of unknown channels are dropped, and the connection is kept
+	comms[0].Channel("ch3").SendConsensus(2, prepare)
+	comms[1].Deregister("ch1")
+	comms[0].Channel("ch1").SendConsensus(2, prepare)
+	comms[0].SendConsensus(2, prepare)
+	comms[0].Channel("ch2").SendConsensus(2, prepare)
+	channelHandlers["ch2"][1].next(t)
+	assert.Equal(t, uint64(3), comms[1].Metrics().UnroutedMessages)
+
+	for _, h := range append(handlers, channelHandlers["ch1"]...) {
+		select {
+		case r := <-h:
+			t.Fatalf("received %v on the wrong channel", r)
+		default:
+		}
+	}
+
+	assert.EqualError(t, comms[0].Register("ch1", make(handler)), "channel ch1 is already registered")
+	assert.EqualError(t, comms[0].Register("", make(handler)), "empty channel ID")
+}
+
+// stuckNode accepts the connections of the remote nodes in place of the given node,
+// completes the TLS handshake, and never reads from them.
+func stuckNode(t *testing.T, c *comm.Comm) chan net.Conn {
+	conns := make(chan net.Conn, 10)
+	listener := tls.NewListener(c.Listener, &tls.Config{
+		Certificates: []tls.Certificate{c.Certificate},
+		ClientAuth:   tls.RequireAnyClientCert,
+	})
+	go func() {
+		for {
+			conn, err := listener.Accept()
+			if err != nil {
+				return
+			}
+			assert.NoError(t, conn.(*tls.Conn).Handshake())
+			conns <- conn
+		}
+	}()
+	return conns
+}
+
+// stuckRequest is too big for the socket buffers, so it can't be written to a stuck node.
+var stuckRequest = make([]byte, 16*1024*1024)
+
+func TestCommStopWhileWriting(t *testing.T) {
+	comms, _ := cluster(t, 2)
+	comms[0].MaxMessageSize = 2 * len(stuckRequest)
+	comms[0].WriteTimeout = time.Minute
+	conns := stuckNode(t, comms[1])
+	defer comms[1].Listener.Close()
+
+	assert.NoError(t, comms[0].Start())
+	comms[0].SendTransaction(2, stuckRequest)
+	conn := <-conns
+	defer conn.Close()
+	time.Sleep(100 * time.Millisecond)
+
+	// The write is stuck until its deadline, unless Stop closes the connection
+	stopped := make(chan struct{})
+	go func() {
+		comms[0].Stop()
+		close(stopped)
+	}()
+	select {
+	case <-stopped:
+	case <-time.After(receiveTimeout):
+		t.Fatal("Stop waited for a stuck write")
+	}
+}
+
+func TestCommResendFailedWrite(t *testing.T) {
+	comms, handlers := cluster(t, 2)
+	for _, c := range comms {
+		c.MaxMessageSize = 2 * len(stuckRequest)
+	}
+	comms[0].WriteTimeout = time.Second
+	address := comms[1].Listener.Addr().String()
+	conns := stuckNode(t, comms[1])
+
+	assert.NoError(t, comms[0].Start())
+	defer comms[0].Stop()
+	comms[0].SendTransaction(2, stuckRequest)
+	conn := <-conns
+
+	deadline := time.Now().Add(receiveTimeout)
+	for comms[0].Metrics().FailedWrites == 0 {
+		if time.Now().After(deadline) {
+			t.Fatal("the write did not fail")
+		}
+		time.Sleep(10 * time.Millisecond)
+	}
+	conn.Close()
+	comms[1].Listener.Close()
+
+	// Node 2 comes up in place of the stuck node, and is sent the request whose write failed
+	comms[1].Listener = nil
+	comms[1].ListenAddress = address
+	assert.NoError(t, comms[1].Start())
+	defer comms[1].Stop()
+
+	r := handlers[1].next(t)
+	assert.Equal(t, uint64(1), r.sender)
+	assert.Equal(t, stuckRequest, r.req)
+	assert.Equal(t, uint64(1), comms[0].Metrics().SentTransactions)
+}
+
+func TestCommInvalidConfig(t *testing.T) {
+	comms, _ := cluster(t, 2)
+
+	comms[0].RemoteNodes = append(comms[0].RemoteNodes, comms[0].RemoteNodes[0])
+	assert.EqualError(t, comms[0].Start(), "remote node 2 is configured more than once")
+
+	comms[1].Handler = nil
+	assert.EqualError(t, comms[1].Start(), "no handler configured")
+}
+
+var prepare = &protos.Message{
+	Content: &protos.Message_Prepare{
+		Prepare: &protos.Prepare{
+			View:   1,
+			Seq:    2,
+			Digest: "digest",
+		},
+	},
+}
@@ -0,0 +1,55 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package comm
+
+import (
+	"encoding/binary"
+	"io"
+
+	protos "github.com/SmartBFT-Go/consensus/smartbftprotos"
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+)
+
+// frameHeaderSize is the size of the big endian length prefix of each frame.
+const frameHeaderSize = 4
+
+func writeFrame(w io.Writer, frame *protos.Frame) error {
+	payload, err := proto.Marshal(frame)
+	if err != nil {
+		return errors.Wrap(err, "failed marshaling frame")
+	}
+
+	buff := make([]byte, frameHeaderSize+len(payload))
+	binary.BigEndian.PutUint32(buff, uint32(len(payload)))
+	copy(buff[frameHeaderSize:], payload)
+
+	_, err = w.Write(buff)
+	return err
+}
+
+func readFrame(r io.Reader, maxSize int) (*protos.Frame, error) {
+	header := make([]byte, frameHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		return nil, err
+	}
+
+	size := binary.BigEndian.Uint32(header)
+	if uint64(size) > uint64(maxSize) {
+		return nil, errors.Errorf("frame size is %d but the limit is %d", size, maxSize)
+	}
+
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(r, payload); err != nil {
+		return nil, err
+	}
+
+	frame := &protos.Frame{}
+	if err := proto.Unmarshal(payload, frame); err != nil {
+		return nil, errors.Wrap(err, "failed unmarshaling frame")
+	}
+	return frame, nil
+}
@@ -0,0 +1,140 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package comm
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"net"
+	"sync/atomic"
+	"time"
+
+	"github.com/SmartBFT-Go/consensus/pkg/api"
+	protos "github.com/SmartBFT-Go/consensus/smartbftprotos"
+	"github.com/pkg/errors"
+)
+
+// peer sends the messages enqueued for a remote node over a connection to it.
+type peer struct {
+	RemoteNode
+	c            *Comm
+	dialer       *tls.Dialer
+	consensus    chan *protos.Frame
+	transactions chan *protos.Frame
+	logger       api.Logger
+	// pending is the frame whose write failed, which is sent first once the connection is re-established.
+	pending *protos.Frame
+}
+
+func (c *Comm) newPeer(node RemoteNode) *peer {
+	p := &peer{
+		RemoteNode:   node,
+		c:            c,
+		consensus:    make(chan *protos.Frame, c.ConsensusQueueSize),
+		transactions: make(chan *protos.Frame, c.TransactionQueueSize),
+		logger:       api.WithFields(c.logger, "remote", node.ID),
+	}
+	p.dialer = &tls.Dialer{
+		NetDialer: &net.Dialer{Timeout: c.DialTimeout},
+		Config: &tls.Config{
+			Certificates: []tls.Certificate{c.Certificate},
+			RootCAs:      c.RootCAs,
+			MinVersion:   tls.VersionTLS12,
+			// The server must present the certificate of the node we intend to connect to.
+			VerifyConnection: func(cs tls.ConnectionState) error {
+				if len(cs.PeerCertificates) == 0 || !bytes.Equal(cs.PeerCertificates[0].Raw, node.Certificate) {
+					return errors.Errorf("server certificate doesn't belong to node %d", node.ID)
+				}
+				return nil
+			},
+		},
+	}
+	return p
+}
+
+// run connects to the remote node and sends it messages,
+// and reconnects with an exponential backoff whenever the connection fails.
+func (p *peer) run(ctx context.Context) {
+	defer p.c.running.Done()
+
+	backoff := p.c.MinBackoff
+	for {
+		conn, err := p.dialer.DialContext(ctx, "tcp", p.Endpoint)
+		if err != nil {
+			if ctx.Err() != nil {
+				return
+			}
+			atomic.AddUint64(&p.c.metrics.ConnectionFailures, 1)
+			p.logger.Debugf("Failed connecting to %s, retrying in %v: %v", p.Endpoint, backoff, err)
+
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff *= 2
+			if backoff > p.c.MaxBackoff {
+				backoff = p.c.MaxBackoff
+			}
+			continue
+		}
+
+		// Stop closes the connection, so a write stuck on it doesn't hold Stop until its deadline.
+		if !p.c.track(conn) {
+			conn.Close()
+			return
+		}
+		backoff = p.c.MinBackoff
+		p.logger.Debugf("Connected to %s", p.Endpoint)
+		err = p.send(ctx, conn)
+		p.c.untrack(conn)
+		conn.Close()
+		if ctx.Err() != nil {
+			return
+		}
+		atomic.AddUint64(&p.c.metrics.ConnectionFailures, 1)
+		p.logger.Warnf("Lost the connection: %v", err)
+	}
+}
+
+// send writes enqueued messages to the connection until it fails or the context is cancelled.
+// Consensus messages are sent ahead of transactions.
+// A frame whose write fails is kept to be sent first over the next connection.
+func (p *peer) send(ctx context.Context, conn net.Conn) error {
+	for {
+		frame := p.pending
+		p.pending = nil
+		if frame == nil {
+			select {
+			case frame = <-p.consensus:
+			default:
+				select {
+				case <-ctx.Done():
+					return nil
+				case frame = <-p.consensus:
+				case frame = <-p.transactions:
+				}
+			}
+		}
+
+		err := conn.SetWriteDeadline(time.Now().Add(p.c.WriteTimeout))
+		if err == nil {
+			err = writeFrame(conn, frame)
+		}
+		if err != nil {
+			p.pending = frame
+			atomic.AddUint64(&p.c.metrics.FailedWrites, 1)
+			return err
+		}
+
+		if frame.GetConsensus() != nil {
+			atomic.AddUint64(&p.c.metrics.SentConsensus, 1)
+		} else {
+			atomic.AddUint64(&p.c.metrics.SentTransactions, 1)
+		}
+	}
+}
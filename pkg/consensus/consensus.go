@@ -6,12 +6,14 @@
 package consensus
 
 import (
+	"sync"
 	"time"
 
 	algorithm "github.com/SmartBFT-Go/consensus/internal/bft"
 	bft "github.com/SmartBFT-Go/consensus/pkg/api"
 	"github.com/SmartBFT-Go/consensus/pkg/types"
 	protos "github.com/SmartBFT-Go/consensus/smartbftprotos"
+	"github.com/pkg/errors"
 )
 
 const (
@@ -42,22 +44,124 @@ type Consensus struct {
 	ViewChangerTicker       <-chan time.Time
 	ViewChangeResendTimeout time.Duration
 	ViewChangerTimeout      time.Duration
+	// Size limits of incoming messages, zero means unlimited
+	MaxProposalSize         int
+	MaxViewDataSize         int
+	MaxSignatureSize        int
+	MessageRejectionHandler bft.MessageRejectionHandler
+	// Number of incoming messages buffered per sender, zero means the default
+	IncomingMessageQueueSize int
+	// Number of incoming messages per second accepted from each sender, zero means unlimited
+	IncomingMessageRateLimit int
+	// If set, every consensus message sent is authenticated, and every consensus message received is verified
+	MessageAuthenticator bft.MessageAuthenticator
+	// If set, is notified about the lifecycle events of requests
+	RequestTracer bft.RequestTracer
+	// If set, records the messages, requests and ticks the node receives, and the decisions it delivers
+	Journal bft.Journal
+	// If set, is notified when the node halts because of a fatal error
+	FatalErrorHandler bft.FatalErrorHandler
+	// Number of recent decisions checked for conflicts, zero means the default
+	DecisionHistorySize int
+	// Selects the leader of each view, round robin if not set
+	LeaderRotation bft.LeaderRotation
+	// If positive, the leader is rotated after this number of decisions in a view
+	DecisionsPerLeader uint64
+	// If set, leaders deposed by view changes are skipped by leader selection until they sign a decision.
+	// The leader is selected by rotation.Blacklisting over LeaderRotation, which therefore shouldn't be wrapped in it.
+	LeaderBlacklisting bool
+	// If set, attaches application bytes to the metadata of proposals and verifies them.
+	// Nodes which predate versioned metadata neither verify nor reject them, so all nodes should be upgraded together.
+	MetadataExtender bft.MetadataExtender
+	// If positive, the metadata of each decision carries a timestamp, which followers accept
+	// only within this skew from their clock, and only if it is after the timestamp of the previous decision
+	TimestampSkew time.Duration
+	// The kind of faults the cluster tolerates, Byzantine faults if not set.
+	// If only crash faults are tolerated, commits are not signed.
+	FaultModel types.FaultModel
+	// If set, the voting weight of every node, and quorums are weighed instead of counted.
+	// Start returns an error if the weights don't pass types.ValidateWeights,
+	// which requires every node to weigh at most the tolerated faulty weight.
+	NodeWeights map[uint64]uint64
+	// If positive, prepares are signed, and a proposal prepared by all nodes within this timeout
+	// is committed without waiting for commits
+	FastPathTimeout time.Duration
+	// If positive, votes are sent to a collector instead of to all nodes, and are sent to all nodes
+	// if the collector doesn't broadcast a certificate of a quorum of votes within this timeout
+	VoteCollectorTimeout time.Duration
+	// The node which collects votes, zero means the leader
+	VoteCollector uint64
+	// If true, the leader sends the IDs of the requests of proposals instead of their payloads, and followers
+	// assemble the payloads from the requests they hold, fetching the missing ones from other nodes.
+	// Requires the Assembler to assemble the same payload from the same metadata and requests at all nodes.
+	// Leaders send such pre-prepares only once a quorum of the nodes supports protocol version 2.
+	DisseminateRequests bool
+	// If positive, the leader broadcasts pre-prepares of proposals of at least this size in erasure coded chunks,
+	// one to each node, which relays it to the other nodes. Only once a quorum of the nodes supports protocol version 3.
+	ErasureCodingMinProposalSize int
+	// Number of workers verifying signatures on commits and requests, zero means the number of CPUs.
+	// If the Verifier is a BatchVerifier, they are verified in batches.
+	VerificationWorkers int
+	// Number of verifications waiting for a worker, zero means the default
+	VerificationQueueSize int
+	// The highest protocol version the node supports, zero means the version implemented by the library.
+	// Proposals switch to a higher version only once a quorum of the nodes supports it.
+	MaxProtocolVersion uint32
 
-	viewChanger *algorithm.ViewChanger
-	controller  *algorithm.Controller
-	state       *algorithm.PersistedState
-	n           uint64
+	viewChanger   *algorithm.ViewChanger
+	controller    *algorithm.Controller
+	state         *algorithm.PersistedState
+	n             uint64
+	logger        bft.Logger
+	checkpoint    *types.Checkpoint
+	versions      *algorithm.ProtocolVersions
+	speculation   *algorithm.Speculation
+	dissemination *algorithm.Dissemination
+	erasureCoding *algorithm.ErasureCodedComm
+	verification  *algorithm.VerificationPool
+
+	stopChan chan struct{}
+	running  sync.WaitGroup
+	errChan  chan error
 }
 
 func (c *Consensus) Complain(stopView bool) {
+	if c.controller.Halted() {
+		return
+	}
 	c.viewChanger.StartViewChange(stopView)
 }
 
+// OnFatalError reports the error the node halted because of.
+func (c *Consensus) OnFatalError(err *types.FatalError) {
+	select {
+	case c.errChan <- err:
+	default:
+	}
+	if c.FatalErrorHandler != nil {
+		c.FatalErrorHandler.OnFatalError(err)
+	}
+}
+
+// Err returns a channel which receives a *types.FatalError if the node halts.
+// A halted node stops voting and no longer accepts requests, but keeps running until it is stopped.
+// Must be called after Start.
+func (c *Consensus) Err() <-chan error {
+	return c.errChan
+}
+
 func (c *Consensus) Deliver(proposal types.Proposal, signatures []types.Signature) {
+	if c.Journal != nil {
+		c.Journal.RecordDelivery(proposal, signatures)
+	}
+	if c.speculation != nil {
+		c.speculation.Delivered(proposal)
+	}
 	c.Application.Deliver(proposal, signatures)
 }
 
-func (c *Consensus) Start() {
+// Start starts the consensus, and returns an error if it is misconfigured.
+func (c *Consensus) Start() error {
 	// requestTimeout := 2 * c.BatchTimeout // Request timeout should be at least as batch timeout
 	opts := algorithm.PoolOptions{
 		QueueSize:         DefaultRequestPoolSize,
@@ -67,34 +171,89 @@ func (c *Consensus) Start() {
 	}
 
 	c.n = uint64(len(c.Nodes()))
+	c.logger = algorithm.WithFields(c.Logger, "node", c.SelfID)
+
+	if c.NodeWeights != nil {
+		if err := types.ValidateWeights(c.Nodes(), c.NodeWeights, c.FaultModel); err != nil {
+			return errors.Wrap(err, "invalid node weights")
+		}
+	}
+
+	c.stopChan = make(chan struct{})
+	c.errChan = make(chan error, 1)
+	scheduler, viewChangerTicker := c.Scheduler, c.ViewChangerTicker
+	if c.Journal != nil {
+		scheduler = c.recordTicks(c.Scheduler, c.Journal.RecordSchedulerTick)
+		viewChangerTicker = c.recordTicks(c.ViewChangerTicker, c.Journal.RecordViewChangerTick)
+	}
 
 	inFlight := algorithm.InFlightData{}
+	decisions := algorithm.NewDecisionHistory(c.DecisionHistorySize)
 
 	c.state = &algorithm.PersistedState{
 		InFlightProposal: &inFlight,
 		Entries:          c.WALInitialContent,
-		Logger:           c.Logger,
+		Logger:           c.logger,
 		WAL:              c.WAL,
 	}
 
 	cpt := types.Checkpoint{}
 	cpt.Set(c.LastProposal, c.LastSignatures)
+	c.checkpoint = &cpt
+
+	c.versions = &algorithm.ProtocolVersions{
+		SelfID:     c.SelfID,
+		MaxVersion: c.MaxProtocolVersion,
+		Checkpoint: &cpt,
+		Logger:     c.logger,
+	}
+
+	if app, isSpeculative := c.Application.(bft.SpeculativeApplication); isSpeculative {
+		c.speculation = &algorithm.Speculation{Application: app, Logger: c.logger}
+	}
+
+	if c.DisseminateRequests {
+		c.dissemination = &algorithm.Dissemination{
+			Assembler: c.Assembler,
+			Inspector: c.RequestInspector,
+			Comm:      c,
+			Logger:    c.logger,
+			// Pool later
+		}
+	}
+
+	c.verification = algorithm.NewVerificationPool(c.VerificationWorkers, c.VerificationQueueSize)
+
+	if c.ErasureCodingMinProposalSize > 0 {
+		c.erasureCoding = &algorithm.ErasureCodedComm{
+			Comm:             c,
+			SelfID:           c.SelfID,
+			FaultModel:       c.FaultModel,
+			Weights:          c.NodeWeights,
+			MinProposalSize:  c.ErasureCodingMinProposalSize,
+			ProtocolVersions: c.versions,
+			Logger:           c.logger,
+		}
+	}
 
 	c.viewChanger = &algorithm.ViewChanger{
 		SelfID:      c.SelfID,
 		N:           c.n,
-		Logger:      c.Logger,
+		Logger:      c.logger,
 		Comm:        c,
 		Signer:      c.Signer,
 		Verifier:    c.Verifier,
 		Application: c,
 		Checkpoint:  &cpt,
 		InFlight:    &inFlight,
+		Decisions:   decisions,
 		// Controller later
 		// RequestsTimer later
-		Ticker:            c.ViewChangerTicker,
+		Ticker:            viewChangerTicker,
 		ResendTimeout:     c.ViewChangeResendTimeout,
 		TimeoutViewChange: c.ViewChangerTimeout,
+		FlowControl:       c.flowControl(),
+		RejectionHandler:  c.MessageRejectionHandler,
 	}
 
 	c.controller = &algorithm.Controller{
@@ -103,7 +262,7 @@ func (c *Consensus) Start() {
 		ID:               c.SelfID,
 		N:                c.n,
 		Verifier:         c.Verifier,
-		Logger:           c.Logger,
+		Logger:           c.logger,
 		Assembler:        c.Assembler,
 		Application:      c,
 		FailureDetector:  c,
@@ -112,43 +271,116 @@ func (c *Consensus) Start() {
 		Signer:           c.Signer,
 		RequestInspector: c.RequestInspector,
 		ViewChanger:      c.viewChanger,
+		Decisions:        decisions,
+		LeaderRotation:   c.LeaderRotation,
+		MessageLimits: algorithm.MessageLimits{
+			MaxProposalSize:  c.MaxProposalSize,
+			MaxViewDataSize:  c.MaxViewDataSize,
+			MaxSignatureSize: c.MaxSignatureSize,
+		},
+		FlowControl:        c.flowControl(),
+		RejectionHandler:   c.MessageRejectionHandler,
+		Authenticator:      c.MessageAuthenticator,
+		RequestTracer:      c.RequestTracer,
+		FatalErrorHandler:  c,
+		DecisionsPerLeader: c.DecisionsPerLeader,
+		FaultModel:         c.FaultModel,
+		Weights:            c.NodeWeights,
+		ProtocolVersions:   c.versions,
+		Speculation:        c.speculation,
+		InFlight:           &inFlight,
+		Dissemination:      c.dissemination,
+		ErasureCoding:      c.erasureCoding,
+		VerificationPool:   c.verification,
 	}
 
 	c.viewChanger.Synchronizer = c.controller
+	c.viewChanger.Halter = c.controller
+	c.viewChanger.LeaderRotation = c.LeaderRotation
+	c.viewChanger.FaultModel = c.FaultModel
+	c.viewChanger.Weights = c.NodeWeights
 
 	c.controller.ProposerBuilder = c.proposalMaker()
 
-	pool := algorithm.NewPool(c.Logger, c.RequestInspector, c.controller, opts)
+	pool := algorithm.NewPool(c.logger, c.RequestInspector, c.controller, opts)
 	batchBuilder := algorithm.NewBatchBuilder(pool, c.BatchSize, c.BatchTimeout)
-	leaderMonitor := algorithm.NewHeartbeatMonitor(c.Scheduler, c.Logger, algorithm.DefaultHeartbeatTimeout, c, c.controller)
+	leaderMonitor := algorithm.NewHeartbeatMonitor(scheduler, c.logger, algorithm.DefaultHeartbeatTimeout, c, c.controller)
 	c.controller.RequestPool = pool
 	c.controller.Batcher = batchBuilder
 	c.controller.LeaderMonitor = leaderMonitor
+	if c.dissemination != nil {
+		c.dissemination.Pool = pool
+	}
 
 	c.viewChanger.Controller = c.controller
 	c.viewChanger.RequestsTimer = pool
 
 	// If we delivered to the application proposal with sequence i,
 	// then we are expecting to be proposed a proposal with sequence i+1.
-	c.viewChanger.Start(c.Metadata.ViewId)
-	c.controller.Start(c.Metadata.ViewId, c.Metadata.LatestSequence+1)
+	view := c.Metadata.ViewId
+	if c.Metadata.LatestSequence > 0 {
+		view = algorithm.ViewAfterDecision(&c.Metadata, c.DecisionsPerLeader)
+	}
+	c.viewChanger.Start(view)
+	c.controller.Start(view, c.Metadata.LatestSequence+1)
+	return nil
 }
 
+// Stop stops the consensus, unless it wasn't started, or failed starting.
 func (c *Consensus) Stop() {
+	if c.controller == nil {
+		return
+	}
 	c.viewChanger.Stop()
 	c.controller.Stop()
+	select {
+	case <-c.stopChan:
+	default:
+		close(c.stopChan)
+	}
+	c.running.Wait()
+	c.verification.Close()
 }
 
 func (c *Consensus) HandleMessage(sender uint64, m *protos.Message) {
+	if c.Journal != nil {
+		c.Journal.RecordMessage(sender, m)
+	}
 	c.controller.ProcessMessages(sender, m)
 }
 
 func (c *Consensus) HandleRequest(sender uint64, req []byte) {
+	if c.Journal != nil {
+		c.Journal.RecordRequest(sender, req)
+	}
 	c.controller.HandleRequest(sender, req)
 }
 
+// recordTicks returns a channel which relays the ticks of the given channel once they are recorded.
+func (c *Consensus) recordTicks(ticks <-chan time.Time, record func(time.Time)) <-chan time.Time {
+	recorded := make(chan time.Time)
+	c.running.Add(1)
+	go func() {
+		defer c.running.Done()
+		for {
+			select {
+			case <-c.stopChan:
+				return
+			case t := <-ticks:
+				record(t)
+				select {
+				case recorded <- t:
+				case <-c.stopChan:
+					return
+				}
+			}
+		}
+	}()
+	return recorded
+}
+
 func (c *Consensus) SubmitRequest(req []byte) error {
-	c.Logger.Debugf("Submit Request: %s", c.RequestInspector.RequestID(req))
+	c.logger.Debugf("Submit Request: %s", c.RequestInspector.RequestID(req))
 	return c.controller.SubmitRequest(req)
 }
 
@@ -158,21 +390,79 @@ func (c *Consensus) BroadcastConsensus(m *protos.Message) {
 		if c.SelfID == node {
 			continue
 		}
-		c.Comm.SendConsensus(node, m)
+		c.SendConsensus(node, m)
+	}
+}
+
+func (c *Consensus) SendConsensus(targetID uint64, m *protos.Message) {
+	if c.controller.Halted() {
+		return
 	}
+	m = c.versions.Stamp(m)
+	if c.MessageAuthenticator != nil {
+		m = algorithm.AuthenticateMessage(c.MessageAuthenticator, targetID, m)
+	}
+	c.Comm.SendConsensus(targetID, m)
+}
+
+// ProtocolVersion returns the protocol version in effect, which is the version of the last decision.
+func (c *Consensus) ProtocolVersion() uint32 {
+	return c.versions.Active()
+}
+
+// VerificationMetrics returns a snapshot of the queue of the workers verifying signatures and requests.
+func (c *Consensus) VerificationMetrics() types.VerificationMetrics {
+	return c.verification.Metrics()
+}
+
+// IncompatibleNodes returns the nodes whose last message was rejected
+// because the node doesn't support its protocol version, or the node doesn't support the version in effect.
+func (c *Consensus) IncompatibleNodes() []uint64 {
+	return c.versions.Incompatible()
 }
 
 func (c *Consensus) proposalMaker() *algorithm.ProposalMaker {
+	var comm algorithm.Comm = c
+	if c.erasureCoding != nil {
+		comm = c.erasureCoding
+	}
 	return &algorithm.ProposalMaker{
-		State:           c.state,
-		Comm:            c,
-		Decider:         c.controller,
-		Logger:          c.Logger,
-		Signer:          c.Signer,
-		SelfID:          c.SelfID,
-		Sync:            c.controller,
-		FailureDetector: c,
-		Verifier:        c.Verifier,
-		N:               c.n,
+		State:            c.state,
+		Comm:             comm,
+		Decider:          c.controller,
+		Logger:           c.logger,
+		Signer:           c.Signer,
+		SelfID:           c.SelfID,
+		Sync:             c.controller,
+		FailureDetector:  c,
+		Verifier:         c.Verifier,
+		N:                c.n,
+		FlowControl:      c.flowControl(),
+		RejectionHandler: c.MessageRejectionHandler,
+		Halter:           c.controller,
+
+		DecisionsPerLeader: c.DecisionsPerLeader,
+		LeaderBlacklisting: c.LeaderBlacklisting,
+		LeaderRotation:     c.LeaderRotation,
+		Checkpoint:         c.checkpoint,
+		MetadataExtender:   c.MetadataExtender,
+		TimestampSkew:      c.TimestampSkew,
+		FaultModel:         c.FaultModel,
+		Weights:            c.NodeWeights,
+		ProtocolVersions:   c.versions,
+		Speculation:        c.speculation,
+		FastPathTimeout:    c.FastPathTimeout,
+		InFlight:           c.state.InFlightProposal,
+		CollectorTimeout:   c.VoteCollectorTimeout,
+		Collector:          c.VoteCollector,
+		Dissemination:      c.dissemination,
+		VerificationPool:   c.verification,
+	}
+}
+
+func (c *Consensus) flowControl() algorithm.FlowControl {
+	return algorithm.FlowControl{
+		QueueSize: c.IncomingMessageQueueSize,
+		RateLimit: c.IncomingMessageRateLimit,
 	}
 }
@@ -0,0 +1,173 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package erasure
+
+import (
+	"github.com/pkg/errors"
+)
+
+// ReedSolomon splits data into shards, any DataShards of which suffice to reconstruct it.
+// The first DataShards shards are the data itself, and the rest are parities computed over GF(2^8)
+// with a Cauchy matrix, so the code is systematic and every square submatrix of the encoding matrix is invertible.
+type ReedSolomon struct {
+	dataShards  int
+	totalShards int
+	// The rows of the encoding matrix, the identity followed by the Cauchy matrix
+	matrix [][]byte
+}
+
+// NewReedSolomon returns a code of totalShards shards, any dataShards of which suffice to reconstruct the data.
+func NewReedSolomon(dataShards, totalShards int) (*ReedSolomon, error) {
+	if dataShards <= 0 {
+		return nil, errors.Errorf("data shards must be positive, got %d", dataShards)
+	}
+	if totalShards < dataShards {
+		return nil, errors.Errorf("total shards %d are fewer than data shards %d", totalShards, dataShards)
+	}
+	if totalShards > 256 {
+		return nil, errors.Errorf("total shards %d exceed 256", totalShards)
+	}
+
+	matrix := make([][]byte, totalShards)
+	for i := range matrix {
+		matrix[i] = make([]byte, dataShards)
+		if i < dataShards {
+			matrix[i][i] = 1
+			continue
+		}
+		// x_i = i and y_j = j are distinct for parity rows, so x_i + y_j is never zero
+		for j := range matrix[i] {
+			matrix[i][j] = gfInv(byte(i) ^ byte(j))
+		}
+	}
+
+	return &ReedSolomon{
+		dataShards:  dataShards,
+		totalShards: totalShards,
+		matrix:      matrix,
+	}, nil
+}
+
+// DataShards returns the number of shards which suffice to reconstruct the data.
+func (rs *ReedSolomon) DataShards() int {
+	return rs.dataShards
+}
+
+// TotalShards returns the number of shards the data is split into.
+func (rs *ReedSolomon) TotalShards() int {
+	return rs.totalShards
+}
+
+// Encode splits the data into shards of equal size, padding the last data shard with zeros.
+func (rs *ReedSolomon) Encode(data []byte) [][]byte {
+	shardSize := (len(data) + rs.dataShards - 1) / rs.dataShards
+	if shardSize == 0 {
+		shardSize = 1
+	}
+
+	shards := make([][]byte, rs.totalShards)
+	padded := make([]byte, shardSize*rs.dataShards)
+	copy(padded, data)
+	for i := 0; i < rs.dataShards; i++ {
+		shards[i] = padded[i*shardSize : (i+1)*shardSize]
+	}
+	for i := rs.dataShards; i < rs.totalShards; i++ {
+		shards[i] = make([]byte, shardSize)
+		for j := 0; j < rs.dataShards; j++ {
+			gfMulAdd(shards[i], shards[j], rs.matrix[i][j])
+		}
+	}
+	return shards
+}
+
+// Decode reconstructs data of the given size from the shards, where missing shards are nil.
+func (rs *ReedSolomon) Decode(shards [][]byte, size int) ([]byte, error) {
+	if len(shards) != rs.totalShards {
+		return nil, errors.Errorf("got %d shards but the code has %d", len(shards), rs.totalShards)
+	}
+
+	var indices []int
+	shardSize := -1
+	for i, shard := range shards {
+		if shard == nil {
+			continue
+		}
+		if shardSize == -1 {
+			shardSize = len(shard)
+		}
+		if len(shard) != shardSize {
+			return nil, errors.Errorf("shard %d is of size %d but shard %d is of size %d", i, len(shard), indices[0], shardSize)
+		}
+		if len(indices) < rs.dataShards {
+			indices = append(indices, i)
+		}
+	}
+	if len(indices) < rs.dataShards {
+		return nil, errors.Errorf("got %d shards but %d are needed", len(indices), rs.dataShards)
+	}
+	if size > shardSize*rs.dataShards {
+		return nil, errors.Errorf("size %d exceeds the %d bytes in the data shards", size, shardSize*rs.dataShards)
+	}
+
+	sub := make([][]byte, rs.dataShards)
+	for r, i := range indices {
+		sub[r] = append([]byte(nil), rs.matrix[i]...)
+	}
+	decoding, err := invert(sub)
+	if err != nil {
+		return nil, err
+	}
+
+	data := make([]byte, shardSize*rs.dataShards)
+	for j := 0; j < rs.dataShards; j++ {
+		dataShard := data[j*shardSize : (j+1)*shardSize]
+		for r, i := range indices {
+			gfMulAdd(dataShard, shards[i], decoding[j][r])
+		}
+	}
+	return data[:size], nil
+}
+
+// invert inverts the square matrix with Gauss-Jordan elimination, modifying it.
+func invert(m [][]byte) ([][]byte, error) {
+	n := len(m)
+	inv := make([][]byte, n)
+	for i := range inv {
+		inv[i] = make([]byte, n)
+		inv[i][i] = 1
+	}
+
+	for col := 0; col < n; col++ {
+		pivot := -1
+		for row := col; row < n; row++ {
+			if m[row][col] != 0 {
+				pivot = row
+				break
+			}
+		}
+		if pivot == -1 {
+			return nil, errors.New("matrix is singular")
+		}
+		m[col], m[pivot] = m[pivot], m[col]
+		inv[col], inv[pivot] = inv[pivot], inv[col]
+
+		scale := gfInv(m[col][col])
+		for j := 0; j < n; j++ {
+			m[col][j] = gfMul(m[col][j], scale)
+			inv[col][j] = gfMul(inv[col][j], scale)
+		}
+
+		for row := 0; row < n; row++ {
+			if row == col || m[row][col] == 0 {
+				continue
+			}
+			factor := m[row][col]
+			gfMulAdd(m[row], m[col], factor)
+			gfMulAdd(inv[row], inv[col], factor)
+		}
+	}
+	return inv, nil
+}
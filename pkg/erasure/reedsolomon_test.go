@@ -0,0 +1,71 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package erasure_test
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/SmartBFT-Go/consensus/pkg/erasure"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestReedSolomon(t *testing.T) {
+	for _, testCase := range []struct {
+		description string
+		dataShards  int
+		totalShards int
+		size        int
+	}{
+		{description: "single node", dataShards: 1, totalShards: 1, size: 10},
+		{description: "four nodes", dataShards: 2, totalShards: 4, size: 1001},
+		{description: "seven nodes", dataShards: 3, totalShards: 7, size: 4096},
+		{description: "many nodes", dataShards: 34, totalShards: 100, size: 50000},
+		{description: "empty data", dataShards: 2, totalShards: 4, size: 0},
+	} {
+		testCase := testCase
+		t.Run(testCase.description, func(t *testing.T) {
+			rs, err := erasure.NewReedSolomon(testCase.dataShards, testCase.totalShards)
+			assert.NoError(t, err)
+
+			data := make([]byte, testCase.size)
+			rand.Read(data)
+			shards := rs.Encode(data)
+			assert.Len(t, shards, testCase.totalShards)
+
+			// Any data shards suffice, so we keep a random subset of them
+			for i := 0; i < 10; i++ {
+				available := make([][]byte, len(shards))
+				for _, index := range rand.Perm(len(shards))[:testCase.dataShards] {
+					available[index] = shards[index]
+				}
+				decoded, err := rs.Decode(available, len(data))
+				assert.NoError(t, err)
+				assert.Equal(t, data, decoded)
+			}
+		})
+	}
+}
+
+func TestReedSolomonErrors(t *testing.T) {
+	_, err := erasure.NewReedSolomon(0, 4)
+	assert.EqualError(t, err, "data shards must be positive, got 0")
+	_, err = erasure.NewReedSolomon(3, 2)
+	assert.EqualError(t, err, "total shards 2 are fewer than data shards 3")
+	_, err = erasure.NewReedSolomon(2, 257)
+	assert.EqualError(t, err, "total shards 257 exceed 256")
+
+	rs, err := erasure.NewReedSolomon(2, 4)
+	assert.NoError(t, err)
+	shards := rs.Encode([]byte("hello world"))
+
+	_, err = rs.Decode(shards[:3], 11)
+	assert.EqualError(t, err, "got 3 shards but the code has 4")
+	_, err = rs.Decode([][]byte{nil, nil, nil, shards[3]}, 11)
+	assert.EqualError(t, err, "got 1 shards but 2 are needed")
+	_, err = rs.Decode([][]byte{shards[0], nil, nil, shards[3][:2]}, 11)
+	assert.EqualError(t, err, "shard 3 is of size 2 but shard 0 is of size 6")
+}
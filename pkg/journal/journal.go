@@ -0,0 +1,204 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package journal
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/SmartBFT-Go/consensus/pkg/types"
+	protos "github.com/SmartBFT-Go/consensus/smartbftprotos"
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+)
+
+// MaxEntrySize is the largest entry a Reader accepts.
+const MaxEntrySize = 100 * 1024 * 1024
+
+// Recorder is an api.Journal which appends each entry to a stream,
+// as a protobuf message prefixed by its varint encoded length.
+// Entries are flushed as they are recorded, so the journal survives a crash of the node.
+type Recorder struct {
+	lock   sync.Mutex
+	w      *bufio.Writer
+	closer io.Closer
+	err    error
+	now    func() time.Time
+}
+
+// NewRecorder creates a recorder which writes to the given writer.
+func NewRecorder(w io.Writer) *Recorder {
+	return &Recorder{
+		w:   bufio.NewWriter(w),
+		now: time.Now,
+	}
+}
+
+// Create creates a recorder which appends to the file at the given path,
+// creating the file if it doesn't exist.
+func Create(path string) (*Recorder, error) {
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed opening journal %s", path)
+	}
+	r := NewRecorder(f)
+	r.closer = f
+	return r, nil
+}
+
+// RecordMessage records a consensus message received from the sender.
+func (r *Recorder) RecordMessage(sender uint64, m *protos.Message) {
+	r.record(&protos.JournalEntry{
+		Event: &protos.JournalEntry_Message{
+			Message: &protos.JournalMessage{Sender: sender, Message: m},
+		},
+	})
+}
+
+// RecordRequest records a request received from the sender.
+func (r *Recorder) RecordRequest(sender uint64, req []byte) {
+	r.record(&protos.JournalEntry{
+		Event: &protos.JournalEntry_Request{
+			Request: &protos.JournalRequest{Sender: sender, Request: req},
+		},
+	})
+}
+
+// RecordSchedulerTick records a tick of the scheduler.
+func (r *Recorder) RecordSchedulerTick(t time.Time) {
+	r.recordTick(protos.JournalTick_SCHEDULER, t)
+}
+
+// RecordViewChangerTick records a tick of the view changer.
+func (r *Recorder) RecordViewChangerTick(t time.Time) {
+	r.recordTick(protos.JournalTick_VIEW_CHANGER, t)
+}
+
+// RecordDelivery records a decision delivered to the application.
+func (r *Recorder) RecordDelivery(proposal types.Proposal, signatures []types.Signature) {
+	delivery := &protos.JournalDelivery{
+		Proposal: &protos.Proposal{
+			Header:               proposal.Header,
+			Payload:              proposal.Payload,
+			Metadata:             proposal.Metadata,
+			VerificationSequence: uint64(proposal.VerificationSequence),
+		},
+	}
+	for _, sig := range signatures {
+		delivery.Signatures = append(delivery.Signatures, &protos.Signature{
+			Signer: sig.Id,
+			Value:  sig.Value,
+			Msg:    sig.Msg,
+		})
+	}
+	r.record(&protos.JournalEntry{
+		Event: &protos.JournalEntry_Delivery{Delivery: delivery},
+	})
+}
+
+// Err returns the error that failed recording entries, if any.
+// Once recording fails, all subsequent entries are discarded.
+func (r *Recorder) Err() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+	return r.err
+}
+
+// Close closes the underlying file, if the recorder was created by Create.
+func (r *Recorder) Close() error {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.err == nil {
+		r.err = r.w.Flush()
+	}
+	if r.closer == nil {
+		return r.err
+	}
+	if err := r.closer.Close(); err != nil && r.err == nil {
+		r.err = err
+	}
+	return r.err
+}
+
+func (r *Recorder) recordTick(source protos.JournalTick_Source, t time.Time) {
+	r.record(&protos.JournalEntry{
+		Event: &protos.JournalEntry_Tick{
+			Tick: &protos.JournalTick{Source: source, Time: t.UnixNano()},
+		},
+	})
+}
+
+func (r *Recorder) record(entry *protos.JournalEntry) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if r.err != nil {
+		return
+	}
+
+	entry.Timestamp = r.now().UnixNano()
+	payload, err := proto.Marshal(entry)
+	if err != nil {
+		r.err = errors.Wrap(err, "failed marshaling journal entry")
+		return
+	}
+
+	header := make([]byte, binary.MaxVarintLen64)
+	n := binary.PutUvarint(header, uint64(len(payload)))
+	if _, err := r.w.Write(header[:n]); err != nil {
+		r.err = errors.Wrap(err, "failed writing journal entry")
+		return
+	}
+	if _, err := r.w.Write(payload); err != nil {
+		r.err = errors.Wrap(err, "failed writing journal entry")
+		return
+	}
+	if err := r.w.Flush(); err != nil {
+		r.err = errors.Wrap(err, "failed writing journal entry")
+	}
+}
+
+// Reader reads the entries of a journal written by a Recorder.
+type Reader struct {
+	r *bufio.Reader
+}
+
+// NewReader creates a reader of the journal in the given stream.
+func NewReader(r io.Reader) *Reader {
+	return &Reader{r: bufio.NewReader(r)}
+}
+
+// Next returns the next entry of the journal, or io.EOF if there are no more entries.
+// A journal that ends with a partially written entry, as happens when the node crashes
+// in the middle of recording it, returns io.ErrUnexpectedEOF.
+func (jr *Reader) Next() (*protos.JournalEntry, error) {
+	size, err := binary.ReadUvarint(jr.r)
+	if err != nil {
+		return nil, err
+	}
+	if size > MaxEntrySize {
+		return nil, errors.Errorf("journal entry size is %d but the limit is %d", size, MaxEntrySize)
+	}
+
+	payload := make([]byte, size)
+	if _, err := io.ReadFull(jr.r, payload); err != nil {
+		if err == io.EOF {
+			return nil, io.ErrUnexpectedEOF
+		}
+		return nil, err
+	}
+
+	entry := &protos.JournalEntry{}
+	if err := proto.Unmarshal(payload, entry); err != nil {
+		return nil, errors.Wrap(err, "failed unmarshaling journal entry")
+	}
+	return entry, nil
+}
@@ -0,0 +1,117 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package journal_test
+
+import (
+	"bytes"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/SmartBFT-Go/consensus/pkg/api"
+	"github.com/SmartBFT-Go/consensus/pkg/journal"
+	"github.com/SmartBFT-Go/consensus/pkg/types"
+	protos "github.com/SmartBFT-Go/consensus/smartbftprotos"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ api.Journal = &journal.Recorder{}
+
+var heartbeat = &protos.Message{
+	Content: &protos.Message_HeartBeat{
+		HeartBeat: &protos.HeartBeat{View: 1},
+	},
+}
+
+func TestRecordAndRead(t *testing.T) {
+	buff := &bytes.Buffer{}
+	recorder := journal.NewRecorder(buff)
+
+	tick := time.Unix(100, 5)
+	recorder.RecordMessage(1, heartbeat)
+	recorder.RecordRequest(2, []byte{1, 2, 3})
+	recorder.RecordSchedulerTick(tick)
+	recorder.RecordViewChangerTick(tick)
+	recorder.RecordDelivery(types.Proposal{Payload: []byte{4}, VerificationSequence: 1}, []types.Signature{{Id: 3, Value: []byte{5}}})
+	assert.NoError(t, recorder.Close())
+
+	reader := journal.NewReader(buff)
+	var entries []*protos.JournalEntry
+	for {
+		entry, err := reader.Next()
+		if err == io.EOF {
+			break
+		}
+		assert.NoError(t, err)
+		assert.NotZero(t, entry.Timestamp)
+		entry.Timestamp = 0
+		entries = append(entries, entry)
+	}
+
+	expected := []*protos.JournalEntry{
+		{Event: &protos.JournalEntry_Message{Message: &protos.JournalMessage{Sender: 1, Message: heartbeat}}},
+		{Event: &protos.JournalEntry_Request{Request: &protos.JournalRequest{Sender: 2, Request: []byte{1, 2, 3}}}},
+		{Event: &protos.JournalEntry_Tick{Tick: &protos.JournalTick{Source: protos.JournalTick_SCHEDULER, Time: tick.UnixNano()}}},
+		{Event: &protos.JournalEntry_Tick{Tick: &protos.JournalTick{Source: protos.JournalTick_VIEW_CHANGER, Time: tick.UnixNano()}}},
+		{Event: &protos.JournalEntry_Delivery{Delivery: &protos.JournalDelivery{
+			Proposal:   &protos.Proposal{Payload: []byte{4}, VerificationSequence: 1},
+			Signatures: []*protos.Signature{{Signer: 3, Value: []byte{5}}},
+		}}},
+	}
+	assert.Len(t, entries, len(expected))
+	for i := range expected {
+		assert.True(t, proto.Equal(expected[i], entries[i]), "entry %d: %v", i, entries[i])
+	}
+}
+
+func TestReadTruncatedJournal(t *testing.T) {
+	buff := &bytes.Buffer{}
+	recorder := journal.NewRecorder(buff)
+	recorder.RecordMessage(1, heartbeat)
+	recorder.RecordMessage(2, heartbeat)
+
+	// Crash in the middle of writing the second entry
+	truncated := buff.Bytes()[:buff.Len()-1]
+	reader := journal.NewReader(bytes.NewReader(truncated))
+
+	entry, err := reader.Next()
+	assert.NoError(t, err)
+	assert.Equal(t, uint64(1), entry.GetMessage().Sender)
+
+	_, err = reader.Next()
+	assert.Equal(t, io.ErrUnexpectedEOF, err)
+}
+
+func TestCreateAppends(t *testing.T) {
+	testDir, err := ioutil.TempDir("", t.Name())
+	assert.NoError(t, err)
+	defer os.RemoveAll(testDir)
+	path := filepath.Join(testDir, "journal")
+
+	for sender := uint64(1); sender <= 2; sender++ {
+		recorder, err := journal.Create(path)
+		assert.NoError(t, err)
+		recorder.RecordMessage(sender, heartbeat)
+		assert.NoError(t, recorder.Close())
+	}
+
+	f, err := os.Open(path)
+	assert.NoError(t, err)
+	defer f.Close()
+
+	reader := journal.NewReader(f)
+	for sender := uint64(1); sender <= 2; sender++ {
+		entry, err := reader.Next()
+		assert.NoError(t, err)
+		assert.Equal(t, sender, entry.GetMessage().Sender)
+	}
+	_, err = reader.Next()
+	assert.Equal(t, io.EOF, err)
+}
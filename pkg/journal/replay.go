@@ -0,0 +1,135 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package journal
+
+import (
+	"io"
+	"time"
+
+	"github.com/SmartBFT-Go/consensus/pkg/api"
+	"github.com/SmartBFT-Go/consensus/pkg/types"
+	protos "github.com/SmartBFT-Go/consensus/smartbftprotos"
+	"github.com/golang/protobuf/proto"
+	"github.com/pkg/errors"
+)
+
+const DefaultReplayTimeout = 10 * time.Second
+
+// Handler is fed the messages and requests of a journal.
+// It is implemented by consensus.Consensus.
+type Handler interface {
+	HandleMessage(sender uint64, m *protos.Message)
+	HandleRequest(sender uint64, req []byte)
+}
+
+// Replayer feeds a recorded journal into a fresh node,
+// which is started with the same configuration and WAL contents as the recorded node had.
+// The node should be configured with the tick channels of the Replayer instead of real tickers,
+// and with the Replayer as its application.
+//
+// Replay waits for the node to deliver each decision the journal recorded before it feeds further entries,
+// and fails if the node delivers a different decision.
+// Timers that are not driven by ticks, such as the request pool timeouts, are not reproduced.
+type Replayer struct {
+	handler           Handler
+	application       api.Application
+	scheduler         chan time.Time
+	viewChangerTicker chan time.Time
+	deliveries        chan types.Proposal
+
+	// Timeout bounds the wait for the node to consume a tick, or to deliver a decision.
+	Timeout time.Duration
+}
+
+// NewReplayer creates a replayer which feeds the handler,
+// and passes the decisions delivered by the node to the given application.
+func NewReplayer(handler Handler, application api.Application) *Replayer {
+	return &Replayer{
+		handler:           handler,
+		application:       application,
+		scheduler:         make(chan time.Time),
+		viewChangerTicker: make(chan time.Time),
+		deliveries:        make(chan types.Proposal, 1),
+		Timeout:           DefaultReplayTimeout,
+	}
+}
+
+// Scheduler returns the channel the node should use as its scheduler ticker.
+func (r *Replayer) Scheduler() <-chan time.Time {
+	return r.scheduler
+}
+
+// ViewChangerTicker returns the channel the node should use as its view changer ticker.
+func (r *Replayer) ViewChangerTicker() <-chan time.Time {
+	return r.viewChangerTicker
+}
+
+// Deliver passes the decision to the application, and lets Replay compare it with the recorded decision.
+func (r *Replayer) Deliver(proposal types.Proposal, signatures []types.Signature) {
+	r.application.Deliver(proposal, signatures)
+	r.deliveries <- proposal
+}
+
+// Replay feeds the node all entries of the journal, in the order they were recorded.
+// It returns the number of entries replayed.
+func (r *Replayer) Replay(jr *Reader) (int, error) {
+	var count int
+	for {
+		entry, err := jr.Next()
+		if err == io.EOF {
+			return count, nil
+		}
+		if err != nil {
+			return count, errors.Wrapf(err, "failed reading entry %d", count)
+		}
+		if err := r.replay(entry); err != nil {
+			return count, errors.Wrapf(err, "failed replaying entry %d", count)
+		}
+		count++
+	}
+}
+
+func (r *Replayer) replay(entry *protos.JournalEntry) error {
+	switch event := entry.GetEvent().(type) {
+	case *protos.JournalEntry_Message:
+		r.handler.HandleMessage(event.Message.Sender, event.Message.Message)
+	case *protos.JournalEntry_Request:
+		r.handler.HandleRequest(event.Request.Sender, event.Request.Request)
+	case *protos.JournalEntry_Tick:
+		ticker := r.scheduler
+		if event.Tick.Source == protos.JournalTick_VIEW_CHANGER {
+			ticker = r.viewChangerTicker
+		}
+		select {
+		case ticker <- time.Unix(0, event.Tick.Time):
+		case <-time.After(r.Timeout):
+			return errors.Errorf("%s tick was not consumed within %v", event.Tick.Source, r.Timeout)
+		}
+	case *protos.JournalEntry_Delivery:
+		return r.awaitDelivery(event.Delivery.Proposal)
+	default:
+		return errors.New("empty entry")
+	}
+	return nil
+}
+
+func (r *Replayer) awaitDelivery(recorded *protos.Proposal) error {
+	select {
+	case delivered := <-r.deliveries:
+		replayed := &protos.Proposal{
+			Header:               delivered.Header,
+			Payload:              delivered.Payload,
+			Metadata:             delivered.Metadata,
+			VerificationSequence: uint64(delivered.VerificationSequence),
+		}
+		if !proto.Equal(recorded, replayed) {
+			return errors.Errorf("replay diverged, delivered %v but recorded %v", replayed, recorded)
+		}
+		return nil
+	case <-time.After(r.Timeout):
+		return errors.Errorf("recorded decision was not delivered within %v", r.Timeout)
+	}
+}
@@ -0,0 +1,28 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package logging
+
+import (
+	"github.com/SmartBFT-Go/consensus/pkg/api"
+	"go.uber.org/zap"
+)
+
+// ZapLogger adapts a zap SugaredLogger to an api.StructuredLogger.
+// Contextual fields are encoded by the encoder of the underlying zap core,
+// so with a JSON encoder every entry can be parsed along with its node, view and sequence.
+type ZapLogger struct {
+	*zap.SugaredLogger
+}
+
+// NewZapLogger returns a ZapLogger which logs with the given zap logger.
+func NewZapLogger(logger *zap.SugaredLogger) *ZapLogger {
+	return &ZapLogger{SugaredLogger: logger}
+}
+
+// With returns a child logger which adds the given alternating keys and values to every entry.
+func (zl *ZapLogger) With(keysAndValues ...interface{}) api.StructuredLogger {
+	return &ZapLogger{SugaredLogger: zl.SugaredLogger.With(keysAndValues...)}
+}
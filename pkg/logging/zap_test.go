@@ -0,0 +1,52 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package logging_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/SmartBFT-Go/consensus/pkg/api"
+	"github.com/SmartBFT-Go/consensus/pkg/logging"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+	"go.uber.org/zap/zapcore"
+)
+
+var _ api.StructuredLogger = &logging.ZapLogger{}
+
+func TestZapLoggerWith(t *testing.T) {
+	buff := &bytes.Buffer{}
+	encoderConfig := zap.NewProductionEncoderConfig()
+	encoderConfig.TimeKey = ""
+	core := zapcore.NewCore(zapcore.NewJSONEncoder(encoderConfig), zapcore.AddSync(buff), zapcore.DebugLevel)
+	logger := logging.NewZapLogger(zap.New(core).Sugar())
+
+	nodeLogger := logger.With("node", 3)
+	nodeLogger.With("view", 1, "seq", 5).Infof("Processed %s", "prepare")
+	nodeLogger.Warnf("Done")
+
+	decoder := json.NewDecoder(buff)
+
+	var entry map[string]interface{}
+	assert.NoError(t, decoder.Decode(&entry))
+	assert.Equal(t, map[string]interface{}{
+		"level": "info",
+		"msg":   "Processed prepare",
+		"node":  float64(3),
+		"view":  float64(1),
+		"seq":   float64(5),
+	}, entry)
+
+	entry = nil
+	assert.NoError(t, decoder.Decode(&entry))
+	assert.Equal(t, map[string]interface{}{
+		"level": "warn",
+		"msg":   "Done",
+		"node":  float64(3),
+	}, entry)
+}
@@ -0,0 +1,93 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package rotation
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+
+	"github.com/SmartBFT-Go/consensus/pkg/api"
+)
+
+// RoundRobin rotates the leadership among all nodes in the order of their IDs.
+type RoundRobin struct{}
+
+// Leader returns the node at the position of the view, modulo the number of nodes.
+func (RoundRobin) Leader(view uint64, nodes []uint64) uint64 {
+	return nodes[view%uint64(len(nodes))]
+}
+
+// Blacklisting selects the leader among the nodes which are not blacklisted, using the underlying rotation.
+// The blacklist must be agreed on by all nodes, for example by deriving it from the metadata of the latest decision.
+// If all nodes are blacklisted, none of them is skipped.
+// Leader blacklisting in the consensus configuration applies it with the blacklist kept in the decision metadata,
+// so when it is enabled, the configured rotation should not be wrapped in it as well.
+type Blacklisting struct {
+	// Rotation selects the leader among the nodes which are not blacklisted, round robin if not set
+	Rotation api.LeaderRotation
+	// Blacklist returns the nodes to skip
+	Blacklist func() []uint64
+}
+
+// Leader returns the leader of the view among the nodes which are not blacklisted.
+func (b *Blacklisting) Leader(view uint64, nodes []uint64) uint64 {
+	blacklisted := make(map[uint64]struct{})
+	for _, node := range b.Blacklist() {
+		blacklisted[node] = struct{}{}
+	}
+
+	candidates := make([]uint64, 0, len(nodes))
+	for _, node := range nodes {
+		if _, isBlacklisted := blacklisted[node]; !isBlacklisted {
+			candidates = append(candidates, node)
+		}
+	}
+	if len(candidates) == 0 {
+		candidates = nodes
+	}
+
+	if b.Rotation == nil {
+		return RoundRobin{}.Leader(view, candidates)
+	}
+	return b.Rotation.Leader(view, candidates)
+}
+
+// ReputationWeighted selects the leader of each view pseudo-randomly,
+// with a probability proportional to the reputation of each node.
+// The choice depends only on the view and the reputations, so all nodes which agree on the reputations,
+// for example by deriving them from the decisions they delivered, agree on the leader.
+// Nodes without reputation are never selected, unless no node has any reputation,
+// in which case the leadership rotates round robin.
+type ReputationWeighted struct {
+	Reputation func(node uint64) uint64
+}
+
+// Leader returns the leader of the view, chosen by the reputation of the nodes.
+func (rw *ReputationWeighted) Leader(view uint64, nodes []uint64) uint64 {
+	reputations := make([]uint64, len(nodes))
+	var total uint64
+	for i, node := range nodes {
+		reputations[i] = rw.Reputation(node)
+		total += reputations[i]
+	}
+	if total == 0 {
+		return RoundRobin{}.Leader(view, nodes)
+	}
+
+	viewBytes := make([]byte, 8)
+	binary.BigEndian.PutUint64(viewBytes, view)
+	digest := sha256.Sum256(viewBytes)
+	point := binary.BigEndian.Uint64(digest[:8]) % total
+
+	for i, node := range nodes {
+		if point < reputations[i] {
+			return node
+		}
+		point -= reputations[i]
+	}
+	// Unreachable, as the point is smaller than the total reputation
+	return nodes[len(nodes)-1]
+}
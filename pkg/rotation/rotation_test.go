@@ -0,0 +1,84 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package rotation_test
+
+import (
+	"testing"
+
+	"github.com/SmartBFT-Go/consensus/pkg/api"
+	"github.com/SmartBFT-Go/consensus/pkg/rotation"
+	"github.com/stretchr/testify/assert"
+)
+
+var (
+	_ api.LeaderRotation = rotation.RoundRobin{}
+	_ api.LeaderRotation = &rotation.Blacklisting{}
+	_ api.LeaderRotation = &rotation.ReputationWeighted{}
+)
+
+var nodes = []uint64{1, 2, 3, 4}
+
+func leaders(r api.LeaderRotation, views uint64) []uint64 {
+	var leaders []uint64
+	for view := uint64(0); view < views; view++ {
+		leaders = append(leaders, r.Leader(view, nodes))
+	}
+	return leaders
+}
+
+func TestRoundRobin(t *testing.T) {
+	assert.Equal(t, []uint64{1, 2, 3, 4, 1, 2}, leaders(rotation.RoundRobin{}, 6))
+}
+
+func TestBlacklisting(t *testing.T) {
+	var blacklist []uint64
+	r := &rotation.Blacklisting{
+		Blacklist: func() []uint64 {
+			return blacklist
+		},
+	}
+
+	assert.Equal(t, []uint64{1, 2, 3, 4, 1, 2}, leaders(r, 6))
+
+	blacklist = []uint64{2}
+	assert.Equal(t, []uint64{1, 3, 4, 1, 3, 4}, leaders(r, 6))
+
+	blacklist = []uint64{1, 2, 3, 4}
+	assert.Equal(t, []uint64{1, 2, 3, 4, 1, 2}, leaders(r, 6))
+
+	r.Rotation = &rotation.ReputationWeighted{
+		Reputation: func(node uint64) uint64 {
+			return node
+		},
+	}
+	blacklist = []uint64{4}
+	assert.NotContains(t, leaders(r, 100), uint64(4))
+}
+
+func TestReputationWeighted(t *testing.T) {
+	reputation := map[uint64]uint64{1: 0, 2: 1, 3: 3, 4: 0}
+	r := &rotation.ReputationWeighted{
+		Reputation: func(node uint64) uint64 {
+			return reputation[node]
+		},
+	}
+
+	counts := make(map[uint64]int)
+	for _, leader := range leaders(r, 4000) {
+		counts[leader]++
+	}
+	assert.Zero(t, counts[1])
+	assert.Zero(t, counts[4])
+	assert.InDelta(t, 1000, counts[2], 150)
+	assert.InDelta(t, 3000, counts[3], 150)
+
+	// The choice is deterministic
+	assert.Equal(t, leaders(r, 100), leaders(r, 100))
+
+	// Without any reputation, the leadership rotates round robin
+	reputation = map[uint64]uint64{}
+	assert.Equal(t, []uint64{1, 2, 3, 4, 1, 2}, leaders(r, 6))
+}
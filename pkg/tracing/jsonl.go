@@ -0,0 +1,49 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package tracing
+
+import (
+	"encoding/json"
+	"io"
+	"sync"
+
+	"github.com/SmartBFT-Go/consensus/pkg/types"
+	"github.com/pkg/errors"
+)
+
+// JSONLinesExporter is an api.RequestTracer which writes each request event
+// as a JSON object in a line of its own.
+type JSONLinesExporter struct {
+	lock    sync.Mutex
+	encoder *json.Encoder
+	err     error
+}
+
+// NewJSONLinesExporter creates an exporter which writes to the given writer.
+func NewJSONLinesExporter(w io.Writer) *JSONLinesExporter {
+	return &JSONLinesExporter{encoder: json.NewEncoder(w)}
+}
+
+// TraceRequest writes the event.
+// Once a write fails, all subsequent events are discarded.
+func (e *JSONLinesExporter) TraceRequest(event types.RequestEvent) {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+
+	if e.err != nil {
+		return
+	}
+	if err := e.encoder.Encode(event); err != nil {
+		e.err = errors.Wrap(err, "failed writing request event")
+	}
+}
+
+// Err returns the error that failed writing events, if any.
+func (e *JSONLinesExporter) Err() error {
+	e.lock.Lock()
+	defer e.lock.Unlock()
+	return e.err
+}
@@ -0,0 +1,74 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package tracing_test
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/SmartBFT-Go/consensus/pkg/api"
+	"github.com/SmartBFT-Go/consensus/pkg/tracing"
+	"github.com/SmartBFT-Go/consensus/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+var _ api.RequestTracer = &tracing.JSONLinesExporter{}
+
+func TestJSONLinesExporter(t *testing.T) {
+	buff := &bytes.Buffer{}
+	exporter := tracing.NewJSONLinesExporter(buff)
+
+	now := time.Date(2019, 12, 1, 10, 0, 0, 0, time.UTC)
+	events := []types.RequestEvent{
+		{Time: now, Node: 2, Type: types.RequestSubmitted, Request: types.RequestInfo{ClientID: "alice", ID: "1"}},
+		{Time: now, Node: 2, Type: types.RequestForwarded, Request: types.RequestInfo{ClientID: "alice", ID: "1"}, Leader: 1},
+		{Time: now, Node: 2, Type: types.RequestCommitted, Request: types.RequestInfo{ClientID: "alice", ID: "1"}, Sequence: 5},
+	}
+	for _, event := range events {
+		exporter.TraceRequest(event)
+	}
+	assert.NoError(t, exporter.Err())
+
+	scanner := bufio.NewScanner(buff)
+	assert.True(t, scanner.Scan())
+	assert.JSONEq(t, `{"time":"2019-12-01T10:00:00Z","node":2,"type":"submitted","request":{"ClientID":"alice","ID":"1"}}`, scanner.Text())
+
+	var parsed []types.RequestEvent
+	for i := 0; i < len(events); i++ {
+		if i > 0 {
+			assert.True(t, scanner.Scan())
+		}
+		var event types.RequestEvent
+		assert.NoError(t, json.Unmarshal(scanner.Bytes(), &event))
+		parsed = append(parsed, event)
+	}
+	assert.False(t, scanner.Scan())
+	assert.Equal(t, events, parsed)
+}
+
+type failingWriter struct {
+	writes int
+}
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	w.writes++
+	return 0, errors.New("disk full")
+}
+
+func TestJSONLinesExporterWriteFailure(t *testing.T) {
+	w := &failingWriter{}
+	exporter := tracing.NewJSONLinesExporter(w)
+
+	exporter.TraceRequest(types.RequestEvent{Type: types.RequestSubmitted})
+	exporter.TraceRequest(types.RequestEvent{Type: types.RequestBatched})
+
+	assert.EqualError(t, exporter.Err(), "failed writing request event: disk full")
+	assert.Equal(t, 1, w.writes)
+}
@@ -0,0 +1,31 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package types
+
+import "fmt"
+
+// DecisionCertificate is a decided proposal along with the quorum of signatures on it.
+type DecisionCertificate struct {
+	Proposal   Proposal
+	Signatures []Signature
+}
+
+// ForkEvidence proves that two different proposals were decided at the same sequence.
+// Since any two quorums intersect, some nodes signed both proposals, and these nodes are provably faulty.
+type ForkEvidence struct {
+	Sequence uint64
+	// Delivered is the certificate of the decision this node delivered at the sequence
+	Delivered DecisionCertificate
+	// Conflicting is the certificate of the other decision at the sequence
+	Conflicting DecisionCertificate
+	// Signers are the nodes which signed both proposals
+	Signers []uint64
+}
+
+func (fe *ForkEvidence) Error() string {
+	return fmt.Sprintf("conflicting decisions at sequence %d: delivered %s but %s is also signed by a quorum, nodes %v signed both",
+		fe.Sequence, fe.Delivered.Proposal.Digest(), fe.Conflicting.Proposal.Digest(), fe.Signers)
+}
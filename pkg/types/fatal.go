@@ -0,0 +1,25 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package types
+
+import "fmt"
+
+// FatalError is an inconsistency a node cannot recover from by itself.
+// Once a node encounters it, the node halts: it stops voting, but keeps running
+// so that the embedding service can report the error and shut the node down.
+type FatalError struct {
+	Node uint64
+	Err  error
+}
+
+func (fe *FatalError) Error() string {
+	return fmt.Sprintf("node %d halted: %v", fe.Node, fe.Err)
+}
+
+// Cause returns the underlying error.
+func (fe *FatalError) Cause() error {
+	return fe.Err
+}
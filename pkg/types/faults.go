@@ -0,0 +1,17 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package types
+
+// FaultModel is the kind of faults a cluster tolerates.
+type FaultModel int
+
+const (
+	// ByzantineFaults tolerates f arbitrarily faulty nodes out of 3f+1 nodes.
+	ByzantineFaults FaultModel = iota
+	// CrashFaults tolerates f crashed nodes out of 2f+1 nodes, and trusts the rest.
+	// Commits are not signed, and decisions carry only the IDs of the committing nodes.
+	CrashFaults
+)
@@ -0,0 +1,43 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package types
+
+import "time"
+
+// RequestEventType is a step in the lifecycle of a request.
+type RequestEventType string
+
+const (
+	// RequestSubmitted means the request entered the request pool.
+	RequestSubmitted RequestEventType = "submitted"
+	// RequestForwarded means the request timed out in the pool and was forwarded to the leader.
+	RequestForwarded RequestEventType = "forwarded"
+	// RequestLeaderComplained means the request timed out after it was forwarded,
+	// and the node complained about the leader.
+	RequestLeaderComplained RequestEventType = "leader_complained"
+	// RequestBatched means the leader included the request in a batch it proposes.
+	RequestBatched RequestEventType = "batched"
+	// RequestCommitted means the request is in a decision which was delivered to the application.
+	RequestCommitted RequestEventType = "committed"
+	// RequestRemoved means the request was removed from the pool after it was committed.
+	RequestRemoved RequestEventType = "removed"
+	// RequestRevoked means the request is no longer valid, and was removed from the pool.
+	RequestRevoked RequestEventType = "revoked"
+	// RequestAutoRemoved means the request timed out after the node complained, and was removed from the pool.
+	RequestAutoRemoved RequestEventType = "auto_removed"
+)
+
+// RequestEvent is a timestamped step in the lifecycle of a request, as observed by a node.
+type RequestEvent struct {
+	Time    time.Time        `json:"time"`
+	Node    uint64           `json:"node"`
+	Type    RequestEventType `json:"type"`
+	Request RequestInfo      `json:"request"`
+	// Sequence is the sequence of the proposal the request was batched in, or committed in.
+	Sequence uint64 `json:"sequence,omitempty"`
+	// Leader is the node the request was forwarded to, or complained about.
+	Leader uint64 `json:"leader,omitempty"`
+}
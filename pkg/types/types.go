@@ -11,8 +11,10 @@ import (
 	"encoding/hex"
 	"fmt"
 	"sync"
+	"time"
 
 	"github.com/SmartBFT-Go/consensus/smartbftprotos"
+	"github.com/golang/protobuf/proto"
 )
 
 type Proposal struct {
@@ -52,6 +54,19 @@ func (p Proposal) Digest() string {
 	return computeDigest(rawBytes)
 }
 
+// Timestamp returns the time carried by the metadata of the proposal,
+// or the zero time if the metadata carries no time.
+func (p Proposal) Timestamp() (time.Time, error) {
+	md := &smartbftprotos.ViewMetadata{}
+	if err := proto.Unmarshal(p.Metadata, md); err != nil {
+		return time.Time{}, fmt.Errorf("failed unmarshaling metadata: %v", err)
+	}
+	if md.Timestamp == 0 {
+		return time.Time{}, nil
+	}
+	return time.Unix(0, md.Timestamp), nil
+}
+
 func computeDigest(rawBytes []byte) string {
 	h := sha256.New()
 	h.Write(rawBytes)
@@ -94,3 +109,16 @@ func (c *Checkpoint) Set(proposal Proposal, signatures []Signature) {
 	c.proposal = proposal
 	c.signatures = signatures
 }
+
+// VerificationMetrics is a snapshot of the pool of workers which verify signatures and requests.
+type VerificationMetrics struct {
+	// QueueDepth is the number of verification tasks waiting for a worker.
+	QueueDepth uint64
+	// MaxQueueDepth is the highest number of verification tasks that waited for a worker at once.
+	MaxQueueDepth uint64
+	// Verified counts the signatures and requests verified, and Invalid counts those of them which are invalid.
+	Verified uint64
+	Invalid  uint64
+	// Batches counts the batch verifications of a BatchVerifier.
+	Batches uint64
+}
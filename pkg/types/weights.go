@@ -0,0 +1,67 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package types
+
+import (
+	"fmt"
+	"math"
+)
+
+// TotalWeight returns the sum of the voting weights of the nodes.
+func TotalWeight(weights map[uint64]uint64) uint64 {
+	var total uint64
+	for _, weight := range weights {
+		total += weight
+	}
+	return total
+}
+
+// WeightedQuorum returns the quorum weight Q and the tolerated faulty weight F of nodes with the given voting weights.
+// Under Byzantine faults, F = argmax ( W >= 3F+1 ) for the total weight W, and any two sets of nodes
+// weighing at least Q intersect in nodes weighing at least F+1, of which at least one is correct.
+// Under crash faults, F = argmax ( W >= 2F+1 ), and Q is a majority of the total weight.
+func WeightedQuorum(weights map[uint64]uint64, model FaultModel) (Q uint64, F uint64) {
+	total := TotalWeight(weights)
+	if total == 0 {
+		return 0, 0
+	}
+	if model == CrashFaults {
+		return total/2 + 1, (total - 1) / 2
+	}
+	F = (total - 1) / 3
+	Q = uint64(math.Ceil((float64(total) + float64(F) + 1) / 2.0))
+	return Q, F
+}
+
+// ValidateWeights checks that the voting weights are assigned exactly to the given nodes,
+// and that every node has a positive weight which is at most the tolerated faulty weight F of the fault model,
+// as the fault of a heavier node alone isn't tolerated.
+func ValidateWeights(nodes []uint64, weights map[uint64]uint64, model FaultModel) error {
+	var total uint64
+	for _, node := range nodes {
+		weight, exists := weights[node]
+		if !exists {
+			return fmt.Errorf("node %d has no weight", node)
+		}
+		if weight == 0 {
+			return fmt.Errorf("node %d has a weight of zero", node)
+		}
+		if total+weight < total {
+			return fmt.Errorf("total weight overflows")
+		}
+		total += weight
+	}
+	if len(weights) != len(nodes) {
+		return fmt.Errorf("%d weights are assigned to %d nodes", len(weights), len(nodes))
+	}
+	_, F := WeightedQuorum(weights, model)
+	for _, node := range nodes {
+		if weights[node] > F {
+			return fmt.Errorf("node %d has a weight of %d, which exceeds the tolerated faulty weight %d", node, weights[node], F)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,73 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package types_test
+
+import (
+	"testing"
+
+	"github.com/SmartBFT-Go/consensus/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWeightedQuorum(t *testing.T) {
+	for _, testCase := range []struct {
+		description string
+		weights     map[uint64]uint64
+		model       types.FaultModel
+		Q           uint64
+		F           uint64
+	}{
+		{
+			description: "equal weights",
+			weights:     map[uint64]uint64{1: 1, 2: 1, 3: 1, 4: 1},
+			Q:           3,
+			F:           1,
+		},
+		{
+			description: "one heavy node",
+			weights:     map[uint64]uint64{1: 3, 2: 1, 3: 1, 4: 1},
+			Q:           4,
+			F:           1,
+		},
+		{
+			description: "heavy nodes",
+			weights:     map[uint64]uint64{1: 4, 2: 3, 3: 2, 4: 1},
+			Q:           7,
+			F:           3,
+		},
+		{
+			description: "crash faults",
+			weights:     map[uint64]uint64{1: 2, 2: 1, 3: 1},
+			model:       types.CrashFaults,
+			Q:           3,
+			F:           1,
+		},
+	} {
+		t.Run(testCase.description, func(t *testing.T) {
+			Q, F := types.WeightedQuorum(testCase.weights, testCase.model)
+			assert.Equal(t, testCase.Q, Q)
+			assert.Equal(t, testCase.F, F)
+		})
+	}
+}
+
+func TestValidateWeights(t *testing.T) {
+	nodes := []uint64{1, 2, 3, 4}
+	assert.NoError(t, types.ValidateWeights(nodes, map[uint64]uint64{1: 3, 2: 3, 3: 3, 4: 1}, types.ByzantineFaults))
+	assert.EqualError(t, types.ValidateWeights(nodes, map[uint64]uint64{1: 3, 2: 3, 3: 3}, types.ByzantineFaults), "node 4 has no weight")
+	assert.EqualError(t, types.ValidateWeights(nodes, map[uint64]uint64{1: 3, 2: 3, 3: 3, 4: 0}, types.ByzantineFaults), "node 4 has a weight of zero")
+	assert.EqualError(t, types.ValidateWeights(nodes, map[uint64]uint64{1: 3, 2: 3, 3: 3, 4: 1, 5: 1}, types.ByzantineFaults), "5 weights are assigned to 4 nodes")
+	assert.EqualError(t, types.ValidateWeights(nodes, map[uint64]uint64{1: 1 << 63, 2: 1 << 63, 3: 1, 4: 1}, types.ByzantineFaults), "total weight overflows")
+
+	// A node heavier than the tolerated faulty weight is rejected, even if it doesn't reach a quorum by itself
+	assert.EqualError(t, types.ValidateWeights(nodes, map[uint64]uint64{1: 3, 2: 1, 3: 1, 4: 1}, types.ByzantineFaults),
+		"node 1 has a weight of 3, which exceeds the tolerated faulty weight 1")
+	assert.EqualError(t, types.ValidateWeights(nodes, map[uint64]uint64{1: 4, 2: 3, 3: 2, 4: 1}, types.ByzantineFaults),
+		"node 1 has a weight of 4, which exceeds the tolerated faulty weight 3")
+	assert.NoError(t, types.ValidateWeights(nodes, map[uint64]uint64{1: 2, 2: 2, 3: 1, 4: 1}, types.CrashFaults))
+	assert.EqualError(t, types.ValidateWeights(nodes, map[uint64]uint64{1: 3, 2: 1, 3: 1, 4: 1}, types.CrashFaults),
+		"node 1 has a weight of 3, which exceeds the tolerated faulty weight 2")
+}
@@ -701,3 +701,12 @@ func InitializeAndReadAll(logger api.Logger, walDir string, options *Options) (w
 
 	return writeAheadLog, initialState, err
 }
+
+// ChannelDir returns the directory of the Write-Ahead-Log of a channel,
+// for consensus instances of several channels that keep their logs under the same dir.
+func ChannelDir(walDir string, channelID string) (string, error) {
+	if channelID == "" || channelID == "." || channelID == ".." || strings.ContainsAny(channelID, `/\`) {
+		return "", errors.Errorf("wal: invalid channel ID: %q", channelID)
+	}
+	return filepath.Join(walDir, channelID), nil
+}
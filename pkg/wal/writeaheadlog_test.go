@@ -854,3 +854,14 @@ func verifyAppend(t *testing.T, logger api.Logger, dirPath string, expectedFileN
 	assert.Nil(t, record)
 	assert.Equal(t, expectedCRC, r.CRC())
 }
+
+func TestChannelDir(t *testing.T) {
+	dir, err := ChannelDir("/var/wal", "mychannel")
+	assert.NoError(t, err)
+	assert.Equal(t, filepath.Join("/var/wal", "mychannel"), dir)
+
+	for _, channelID := range []string{"", ".", "..", "a/b", `a\b`} {
+		_, err = ChannelDir("/var/wal", channelID)
+		assert.EqualError(t, err, fmt.Sprintf("wal: invalid channel ID: %q", channelID))
+	}
+}
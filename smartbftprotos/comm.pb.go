@@ -0,0 +1,134 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: smartbftprotos/comm.proto
+
+package smartbftprotos
+
+import (
+	fmt "fmt"
+	math "math"
+
+	proto "github.com/golang/protobuf/proto"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+// Frame is the unit sent over a stream between two nodes.
+type Frame struct {
+	// Types that are valid to be assigned to Content:
+	//	*Frame_Consensus
+	//	*Frame_Transaction
+	Content isFrame_Content `protobuf_oneof:"content"`
+	// channel identifies the consensus instance the content belongs to,
+	// and is empty for the default instance.
+	Channel              string   `protobuf:"bytes,3,opt,name=channel,proto3" json:"channel,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *Frame) Reset()         { *m = Frame{} }
+func (m *Frame) String() string { return proto.CompactTextString(m) }
+func (*Frame) ProtoMessage()    {}
+func (*Frame) Descriptor() ([]byte, []int) {
+	return fileDescriptor_c8c504c1314f6cb7, []int{0}
+}
+
+func (m *Frame) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_Frame.Unmarshal(m, b)
+}
+func (m *Frame) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_Frame.Marshal(b, m, deterministic)
+}
+func (m *Frame) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_Frame.Merge(m, src)
+}
+func (m *Frame) XXX_Size() int {
+	return xxx_messageInfo_Frame.Size(m)
+}
+func (m *Frame) XXX_DiscardUnknown() {
+	xxx_messageInfo_Frame.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_Frame proto.InternalMessageInfo
+
+type isFrame_Content interface {
+	isFrame_Content()
+}
+
+type Frame_Consensus struct {
+	Consensus *Message `protobuf:"bytes,1,opt,name=consensus,proto3,oneof"`
+}
+
+type Frame_Transaction struct {
+	Transaction []byte `protobuf:"bytes,2,opt,name=transaction,proto3,oneof"`
+}
+
+func (*Frame_Consensus) isFrame_Content() {}
+
+func (*Frame_Transaction) isFrame_Content() {}
+
+func (m *Frame) GetContent() isFrame_Content {
+	if m != nil {
+		return m.Content
+	}
+	return nil
+}
+
+func (m *Frame) GetConsensus() *Message {
+	if x, ok := m.GetContent().(*Frame_Consensus); ok {
+		return x.Consensus
+	}
+	return nil
+}
+
+func (m *Frame) GetTransaction() []byte {
+	if x, ok := m.GetContent().(*Frame_Transaction); ok {
+		return x.Transaction
+	}
+	return nil
+}
+
+func (m *Frame) GetChannel() string {
+	if m != nil {
+		return m.Channel
+	}
+	return ""
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*Frame) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*Frame_Consensus)(nil),
+		(*Frame_Transaction)(nil),
+	}
+}
+
+func init() {
+	proto.RegisterType((*Frame)(nil), "smartbftprotos.Frame")
+}
+
+func init() { proto.RegisterFile("smartbftprotos/comm.proto", fileDescriptor_c8c504c1314f6cb7) }
+
+var fileDescriptor_c8c504c1314f6cb7 = []byte{
+	// 168 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xe2, 0x92, 0x2c, 0xce, 0x4d, 0x2c,
+	0x2a, 0x49, 0x4a, 0x2b, 0x29, 0x28, 0xca, 0x2f, 0xc9, 0x2f, 0xd6, 0x4f, 0xce, 0xcf, 0xcd, 0xd5,
+	0x03, 0xb3, 0x85, 0xf8, 0x50, 0xa5, 0xa4, 0x64, 0xd1, 0x94, 0xe6, 0xa6, 0x16, 0x17, 0x27, 0xa6,
+	0xa7, 0x16, 0x43, 0x94, 0x2b, 0x75, 0x32, 0x72, 0xb1, 0xba, 0x15, 0x25, 0xe6, 0xa6, 0x0a, 0x99,
+	0x73, 0x71, 0x26, 0xe7, 0xe7, 0x15, 0xa7, 0xe6, 0x15, 0x97, 0x16, 0x4b, 0x30, 0x2a, 0x30, 0x6a,
+	0x70, 0x1b, 0x89, 0xeb, 0xa1, 0x6a, 0xd6, 0xf3, 0x85, 0x68, 0xf6, 0x60, 0x08, 0x42, 0xa8, 0x15,
+	0x52, 0xe2, 0xe2, 0x2e, 0x29, 0x4a, 0xcc, 0x2b, 0x4e, 0x4c, 0x2e, 0xc9, 0xcc, 0xcf, 0x93, 0x60,
+	0x52, 0x60, 0xd4, 0xe0, 0xf1, 0x60, 0x08, 0x42, 0x16, 0x14, 0x92, 0xe0, 0x62, 0x4f, 0xce, 0x48,
+	0xcc, 0xcb, 0x4b, 0xcd, 0x91, 0x60, 0x56, 0x60, 0xd4, 0xe0, 0x0c, 0x82, 0x71, 0x9d, 0x38, 0xb9,
+	0xd8, 0x93, 0xf3, 0xf3, 0x4a, 0x52, 0xf3, 0x4a, 0x92, 0xd8, 0xc0, 0xb6, 0x18, 0x03, 0x06, 0x00,
+	0xf3, 0x32, 0xb4, 0xde, 0xde, 0x00, 0x00, 0x00,
+}
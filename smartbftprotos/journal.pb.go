@@ -0,0 +1,396 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// source: smartbftprotos/journal.proto
+
+package smartbftprotos
+
+import (
+	fmt "fmt"
+	math "math"
+
+	proto "github.com/golang/protobuf/proto"
+)
+
+// Reference imports to suppress errors if they are not otherwise used.
+var _ = proto.Marshal
+var _ = fmt.Errorf
+var _ = math.Inf
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the proto package it is being compiled against.
+// A compilation error at this line likely means your copy of the
+// proto package needs to be updated.
+const _ = proto.ProtoPackageIsVersion3 // please upgrade the proto package
+
+type JournalTick_Source int32
+
+const (
+	JournalTick_SCHEDULER    JournalTick_Source = 0
+	JournalTick_VIEW_CHANGER JournalTick_Source = 1
+)
+
+var JournalTick_Source_name = map[int32]string{
+	0: "SCHEDULER",
+	1: "VIEW_CHANGER",
+}
+
+var JournalTick_Source_value = map[string]int32{
+	"SCHEDULER":    0,
+	"VIEW_CHANGER": 1,
+}
+
+func (x JournalTick_Source) String() string {
+	return proto.EnumName(JournalTick_Source_name, int32(x))
+}
+
+func (JournalTick_Source) EnumDescriptor() ([]byte, []int) {
+	return fileDescriptor_f419f020f9b26cdf, []int{3, 0}
+}
+
+// JournalEntry is an input of a node, or a decision it delivered, recorded for replay.
+type JournalEntry struct {
+	// Unix time in nanoseconds of when the entry was recorded
+	Timestamp int64 `protobuf:"varint,1,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	// Types that are valid to be assigned to Event:
+	//	*JournalEntry_Message
+	//	*JournalEntry_Request
+	//	*JournalEntry_Tick
+	//	*JournalEntry_Delivery
+	Event                isJournalEntry_Event `protobuf_oneof:"event"`
+	XXX_NoUnkeyedLiteral struct{}             `json:"-"`
+	XXX_unrecognized     []byte               `json:"-"`
+	XXX_sizecache        int32                `json:"-"`
+}
+
+func (m *JournalEntry) Reset()         { *m = JournalEntry{} }
+func (m *JournalEntry) String() string { return proto.CompactTextString(m) }
+func (*JournalEntry) ProtoMessage()    {}
+func (*JournalEntry) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f419f020f9b26cdf, []int{0}
+}
+
+func (m *JournalEntry) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_JournalEntry.Unmarshal(m, b)
+}
+func (m *JournalEntry) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_JournalEntry.Marshal(b, m, deterministic)
+}
+func (m *JournalEntry) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_JournalEntry.Merge(m, src)
+}
+func (m *JournalEntry) XXX_Size() int {
+	return xxx_messageInfo_JournalEntry.Size(m)
+}
+func (m *JournalEntry) XXX_DiscardUnknown() {
+	xxx_messageInfo_JournalEntry.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_JournalEntry proto.InternalMessageInfo
+
+func (m *JournalEntry) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+type isJournalEntry_Event interface {
+	isJournalEntry_Event()
+}
+
+type JournalEntry_Message struct {
+	Message *JournalMessage `protobuf:"bytes,2,opt,name=message,proto3,oneof"`
+}
+
+type JournalEntry_Request struct {
+	Request *JournalRequest `protobuf:"bytes,3,opt,name=request,proto3,oneof"`
+}
+
+type JournalEntry_Tick struct {
+	Tick *JournalTick `protobuf:"bytes,4,opt,name=tick,proto3,oneof"`
+}
+
+type JournalEntry_Delivery struct {
+	Delivery *JournalDelivery `protobuf:"bytes,5,opt,name=delivery,proto3,oneof"`
+}
+
+func (*JournalEntry_Message) isJournalEntry_Event() {}
+
+func (*JournalEntry_Request) isJournalEntry_Event() {}
+
+func (*JournalEntry_Tick) isJournalEntry_Event() {}
+
+func (*JournalEntry_Delivery) isJournalEntry_Event() {}
+
+func (m *JournalEntry) GetEvent() isJournalEntry_Event {
+	if m != nil {
+		return m.Event
+	}
+	return nil
+}
+
+func (m *JournalEntry) GetMessage() *JournalMessage {
+	if x, ok := m.GetEvent().(*JournalEntry_Message); ok {
+		return x.Message
+	}
+	return nil
+}
+
+func (m *JournalEntry) GetRequest() *JournalRequest {
+	if x, ok := m.GetEvent().(*JournalEntry_Request); ok {
+		return x.Request
+	}
+	return nil
+}
+
+func (m *JournalEntry) GetTick() *JournalTick {
+	if x, ok := m.GetEvent().(*JournalEntry_Tick); ok {
+		return x.Tick
+	}
+	return nil
+}
+
+func (m *JournalEntry) GetDelivery() *JournalDelivery {
+	if x, ok := m.GetEvent().(*JournalEntry_Delivery); ok {
+		return x.Delivery
+	}
+	return nil
+}
+
+// XXX_OneofWrappers is for the internal use of the proto package.
+func (*JournalEntry) XXX_OneofWrappers() []interface{} {
+	return []interface{}{
+		(*JournalEntry_Message)(nil),
+		(*JournalEntry_Request)(nil),
+		(*JournalEntry_Tick)(nil),
+		(*JournalEntry_Delivery)(nil),
+	}
+}
+
+type JournalMessage struct {
+	Sender               uint64   `protobuf:"varint,1,opt,name=sender,proto3" json:"sender,omitempty"`
+	Message              *Message `protobuf:"bytes,2,opt,name=message,proto3" json:"message,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *JournalMessage) Reset()         { *m = JournalMessage{} }
+func (m *JournalMessage) String() string { return proto.CompactTextString(m) }
+func (*JournalMessage) ProtoMessage()    {}
+func (*JournalMessage) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f419f020f9b26cdf, []int{1}
+}
+
+func (m *JournalMessage) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_JournalMessage.Unmarshal(m, b)
+}
+func (m *JournalMessage) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_JournalMessage.Marshal(b, m, deterministic)
+}
+func (m *JournalMessage) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_JournalMessage.Merge(m, src)
+}
+func (m *JournalMessage) XXX_Size() int {
+	return xxx_messageInfo_JournalMessage.Size(m)
+}
+func (m *JournalMessage) XXX_DiscardUnknown() {
+	xxx_messageInfo_JournalMessage.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_JournalMessage proto.InternalMessageInfo
+
+func (m *JournalMessage) GetSender() uint64 {
+	if m != nil {
+		return m.Sender
+	}
+	return 0
+}
+
+func (m *JournalMessage) GetMessage() *Message {
+	if m != nil {
+		return m.Message
+	}
+	return nil
+}
+
+type JournalRequest struct {
+	Sender               uint64   `protobuf:"varint,1,opt,name=sender,proto3" json:"sender,omitempty"`
+	Request              []byte   `protobuf:"bytes,2,opt,name=request,proto3" json:"request,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *JournalRequest) Reset()         { *m = JournalRequest{} }
+func (m *JournalRequest) String() string { return proto.CompactTextString(m) }
+func (*JournalRequest) ProtoMessage()    {}
+func (*JournalRequest) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f419f020f9b26cdf, []int{2}
+}
+
+func (m *JournalRequest) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_JournalRequest.Unmarshal(m, b)
+}
+func (m *JournalRequest) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_JournalRequest.Marshal(b, m, deterministic)
+}
+func (m *JournalRequest) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_JournalRequest.Merge(m, src)
+}
+func (m *JournalRequest) XXX_Size() int {
+	return xxx_messageInfo_JournalRequest.Size(m)
+}
+func (m *JournalRequest) XXX_DiscardUnknown() {
+	xxx_messageInfo_JournalRequest.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_JournalRequest proto.InternalMessageInfo
+
+func (m *JournalRequest) GetSender() uint64 {
+	if m != nil {
+		return m.Sender
+	}
+	return 0
+}
+
+func (m *JournalRequest) GetRequest() []byte {
+	if m != nil {
+		return m.Request
+	}
+	return nil
+}
+
+type JournalTick struct {
+	Source JournalTick_Source `protobuf:"varint,1,opt,name=source,proto3,enum=smartbftprotos.JournalTick_Source" json:"source,omitempty"`
+	// Unix time in nanoseconds of the tick
+	Time                 int64    `protobuf:"varint,2,opt,name=time,proto3" json:"time,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *JournalTick) Reset()         { *m = JournalTick{} }
+func (m *JournalTick) String() string { return proto.CompactTextString(m) }
+func (*JournalTick) ProtoMessage()    {}
+func (*JournalTick) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f419f020f9b26cdf, []int{3}
+}
+
+func (m *JournalTick) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_JournalTick.Unmarshal(m, b)
+}
+func (m *JournalTick) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_JournalTick.Marshal(b, m, deterministic)
+}
+func (m *JournalTick) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_JournalTick.Merge(m, src)
+}
+func (m *JournalTick) XXX_Size() int {
+	return xxx_messageInfo_JournalTick.Size(m)
+}
+func (m *JournalTick) XXX_DiscardUnknown() {
+	xxx_messageInfo_JournalTick.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_JournalTick proto.InternalMessageInfo
+
+func (m *JournalTick) GetSource() JournalTick_Source {
+	if m != nil {
+		return m.Source
+	}
+	return JournalTick_SCHEDULER
+}
+
+func (m *JournalTick) GetTime() int64 {
+	if m != nil {
+		return m.Time
+	}
+	return 0
+}
+
+type JournalDelivery struct {
+	Proposal             *Proposal    `protobuf:"bytes,1,opt,name=proposal,proto3" json:"proposal,omitempty"`
+	Signatures           []*Signature `protobuf:"bytes,2,rep,name=signatures,proto3" json:"signatures,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *JournalDelivery) Reset()         { *m = JournalDelivery{} }
+func (m *JournalDelivery) String() string { return proto.CompactTextString(m) }
+func (*JournalDelivery) ProtoMessage()    {}
+func (*JournalDelivery) Descriptor() ([]byte, []int) {
+	return fileDescriptor_f419f020f9b26cdf, []int{4}
+}
+
+func (m *JournalDelivery) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_JournalDelivery.Unmarshal(m, b)
+}
+func (m *JournalDelivery) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_JournalDelivery.Marshal(b, m, deterministic)
+}
+func (m *JournalDelivery) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_JournalDelivery.Merge(m, src)
+}
+func (m *JournalDelivery) XXX_Size() int {
+	return xxx_messageInfo_JournalDelivery.Size(m)
+}
+func (m *JournalDelivery) XXX_DiscardUnknown() {
+	xxx_messageInfo_JournalDelivery.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_JournalDelivery proto.InternalMessageInfo
+
+func (m *JournalDelivery) GetProposal() *Proposal {
+	if m != nil {
+		return m.Proposal
+	}
+	return nil
+}
+
+func (m *JournalDelivery) GetSignatures() []*Signature {
+	if m != nil {
+		return m.Signatures
+	}
+	return nil
+}
+
+func init() {
+	proto.RegisterEnum("smartbftprotos.JournalTick_Source", JournalTick_Source_name, JournalTick_Source_value)
+	proto.RegisterType((*JournalEntry)(nil), "smartbftprotos.JournalEntry")
+	proto.RegisterType((*JournalMessage)(nil), "smartbftprotos.JournalMessage")
+	proto.RegisterType((*JournalRequest)(nil), "smartbftprotos.JournalRequest")
+	proto.RegisterType((*JournalTick)(nil), "smartbftprotos.JournalTick")
+	proto.RegisterType((*JournalDelivery)(nil), "smartbftprotos.JournalDelivery")
+}
+
+func init() { proto.RegisterFile("smartbftprotos/journal.proto", fileDescriptor_f419f020f9b26cdf) }
+
+var fileDescriptor_f419f020f9b26cdf = []byte{
+	// 394 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0x7c, 0x90, 0x4d, 0xaf, 0xd2, 0x40,
+	0x14, 0x86, 0x5b, 0xca, 0xed, 0xbd, 0x9c, 0x62, 0x25, 0xb3, 0xd0, 0x51, 0x51, 0x49, 0x57, 0xb8,
+	0xa9, 0xa1, 0xba, 0x91, 0xc4, 0x85, 0x40, 0x63, 0x35, 0x6a, 0xcc, 0xe0, 0xc7, 0xc2, 0x85, 0x29,
+	0x30, 0x92, 0x0a, 0xfd, 0x70, 0x66, 0x4a, 0xc2, 0xd6, 0x95, 0xff, 0xc2, 0xbf, 0x7a, 0xd3, 0xe9,
+	0x50, 0x68, 0x13, 0xd8, 0x75, 0x7a, 0x9e, 0xe7, 0x9d, 0x33, 0x2f, 0xf4, 0x79, 0x1c, 0x32, 0xb1,
+	0xf8, 0x25, 0x32, 0x96, 0x8a, 0x94, 0x3f, 0xff, 0x9d, 0xe6, 0x2c, 0x09, 0xb7, 0xae, 0x3c, 0x22,
+	0xbb, 0x3e, 0x7d, 0xf8, 0xb8, 0x41, 0xc7, 0x94, 0xf3, 0x70, 0x4d, 0x79, 0x89, 0x3b, 0xff, 0x5b,
+	0xd0, 0x7d, 0x5f, 0x06, 0xf8, 0x89, 0x60, 0x7b, 0xd4, 0x87, 0x8e, 0x88, 0x62, 0xca, 0x45, 0x18,
+	0x67, 0x58, 0x1f, 0xe8, 0x43, 0x83, 0x1c, 0x7f, 0xa0, 0x31, 0x5c, 0xab, 0x00, 0xdc, 0x1a, 0xe8,
+	0x43, 0xcb, 0x7b, 0xe2, 0xd6, 0xf3, 0x5d, 0x15, 0xf6, 0xb1, 0xa4, 0x02, 0x8d, 0x1c, 0x84, 0xc2,
+	0x65, 0xf4, 0x4f, 0x4e, 0xb9, 0xc0, 0xc6, 0x45, 0x97, 0x94, 0x54, 0xe1, 0x2a, 0x01, 0x8d, 0xa0,
+	0x2d, 0xa2, 0xe5, 0x06, 0xb7, 0xa5, 0xf8, 0xe8, 0x8c, 0xf8, 0x25, 0x5a, 0x6e, 0x02, 0x8d, 0x48,
+	0x14, 0xbd, 0x86, 0x9b, 0x15, 0xdd, 0x46, 0x3b, 0xca, 0xf6, 0xf8, 0x4a, 0x6a, 0x4f, 0xcf, 0x68,
+	0x33, 0x85, 0x05, 0x1a, 0xa9, 0x94, 0xc9, 0x35, 0x5c, 0xd1, 0x1d, 0x4d, 0x84, 0xf3, 0x03, 0xec,
+	0xfa, 0x9b, 0xd0, 0x3d, 0x30, 0x39, 0x4d, 0x56, 0x94, 0xc9, 0x7e, 0xda, 0x44, 0x9d, 0xd0, 0xa8,
+	0x59, 0xce, 0xfd, 0xe6, 0x85, 0x2a, 0xa1, 0xea, 0xc4, 0x99, 0x80, 0x5d, 0x7f, 0xf4, 0xd9, 0x70,
+	0x7c, 0x6c, 0xaf, 0x08, 0xef, 0x56, 0xdd, 0x38, 0xff, 0x74, 0xb0, 0x4e, 0x0a, 0x40, 0x63, 0x30,
+	0x79, 0x9a, 0xb3, 0x25, 0x95, 0x09, 0xb6, 0xe7, 0x5c, 0x68, 0xcb, 0x9d, 0x4b, 0x92, 0x28, 0x03,
+	0xa1, 0xa2, 0xe7, 0xb8, 0xdc, 0xdf, 0x20, 0xf2, 0xdb, 0x79, 0x06, 0x66, 0x49, 0xa1, 0x3b, 0xd0,
+	0x99, 0x4f, 0x03, 0x7f, 0xf6, 0xf5, 0x83, 0x4f, 0x7a, 0x1a, 0xea, 0x41, 0xf7, 0xdb, 0x3b, 0xff,
+	0xfb, 0xcf, 0x69, 0xf0, 0xe6, 0xd3, 0x5b, 0x9f, 0xf4, 0x74, 0xe7, 0xaf, 0x0e, 0x77, 0x1b, 0xa5,
+	0xa2, 0x97, 0x70, 0x93, 0xb1, 0x34, 0x4b, 0x79, 0xb8, 0x95, 0x0b, 0x59, 0x1e, 0x6e, 0x2e, 0xf4,
+	0x59, 0xcd, 0x49, 0x45, 0xa2, 0x57, 0x00, 0x3c, 0x5a, 0x27, 0xa1, 0xc8, 0x19, 0xe5, 0xb8, 0x35,
+	0x30, 0x86, 0x96, 0xf7, 0xa0, 0xe9, 0xcd, 0x0f, 0x04, 0x39, 0x81, 0x17, 0xa6, 0x9c, 0xbe, 0xb8,
+	0x1d, 0x00, 0x4c, 0x6b, 0x27, 0xf2, 0x28, 0x03, 0x00, 0x00,
+}
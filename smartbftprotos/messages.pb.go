@@ -31,10 +31,20 @@ type Message struct {
 	//	*Message_ViewData
 	//	*Message_NewView
 	//	*Message_HeartBeat
-	Content              isMessage_Content `protobuf_oneof:"content"`
-	XXX_NoUnkeyedLiteral struct{}          `json:"-"`
-	XXX_unrecognized     []byte            `json:"-"`
-	XXX_sizecache        int32             `json:"-"`
+	//	*Message_VoteCertificate
+	//	*Message_FetchRequests
+	//	*Message_FetchedRequests
+	//	*Message_PrePrepareChunk
+	Content isMessage_Content `protobuf_oneof:"content"`
+	// Authenticates the content, if the library is configured to authenticate messages.
+	Authentication []byte `protobuf:"bytes,9,opt,name=authentication,proto3" json:"authentication,omitempty"`
+	// The protocol version in effect at the sender, zero for senders which predate versioning.
+	ProtocolVersion uint32 `protobuf:"varint,10,opt,name=protocol_version,json=protocolVersion,proto3" json:"protocol_version,omitempty"`
+	// The highest protocol version the sender supports.
+	MaxProtocolVersion   uint32   `protobuf:"varint,11,opt,name=max_protocol_version,json=maxProtocolVersion,proto3" json:"max_protocol_version,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *Message) Reset()         { *m = Message{} }
@@ -98,6 +108,22 @@ type Message_HeartBeat struct {
 	HeartBeat *HeartBeat `protobuf:"bytes,8,opt,name=heart_beat,json=heartBeat,proto3,oneof"`
 }
 
+type Message_VoteCertificate struct {
+	VoteCertificate *VoteCertificate `protobuf:"bytes,12,opt,name=vote_certificate,json=voteCertificate,proto3,oneof"`
+}
+
+type Message_FetchRequests struct {
+	FetchRequests *FetchRequests `protobuf:"bytes,13,opt,name=fetch_requests,json=fetchRequests,proto3,oneof"`
+}
+
+type Message_FetchedRequests struct {
+	FetchedRequests *FetchedRequests `protobuf:"bytes,14,opt,name=fetched_requests,json=fetchedRequests,proto3,oneof"`
+}
+
+type Message_PrePrepareChunk struct {
+	PrePrepareChunk *PrePrepareChunk `protobuf:"bytes,15,opt,name=pre_prepare_chunk,json=prePrepareChunk,proto3,oneof"`
+}
+
 func (*Message_PrePrepare) isMessage_Content() {}
 
 func (*Message_Prepare) isMessage_Content() {}
@@ -114,6 +140,14 @@ func (*Message_NewView) isMessage_Content() {}
 
 func (*Message_HeartBeat) isMessage_Content() {}
 
+func (*Message_VoteCertificate) isMessage_Content() {}
+
+func (*Message_FetchRequests) isMessage_Content() {}
+
+func (*Message_FetchedRequests) isMessage_Content() {}
+
+func (*Message_PrePrepareChunk) isMessage_Content() {}
+
 func (m *Message) GetContent() isMessage_Content {
 	if m != nil {
 		return m.Content
@@ -177,6 +211,55 @@ func (m *Message) GetHeartBeat() *HeartBeat {
 	return nil
 }
 
+func (m *Message) GetVoteCertificate() *VoteCertificate {
+	if x, ok := m.GetContent().(*Message_VoteCertificate); ok {
+		return x.VoteCertificate
+	}
+	return nil
+}
+
+func (m *Message) GetFetchRequests() *FetchRequests {
+	if x, ok := m.GetContent().(*Message_FetchRequests); ok {
+		return x.FetchRequests
+	}
+	return nil
+}
+
+func (m *Message) GetFetchedRequests() *FetchedRequests {
+	if x, ok := m.GetContent().(*Message_FetchedRequests); ok {
+		return x.FetchedRequests
+	}
+	return nil
+}
+
+func (m *Message) GetPrePrepareChunk() *PrePrepareChunk {
+	if x, ok := m.GetContent().(*Message_PrePrepareChunk); ok {
+		return x.PrePrepareChunk
+	}
+	return nil
+}
+
+func (m *Message) GetAuthentication() []byte {
+	if m != nil {
+		return m.Authentication
+	}
+	return nil
+}
+
+func (m *Message) GetProtocolVersion() uint32 {
+	if m != nil {
+		return m.ProtocolVersion
+	}
+	return 0
+}
+
+func (m *Message) GetMaxProtocolVersion() uint32 {
+	if m != nil {
+		return m.MaxProtocolVersion
+	}
+	return 0
+}
+
 // XXX_OneofWrappers is for the internal use of the proto package.
 func (*Message) XXX_OneofWrappers() []interface{} {
 	return []interface{}{
@@ -188,16 +271,25 @@ func (*Message) XXX_OneofWrappers() []interface{} {
 		(*Message_ViewData)(nil),
 		(*Message_NewView)(nil),
 		(*Message_HeartBeat)(nil),
+		(*Message_VoteCertificate)(nil),
+		(*Message_FetchRequests)(nil),
+		(*Message_FetchedRequests)(nil),
+		(*Message_PrePrepareChunk)(nil),
 	}
 }
 
 type PrePrepare struct {
-	View                 uint64    `protobuf:"varint,1,opt,name=view,proto3" json:"view,omitempty"`
-	Seq                  uint64    `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`
-	Proposal             *Proposal `protobuf:"bytes,3,opt,name=proposal,proto3" json:"proposal,omitempty"`
-	XXX_NoUnkeyedLiteral struct{}  `json:"-"`
-	XXX_unrecognized     []byte    `json:"-"`
-	XXX_sizecache        int32     `json:"-"`
+	View                 uint64       `protobuf:"varint,1,opt,name=view,proto3" json:"view,omitempty"`
+	Seq                  uint64       `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`
+	Proposal             *Proposal    `protobuf:"bytes,3,opt,name=proposal,proto3" json:"proposal,omitempty"`
+	PrevCommitSignatures []*Signature `protobuf:"bytes,4,rep,name=prev_commit_signatures,json=prevCommitSignatures,proto3" json:"prev_commit_signatures,omitempty"`
+	// The requests of the proposal, whose payload is omitted, if requests are disseminated in advance.
+	RequestIds []*RequestID `protobuf:"bytes,5,rep,name=request_ids,json=requestIds,proto3" json:"request_ids,omitempty"`
+	// The digest of the proposal, if its payload is omitted.
+	Digest               string   `protobuf:"bytes,6,opt,name=digest,proto3" json:"digest,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
 }
 
 func (m *PrePrepare) Reset()         { *m = PrePrepare{} }
@@ -246,22 +338,282 @@ func (m *PrePrepare) GetProposal() *Proposal {
 	return nil
 }
 
-type Prepare struct {
-	View                 uint64   `protobuf:"varint,1,opt,name=view,proto3" json:"view,omitempty"`
-	Seq                  uint64   `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`
-	Digest               string   `protobuf:"bytes,3,opt,name=digest,proto3" json:"digest,omitempty"`
-	Assist               bool     `protobuf:"varint,4,opt,name=assist,proto3" json:"assist,omitempty"`
-	Signature            []byte   `protobuf:"bytes,5,opt,name=signature,proto3" json:"signature,omitempty"`
+func (m *PrePrepare) GetPrevCommitSignatures() []*Signature {
+	if m != nil {
+		return m.PrevCommitSignatures
+	}
+	return nil
+}
+
+func (m *PrePrepare) GetRequestIds() []*RequestID {
+	if m != nil {
+		return m.RequestIds
+	}
+	return nil
+}
+
+func (m *PrePrepare) GetDigest() string {
+	if m != nil {
+		return m.Digest
+	}
+	return ""
+}
+
+// An erasure coded chunk of a pre-prepare, which the leader sends to each node, and the node relays to the others.
+type PrePrepareChunk struct {
+	View   uint64 `protobuf:"varint,1,opt,name=view,proto3" json:"view,omitempty"`
+	Seq    uint64 `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`
+	Leader uint64 `protobuf:"varint,3,opt,name=leader,proto3" json:"leader,omitempty"`
+	// The index of the chunk among the chunks, which is the position of the node it is sent to.
+	Index uint32 `protobuf:"varint,4,opt,name=index,proto3" json:"index,omitempty"`
+	Shard []byte `protobuf:"bytes,5,opt,name=shard,proto3" json:"shard,omitempty"`
+	// The following fields are set only in chunks the leader sends.
+	// The digest of the pre-prepare.
+	Digest string `protobuf:"bytes,6,opt,name=digest,proto3" json:"digest,omitempty"`
+	// The size of the pre-prepare.
+	Size uint64 `protobuf:"varint,7,opt,name=size,proto3" json:"size,omitempty"`
+	// The number of chunks which suffice to reconstruct the pre-prepare.
+	DataShards uint32 `protobuf:"varint,8,opt,name=data_shards,json=dataShards,proto3" json:"data_shards,omitempty"`
+	// The hashes of the shards of all chunks, in the order of their indices.
+	ShardHashes          [][]byte `protobuf:"bytes,9,rep,name=shard_hashes,json=shardHashes,proto3" json:"shard_hashes,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *PrePrepareChunk) Reset()         { *m = PrePrepareChunk{} }
+func (m *PrePrepareChunk) String() string { return proto.CompactTextString(m) }
+func (*PrePrepareChunk) ProtoMessage()    {}
+func (*PrePrepareChunk) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0d30f2fcdff47131, []int{2}
+}
+
+func (m *PrePrepareChunk) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_PrePrepareChunk.Unmarshal(m, b)
+}
+func (m *PrePrepareChunk) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_PrePrepareChunk.Marshal(b, m, deterministic)
+}
+func (m *PrePrepareChunk) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_PrePrepareChunk.Merge(m, src)
+}
+func (m *PrePrepareChunk) XXX_Size() int {
+	return xxx_messageInfo_PrePrepareChunk.Size(m)
+}
+func (m *PrePrepareChunk) XXX_DiscardUnknown() {
+	xxx_messageInfo_PrePrepareChunk.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_PrePrepareChunk proto.InternalMessageInfo
+
+func (m *PrePrepareChunk) GetView() uint64 {
+	if m != nil {
+		return m.View
+	}
+	return 0
+}
+
+func (m *PrePrepareChunk) GetSeq() uint64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+func (m *PrePrepareChunk) GetLeader() uint64 {
+	if m != nil {
+		return m.Leader
+	}
+	return 0
+}
+
+func (m *PrePrepareChunk) GetIndex() uint32 {
+	if m != nil {
+		return m.Index
+	}
+	return 0
+}
+
+func (m *PrePrepareChunk) GetShard() []byte {
+	if m != nil {
+		return m.Shard
+	}
+	return nil
+}
+
+func (m *PrePrepareChunk) GetDigest() string {
+	if m != nil {
+		return m.Digest
+	}
+	return ""
+}
+
+func (m *PrePrepareChunk) GetSize() uint64 {
+	if m != nil {
+		return m.Size
+	}
+	return 0
+}
+
+func (m *PrePrepareChunk) GetDataShards() uint32 {
+	if m != nil {
+		return m.DataShards
+	}
+	return 0
+}
+
+func (m *PrePrepareChunk) GetShardHashes() [][]byte {
+	if m != nil {
+		return m.ShardHashes
+	}
+	return nil
+}
+
+type RequestID struct {
+	Id                   string   `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	ClientId             string   `protobuf:"bytes,2,opt,name=client_id,json=clientId,proto3" json:"client_id,omitempty"`
+	XXX_NoUnkeyedLiteral struct{} `json:"-"`
+	XXX_unrecognized     []byte   `json:"-"`
+	XXX_sizecache        int32    `json:"-"`
+}
+
+func (m *RequestID) Reset()         { *m = RequestID{} }
+func (m *RequestID) String() string { return proto.CompactTextString(m) }
+func (*RequestID) ProtoMessage()    {}
+func (*RequestID) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0d30f2fcdff47131, []int{3}
+}
+
+func (m *RequestID) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_RequestID.Unmarshal(m, b)
+}
+func (m *RequestID) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_RequestID.Marshal(b, m, deterministic)
+}
+func (m *RequestID) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_RequestID.Merge(m, src)
+}
+func (m *RequestID) XXX_Size() int {
+	return xxx_messageInfo_RequestID.Size(m)
+}
+func (m *RequestID) XXX_DiscardUnknown() {
+	xxx_messageInfo_RequestID.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_RequestID proto.InternalMessageInfo
+
+func (m *RequestID) GetId() string {
+	if m != nil {
+		return m.Id
+	}
+	return ""
+}
+
+func (m *RequestID) GetClientId() string {
+	if m != nil {
+		return m.ClientId
+	}
+	return ""
+}
+
+// Asks for requests which are missing to assemble the payload of a proposal.
+type FetchRequests struct {
+	RequestIds           []*RequestID `protobuf:"bytes,1,rep,name=request_ids,json=requestIds,proto3" json:"request_ids,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *FetchRequests) Reset()         { *m = FetchRequests{} }
+func (m *FetchRequests) String() string { return proto.CompactTextString(m) }
+func (*FetchRequests) ProtoMessage()    {}
+func (*FetchRequests) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0d30f2fcdff47131, []int{4}
+}
+
+func (m *FetchRequests) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FetchRequests.Unmarshal(m, b)
+}
+func (m *FetchRequests) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FetchRequests.Marshal(b, m, deterministic)
+}
+func (m *FetchRequests) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FetchRequests.Merge(m, src)
+}
+func (m *FetchRequests) XXX_Size() int {
+	return xxx_messageInfo_FetchRequests.Size(m)
+}
+func (m *FetchRequests) XXX_DiscardUnknown() {
+	xxx_messageInfo_FetchRequests.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FetchRequests proto.InternalMessageInfo
+
+func (m *FetchRequests) GetRequestIds() []*RequestID {
+	if m != nil {
+		return m.RequestIds
+	}
+	return nil
+}
+
+// Answers FetchRequests with the requests the sender has.
+type FetchedRequests struct {
+	Requests             [][]byte `protobuf:"bytes,1,rep,name=requests,proto3" json:"requests,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
 }
 
+func (m *FetchedRequests) Reset()         { *m = FetchedRequests{} }
+func (m *FetchedRequests) String() string { return proto.CompactTextString(m) }
+func (*FetchedRequests) ProtoMessage()    {}
+func (*FetchedRequests) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0d30f2fcdff47131, []int{5}
+}
+
+func (m *FetchedRequests) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_FetchedRequests.Unmarshal(m, b)
+}
+func (m *FetchedRequests) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_FetchedRequests.Marshal(b, m, deterministic)
+}
+func (m *FetchedRequests) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_FetchedRequests.Merge(m, src)
+}
+func (m *FetchedRequests) XXX_Size() int {
+	return xxx_messageInfo_FetchedRequests.Size(m)
+}
+func (m *FetchedRequests) XXX_DiscardUnknown() {
+	xxx_messageInfo_FetchedRequests.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_FetchedRequests proto.InternalMessageInfo
+
+func (m *FetchedRequests) GetRequests() [][]byte {
+	if m != nil {
+		return m.Requests
+	}
+	return nil
+}
+
+type Prepare struct {
+	View      uint64 `protobuf:"varint,1,opt,name=view,proto3" json:"view,omitempty"`
+	Seq       uint64 `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`
+	Digest    string `protobuf:"bytes,3,opt,name=digest,proto3" json:"digest,omitempty"`
+	Assist    bool   `protobuf:"varint,4,opt,name=assist,proto3" json:"assist,omitempty"`
+	Signature []byte `protobuf:"bytes,5,opt,name=signature,proto3" json:"signature,omitempty"`
+	// The signature of the sender on the proposal, if the fast path is enabled.
+	FastPathSignature    *Signature `protobuf:"bytes,6,opt,name=fast_path_signature,json=fastPathSignature,proto3" json:"fast_path_signature,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
+}
+
 func (m *Prepare) Reset()         { *m = Prepare{} }
 func (m *Prepare) String() string { return proto.CompactTextString(m) }
 func (*Prepare) ProtoMessage()    {}
 func (*Prepare) Descriptor() ([]byte, []int) {
-	return fileDescriptor_0d30f2fcdff47131, []int{2}
+	return fileDescriptor_0d30f2fcdff47131, []int{6}
 }
 
 func (m *Prepare) XXX_Unmarshal(b []byte) error {
@@ -317,6 +669,13 @@ func (m *Prepare) GetSignature() []byte {
 	return nil
 }
 
+func (m *Prepare) GetFastPathSignature() *Signature {
+	if m != nil {
+		return m.FastPathSignature
+	}
+	return nil
+}
+
 type ProposedRecord struct {
 	PrePrepare           *PrePrepare `protobuf:"bytes,1,opt,name=pre_prepare,json=prePrepare,proto3" json:"pre_prepare,omitempty"`
 	Prepare              *Prepare    `protobuf:"bytes,2,opt,name=prepare,proto3" json:"prepare,omitempty"`
@@ -329,7 +688,7 @@ func (m *ProposedRecord) Reset()         { *m = ProposedRecord{} }
 func (m *ProposedRecord) String() string { return proto.CompactTextString(m) }
 func (*ProposedRecord) ProtoMessage()    {}
 func (*ProposedRecord) Descriptor() ([]byte, []int) {
-	return fileDescriptor_0d30f2fcdff47131, []int{3}
+	return fileDescriptor_0d30f2fcdff47131, []int{7}
 }
 
 func (m *ProposedRecord) XXX_Unmarshal(b []byte) error {
@@ -379,7 +738,7 @@ func (m *Commit) Reset()         { *m = Commit{} }
 func (m *Commit) String() string { return proto.CompactTextString(m) }
 func (*Commit) ProtoMessage()    {}
 func (*Commit) Descriptor() ([]byte, []int) {
-	return fileDescriptor_0d30f2fcdff47131, []int{4}
+	return fileDescriptor_0d30f2fcdff47131, []int{8}
 }
 
 func (m *Commit) XXX_Unmarshal(b []byte) error {
@@ -435,6 +794,80 @@ func (m *Commit) GetAssist() bool {
 	return false
 }
 
+// Aggregates the votes of a quorum on a proposal, and is broadcast by the collector of the votes.
+type VoteCertificate struct {
+	View   uint64 `protobuf:"varint,1,opt,name=view,proto3" json:"view,omitempty"`
+	Seq    uint64 `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`
+	Digest string `protobuf:"bytes,3,opt,name=digest,proto3" json:"digest,omitempty"`
+	// The signatures on the prepares, over the prepares without their signatures.
+	Prepares []*Signature `protobuf:"bytes,4,rep,name=prepares,proto3" json:"prepares,omitempty"`
+	// The signatures on the proposal, sent in commits.
+	Commits              []*Signature `protobuf:"bytes,5,rep,name=commits,proto3" json:"commits,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}     `json:"-"`
+	XXX_unrecognized     []byte       `json:"-"`
+	XXX_sizecache        int32        `json:"-"`
+}
+
+func (m *VoteCertificate) Reset()         { *m = VoteCertificate{} }
+func (m *VoteCertificate) String() string { return proto.CompactTextString(m) }
+func (*VoteCertificate) ProtoMessage()    {}
+func (*VoteCertificate) Descriptor() ([]byte, []int) {
+	return fileDescriptor_0d30f2fcdff47131, []int{9}
+}
+
+func (m *VoteCertificate) XXX_Unmarshal(b []byte) error {
+	return xxx_messageInfo_VoteCertificate.Unmarshal(m, b)
+}
+func (m *VoteCertificate) XXX_Marshal(b []byte, deterministic bool) ([]byte, error) {
+	return xxx_messageInfo_VoteCertificate.Marshal(b, m, deterministic)
+}
+func (m *VoteCertificate) XXX_Merge(src proto.Message) {
+	xxx_messageInfo_VoteCertificate.Merge(m, src)
+}
+func (m *VoteCertificate) XXX_Size() int {
+	return xxx_messageInfo_VoteCertificate.Size(m)
+}
+func (m *VoteCertificate) XXX_DiscardUnknown() {
+	xxx_messageInfo_VoteCertificate.DiscardUnknown(m)
+}
+
+var xxx_messageInfo_VoteCertificate proto.InternalMessageInfo
+
+func (m *VoteCertificate) GetView() uint64 {
+	if m != nil {
+		return m.View
+	}
+	return 0
+}
+
+func (m *VoteCertificate) GetSeq() uint64 {
+	if m != nil {
+		return m.Seq
+	}
+	return 0
+}
+
+func (m *VoteCertificate) GetDigest() string {
+	if m != nil {
+		return m.Digest
+	}
+	return ""
+}
+
+func (m *VoteCertificate) GetPrepares() []*Signature {
+	if m != nil {
+		return m.Prepares
+	}
+	return nil
+}
+
+func (m *VoteCertificate) GetCommits() []*Signature {
+	if m != nil {
+		return m.Commits
+	}
+	return nil
+}
+
 type Error struct {
 	View                 uint64   `protobuf:"varint,1,opt,name=view,proto3" json:"view,omitempty"`
 	Seq                  uint64   `protobuf:"varint,2,opt,name=seq,proto3" json:"seq,omitempty"`
@@ -449,7 +882,7 @@ func (m *Error) Reset()         { *m = Error{} }
 func (m *Error) String() string { return proto.CompactTextString(m) }
 func (*Error) ProtoMessage()    {}
 func (*Error) Descriptor() ([]byte, []int) {
-	return fileDescriptor_0d30f2fcdff47131, []int{5}
+	return fileDescriptor_0d30f2fcdff47131, []int{10}
 }
 
 func (m *Error) XXX_Unmarshal(b []byte) error {
@@ -510,7 +943,7 @@ func (m *ViewChange) Reset()         { *m = ViewChange{} }
 func (m *ViewChange) String() string { return proto.CompactTextString(m) }
 func (*ViewChange) ProtoMessage()    {}
 func (*ViewChange) Descriptor() ([]byte, []int) {
-	return fileDescriptor_0d30f2fcdff47131, []int{6}
+	return fileDescriptor_0d30f2fcdff47131, []int{11}
 }
 
 func (m *ViewChange) XXX_Unmarshal(b []byte) error {
@@ -551,16 +984,18 @@ type ViewData struct {
 	LastDecisionSignatures []*Signature `protobuf:"bytes,3,rep,name=last_decision_signatures,json=lastDecisionSignatures,proto3" json:"last_decision_signatures,omitempty"`
 	InFlightProposal       *Proposal    `protobuf:"bytes,4,opt,name=in_flight_proposal,json=inFlightProposal,proto3" json:"in_flight_proposal,omitempty"`
 	InFlightPrepared       bool         `protobuf:"varint,5,opt,name=in_flight_prepared,json=inFlightPrepared,proto3" json:"in_flight_prepared,omitempty"`
-	XXX_NoUnkeyedLiteral   struct{}     `json:"-"`
-	XXX_unrecognized       []byte       `json:"-"`
-	XXX_sizecache          int32        `json:"-"`
+	// The signature of the sender on the in flight proposal, if it was sent in a prepare on the fast path.
+	InFlightSignature    *Signature `protobuf:"bytes,6,opt,name=in_flight_signature,json=inFlightSignature,proto3" json:"in_flight_signature,omitempty"`
+	XXX_NoUnkeyedLiteral struct{}   `json:"-"`
+	XXX_unrecognized     []byte     `json:"-"`
+	XXX_sizecache        int32      `json:"-"`
 }
 
 func (m *ViewData) Reset()         { *m = ViewData{} }
 func (m *ViewData) String() string { return proto.CompactTextString(m) }
 func (*ViewData) ProtoMessage()    {}
 func (*ViewData) Descriptor() ([]byte, []int) {
-	return fileDescriptor_0d30f2fcdff47131, []int{7}
+	return fileDescriptor_0d30f2fcdff47131, []int{12}
 }
 
 func (m *ViewData) XXX_Unmarshal(b []byte) error {
@@ -616,6 +1051,13 @@ func (m *ViewData) GetInFlightPrepared() bool {
 	return false
 }
 
+func (m *ViewData) GetInFlightSignature() *Signature {
+	if m != nil {
+		return m.InFlightSignature
+	}
+	return nil
+}
+
 type SignedViewData struct {
 	RawViewData          []byte   `protobuf:"bytes,1,opt,name=raw_view_data,json=rawViewData,proto3" json:"raw_view_data,omitempty"`
 	Signer               uint64   `protobuf:"varint,2,opt,name=signer,proto3" json:"signer,omitempty"`
@@ -629,7 +1071,7 @@ func (m *SignedViewData) Reset()         { *m = SignedViewData{} }
 func (m *SignedViewData) String() string { return proto.CompactTextString(m) }
 func (*SignedViewData) ProtoMessage()    {}
 func (*SignedViewData) Descriptor() ([]byte, []int) {
-	return fileDescriptor_0d30f2fcdff47131, []int{8}
+	return fileDescriptor_0d30f2fcdff47131, []int{13}
 }
 
 func (m *SignedViewData) XXX_Unmarshal(b []byte) error {
@@ -682,7 +1124,7 @@ func (m *NewView) Reset()         { *m = NewView{} }
 func (m *NewView) String() string { return proto.CompactTextString(m) }
 func (*NewView) ProtoMessage()    {}
 func (*NewView) Descriptor() ([]byte, []int) {
-	return fileDescriptor_0d30f2fcdff47131, []int{9}
+	return fileDescriptor_0d30f2fcdff47131, []int{14}
 }
 
 func (m *NewView) XXX_Unmarshal(b []byte) error {
@@ -721,7 +1163,7 @@ func (m *HeartBeat) Reset()         { *m = HeartBeat{} }
 func (m *HeartBeat) String() string { return proto.CompactTextString(m) }
 func (*HeartBeat) ProtoMessage()    {}
 func (*HeartBeat) Descriptor() ([]byte, []int) {
-	return fileDescriptor_0d30f2fcdff47131, []int{10}
+	return fileDescriptor_0d30f2fcdff47131, []int{15}
 }
 
 func (m *HeartBeat) XXX_Unmarshal(b []byte) error {
@@ -762,7 +1204,7 @@ func (m *Signature) Reset()         { *m = Signature{} }
 func (m *Signature) String() string { return proto.CompactTextString(m) }
 func (*Signature) ProtoMessage()    {}
 func (*Signature) Descriptor() ([]byte, []int) {
-	return fileDescriptor_0d30f2fcdff47131, []int{11}
+	return fileDescriptor_0d30f2fcdff47131, []int{16}
 }
 
 func (m *Signature) XXX_Unmarshal(b []byte) error {
@@ -818,7 +1260,7 @@ func (m *Proposal) Reset()         { *m = Proposal{} }
 func (m *Proposal) String() string { return proto.CompactTextString(m) }
 func (*Proposal) ProtoMessage()    {}
 func (*Proposal) Descriptor() ([]byte, []int) {
-	return fileDescriptor_0d30f2fcdff47131, []int{12}
+	return fileDescriptor_0d30f2fcdff47131, []int{17}
 }
 
 func (m *Proposal) XXX_Unmarshal(b []byte) error {
@@ -870,6 +1312,12 @@ func (m *Proposal) GetVerificationSequence() uint64 {
 type ViewMetadata struct {
 	ViewId               uint64   `protobuf:"varint,1,opt,name=view_id,json=viewId,proto3" json:"view_id,omitempty"`
 	LatestSequence       uint64   `protobuf:"varint,2,opt,name=latest_sequence,json=latestSequence,proto3" json:"latest_sequence,omitempty"`
+	DecisionsInView      uint64   `protobuf:"varint,3,opt,name=decisions_in_view,json=decisionsInView,proto3" json:"decisions_in_view,omitempty"`
+	BlackList            []uint64 `protobuf:"varint,4,rep,packed,name=black_list,json=blackList,proto3" json:"black_list,omitempty"`
+	Version              uint32   `protobuf:"varint,5,opt,name=version,proto3" json:"version,omitempty"`
+	ApplicationMetadata  []byte   `protobuf:"bytes,6,opt,name=application_metadata,json=applicationMetadata,proto3" json:"application_metadata,omitempty"`
+	Timestamp            int64    `protobuf:"varint,7,opt,name=timestamp,proto3" json:"timestamp,omitempty"`
+	ProtocolVersion      uint32   `protobuf:"varint,8,opt,name=protocol_version,json=protocolVersion,proto3" json:"protocol_version,omitempty"`
 	XXX_NoUnkeyedLiteral struct{} `json:"-"`
 	XXX_unrecognized     []byte   `json:"-"`
 	XXX_sizecache        int32    `json:"-"`
@@ -879,7 +1327,7 @@ func (m *ViewMetadata) Reset()         { *m = ViewMetadata{} }
 func (m *ViewMetadata) String() string { return proto.CompactTextString(m) }
 func (*ViewMetadata) ProtoMessage()    {}
 func (*ViewMetadata) Descriptor() ([]byte, []int) {
-	return fileDescriptor_0d30f2fcdff47131, []int{13}
+	return fileDescriptor_0d30f2fcdff47131, []int{18}
 }
 
 func (m *ViewMetadata) XXX_Unmarshal(b []byte) error {
@@ -914,6 +1362,48 @@ func (m *ViewMetadata) GetLatestSequence() uint64 {
 	return 0
 }
 
+func (m *ViewMetadata) GetDecisionsInView() uint64 {
+	if m != nil {
+		return m.DecisionsInView
+	}
+	return 0
+}
+
+func (m *ViewMetadata) GetBlackList() []uint64 {
+	if m != nil {
+		return m.BlackList
+	}
+	return nil
+}
+
+func (m *ViewMetadata) GetVersion() uint32 {
+	if m != nil {
+		return m.Version
+	}
+	return 0
+}
+
+func (m *ViewMetadata) GetApplicationMetadata() []byte {
+	if m != nil {
+		return m.ApplicationMetadata
+	}
+	return nil
+}
+
+func (m *ViewMetadata) GetTimestamp() int64 {
+	if m != nil {
+		return m.Timestamp
+	}
+	return 0
+}
+
+func (m *ViewMetadata) GetProtocolVersion() uint32 {
+	if m != nil {
+		return m.ProtocolVersion
+	}
+	return 0
+}
+
 type SavedMessage struct {
 	// Types that are valid to be assigned to Content:
 	//	*SavedMessage_ProposedRecord
@@ -928,7 +1418,7 @@ func (m *SavedMessage) Reset()         { *m = SavedMessage{} }
 func (m *SavedMessage) String() string { return proto.CompactTextString(m) }
 func (*SavedMessage) ProtoMessage()    {}
 func (*SavedMessage) Descriptor() ([]byte, []int) {
-	return fileDescriptor_0d30f2fcdff47131, []int{14}
+	return fileDescriptor_0d30f2fcdff47131, []int{19}
 }
 
 func (m *SavedMessage) XXX_Unmarshal(b []byte) error {
@@ -997,9 +1487,14 @@ func (*SavedMessage) XXX_OneofWrappers() []interface{} {
 func init() {
 	proto.RegisterType((*Message)(nil), "smartbftprotos.Message")
 	proto.RegisterType((*PrePrepare)(nil), "smartbftprotos.PrePrepare")
+	proto.RegisterType((*PrePrepareChunk)(nil), "smartbftprotos.PrePrepareChunk")
+	proto.RegisterType((*RequestID)(nil), "smartbftprotos.RequestID")
+	proto.RegisterType((*FetchRequests)(nil), "smartbftprotos.FetchRequests")
+	proto.RegisterType((*FetchedRequests)(nil), "smartbftprotos.FetchedRequests")
 	proto.RegisterType((*Prepare)(nil), "smartbftprotos.Prepare")
 	proto.RegisterType((*ProposedRecord)(nil), "smartbftprotos.ProposedRecord")
 	proto.RegisterType((*Commit)(nil), "smartbftprotos.Commit")
+	proto.RegisterType((*VoteCertificate)(nil), "smartbftprotos.VoteCertificate")
 	proto.RegisterType((*Error)(nil), "smartbftprotos.Error")
 	proto.RegisterType((*ViewChange)(nil), "smartbftprotos.ViewChange")
 	proto.RegisterType((*ViewData)(nil), "smartbftprotos.ViewData")
@@ -1015,57 +1510,90 @@ func init() {
 func init() { proto.RegisterFile("smartbftprotos/messages.proto", fileDescriptor_0d30f2fcdff47131) }
 
 var fileDescriptor_0d30f2fcdff47131 = []byte{
-	// 818 bytes of a gzipped FileDescriptorProto
-	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x55, 0xcb, 0x6e, 0xf3, 0x44,
-	0x14, 0x8e, 0x73, 0xb3, 0x7d, 0x9a, 0x3f, 0xad, 0x46, 0x6d, 0x6a, 0x0a, 0x94, 0xca, 0x1b, 0x58,
-	0x40, 0xa1, 0x14, 0x09, 0x09, 0xd4, 0x05, 0x6d, 0xa9, 0x52, 0xa1, 0xa2, 0x6a, 0x22, 0xb1, 0x43,
-	0xd6, 0x34, 0x3e, 0x4d, 0x8c, 0x12, 0xdb, 0x9d, 0x99, 0x26, 0xb0, 0x40, 0xe2, 0x05, 0x58, 0xf7,
-	0x39, 0x78, 0x1c, 0xde, 0x06, 0xcd, 0xc5, 0x8e, 0x1d, 0x42, 0x4b, 0xd5, 0xdd, 0x7c, 0x33, 0xdf,
-	0x77, 0x66, 0xce, 0x75, 0xe0, 0x43, 0x31, 0x67, 0x5c, 0xde, 0xdd, 0xcb, 0x9c, 0x67, 0x32, 0x13,
-	0x9f, 0xcf, 0x51, 0x08, 0x36, 0x41, 0x71, 0xac, 0x31, 0xe9, 0xd7, 0x8f, 0xc3, 0xbf, 0x5b, 0xe0,
-	0xde, 0x18, 0x0a, 0x39, 0x83, 0xad, 0x9c, 0x63, 0x94, 0x73, 0xcc, 0x19, 0xc7, 0xc0, 0x39, 0x72,
-	0x3e, 0xd9, 0xfa, 0xf2, 0xe0, 0xb8, 0xae, 0x38, 0xbe, 0xe5, 0x78, 0x6b, 0x18, 0xc3, 0x06, 0x85,
-	0xbc, 0x44, 0xe4, 0x14, 0xdc, 0x42, 0xda, 0xd4, 0xd2, 0xfd, 0x0d, 0x52, 0xab, 0x2b, 0x98, 0xe4,
-	0x0b, 0xe8, 0x8e, 0xb3, 0xf9, 0x3c, 0x91, 0x41, 0x4b, 0x6b, 0x06, 0xeb, 0x9a, 0x0b, 0x7d, 0x3a,
-	0x6c, 0x50, 0xcb, 0x23, 0x9f, 0x41, 0x07, 0x39, 0xcf, 0x78, 0xd0, 0xd6, 0x82, 0xbd, 0x75, 0xc1,
-	0xf7, 0xea, 0x70, 0xd8, 0xa0, 0x86, 0xa5, 0x9c, 0x5a, 0x24, 0xb8, 0x8c, 0xc6, 0x53, 0x96, 0x4e,
-	0x30, 0xe8, 0x6c, 0x76, 0xea, 0xa7, 0x04, 0x97, 0x17, 0x9a, 0xa1, 0x9c, 0x5a, 0x94, 0x88, 0x9c,
-	0x81, 0xaf, 0xe5, 0x31, 0x93, 0x2c, 0xe8, 0x6a, 0xf1, 0xe1, 0xba, 0x78, 0x94, 0x4c, 0x52, 0x8c,
-	0x95, 0x89, 0x4b, 0x26, 0xd9, 0xb0, 0x41, 0xbd, 0x85, 0x5d, 0x93, 0xaf, 0xc0, 0x4b, 0x71, 0x19,
-	0x29, 0x1c, 0xb8, 0x9b, 0x83, 0xf2, 0x23, 0x2e, 0x95, 0x54, 0x05, 0x25, 0x35, 0x4b, 0xf2, 0x0d,
-	0xc0, 0x14, 0x19, 0x97, 0xd1, 0x1d, 0x32, 0x19, 0x78, 0x5a, 0xf7, 0xde, 0xba, 0x6e, 0xa8, 0x18,
-	0xe7, 0xc8, 0x54, 0x6c, 0xfc, 0x69, 0x01, 0xce, 0x7d, 0x70, 0xc7, 0x59, 0x2a, 0x31, 0x95, 0xe1,
-	0x14, 0x60, 0x95, 0x2c, 0x42, 0xa0, 0xad, 0x9f, 0xa1, 0xd2, 0xda, 0xa6, 0x7a, 0x4d, 0x76, 0xa0,
-	0x25, 0xf0, 0x41, 0xa7, 0xab, 0x4d, 0xd5, 0x52, 0x3d, 0x38, 0xe7, 0x59, 0x9e, 0x09, 0x36, 0xb3,
-	0x19, 0x09, 0xfe, 0x9d, 0x45, 0x73, 0x4e, 0x4b, 0x66, 0xf8, 0x3b, 0xb8, 0xaf, 0xbb, 0x66, 0x00,
-	0xdd, 0x38, 0x99, 0xa0, 0x30, 0x69, 0xf7, 0xa9, 0x45, 0x6a, 0x9f, 0x09, 0x91, 0x08, 0xa9, 0xb3,
-	0xeb, 0x51, 0x8b, 0xc8, 0x07, 0xe0, 0x8b, 0x64, 0x92, 0x32, 0xf9, 0xc8, 0x4d, 0x0e, 0x7b, 0x74,
-	0xb5, 0x11, 0xfe, 0xe1, 0x40, 0xdf, 0xbc, 0x0a, 0x63, 0x8a, 0xe3, 0x8c, 0xc7, 0xe4, 0xdb, 0x57,
-	0xd6, 0x72, 0xad, 0x92, 0x4f, 0xfe, 0x6f, 0x25, 0x97, 0x75, 0x1c, 0x3e, 0x39, 0xd0, 0x35, 0xa5,
-	0xfa, 0xc6, 0x08, 0x7c, 0x5d, 0xf5, 0xb4, 0xbd, 0x39, 0xf5, 0xa3, 0x82, 0x50, 0x09, 0x42, 0x25,
-	0x74, 0x9d, 0x6a, 0xe8, 0xc2, 0x9f, 0xa1, 0xa3, 0x5b, 0xe2, 0xed, 0x99, 0xe1, 0xc8, 0x44, 0x96,
-	0xea, 0x47, 0xf9, 0xd4, 0xa2, 0xf0, 0x3b, 0x80, 0x55, 0xf3, 0x90, 0xf7, 0xc1, 0x4f, 0xf1, 0x57,
-	0x19, 0x55, 0x2e, 0xf2, 0xd4, 0x86, 0x2e, 0xeb, 0x95, 0x89, 0x66, 0xcd, 0xc4, 0x5f, 0x4d, 0xf0,
-	0x8a, 0xee, 0x79, 0xde, 0xc2, 0x19, 0xbc, 0x9b, 0x31, 0x21, 0xa3, 0x18, 0xc7, 0x89, 0x48, 0xac,
-	0xa1, 0xe7, 0x4a, 0xb4, 0xa7, 0xe8, 0x97, 0x96, 0x4d, 0x46, 0x10, 0xd4, 0xe4, 0x51, 0x19, 0x3d,
-	0x11, 0xb4, 0x8e, 0x5a, 0xcf, 0x87, 0x7a, 0x50, 0x35, 0x55, 0x6e, 0x0b, 0x72, 0x05, 0x24, 0x49,
-	0xa3, 0xfb, 0x59, 0x32, 0x99, 0xca, 0xa8, 0xec, 0x9d, 0xf6, 0x0b, 0x0f, 0xdb, 0x49, 0xd2, 0x2b,
-	0x2d, 0x29, 0x76, 0xc8, 0xa7, 0x75, 0x3b, 0xba, 0xac, 0x62, 0x9b, 0xcb, 0x0a, 0xdb, 0xec, 0x87,
-	0xbf, 0x40, 0xbf, 0x3e, 0x76, 0x48, 0x08, 0xef, 0x38, 0x33, 0xa3, 0xc6, 0x4c, 0x2b, 0x47, 0xb7,
-	0xc9, 0x16, 0x67, 0xcb, 0x92, 0x33, 0x80, 0xae, 0x72, 0x19, 0xb9, 0xcd, 0xb8, 0x45, 0xf5, 0xf6,
-	0x6a, 0xad, 0xb7, 0xd7, 0x08, 0x5c, 0x3b, 0xa4, 0xc8, 0x10, 0x76, 0xb4, 0x24, 0xae, 0xdc, 0xd3,
-	0xd4, 0x91, 0x7b, 0x61, 0x2a, 0xd2, 0xbe, 0xa8, 0xe1, 0xf0, 0x23, 0xf0, 0xcb, 0x09, 0xb6, 0xa9,
-	0x34, 0xc3, 0x1f, 0xc0, 0x1f, 0x55, 0x8b, 0xdb, 0x3e, 0xdc, 0xa9, 0x3d, 0x7c, 0x17, 0x3a, 0x0b,
-	0x36, 0x7b, 0x34, 0x7d, 0xda, 0xa3, 0x06, 0xa8, 0xaa, 0x9e, 0x8b, 0x89, 0x75, 0x44, 0x2d, 0xc3,
-	0x3f, 0x1d, 0xf0, 0xca, 0x48, 0x0f, 0xa0, 0x3b, 0x45, 0x16, 0x5b, 0x63, 0x3d, 0x6a, 0x11, 0x09,
-	0xc0, 0xcd, 0xd9, 0x6f, 0xb3, 0x8c, 0xc5, 0xd6, 0x5c, 0x01, 0xc9, 0x01, 0x78, 0x73, 0x94, 0x4c,
-	0xbb, 0x6b, 0xac, 0x96, 0x98, 0x9c, 0xc2, 0xde, 0x02, 0x79, 0x72, 0x9f, 0x8c, 0x99, 0xd4, 0x35,
-	0x85, 0x0f, 0x8f, 0x98, 0x8e, 0x4d, 0xf3, 0xb6, 0xe9, 0x6e, 0xf5, 0x70, 0x64, 0xcf, 0xc2, 0x5b,
-	0xe8, 0xa9, 0x48, 0xdc, 0x14, 0x46, 0xf6, 0xc1, 0xd5, 0x01, 0x4d, 0xe2, 0xc2, 0x41, 0x05, 0xaf,
-	0x63, 0xf2, 0x31, 0x6c, 0xcf, 0x98, 0x44, 0x21, 0x57, 0x76, 0x4d, 0xea, 0xfa, 0x66, 0xbb, 0xb4,
-	0xf8, 0xe4, 0x40, 0x6f, 0xc4, 0x16, 0x18, 0x17, 0xbf, 0xf9, 0x35, 0x6c, 0xe7, 0x76, 0x26, 0x46,
-	0x5c, 0x0f, 0x45, 0x3b, 0x05, 0x0f, 0x37, 0x17, 0x65, 0x31, 0x3a, 0x87, 0x0d, 0xda, 0xcf, 0xeb,
-	0xc3, 0xf4, 0xa4, 0xfc, 0xa4, 0xff, 0x63, 0x1c, 0xda, 0x3b, 0x57, 0xbf, 0x74, 0xe5, 0x1b, 0xba,
-	0xeb, 0x6a, 0xd2, 0xe9, 0x3f, 0x01, 0x00, 0x00, 0xff, 0xff, 0x1a, 0x40, 0x76, 0xeb, 0x9a, 0x08,
+	// 1346 bytes of a gzipped FileDescriptorProto
+	0x1f, 0x8b, 0x08, 0x00, 0x00, 0x00, 0x00, 0x00, 0x02, 0xff, 0xa4, 0x57, 0x4b, 0x93, 0xdb, 0x44,
+	0x10, 0xb6, 0xfc, 0x56, 0xfb, 0xb5, 0x99, 0x6c, 0x1c, 0x11, 0xf2, 0x58, 0x74, 0x80, 0x85, 0x22,
+	0x21, 0x21, 0x50, 0x50, 0xa1, 0x72, 0x20, 0x1b, 0xb6, 0xec, 0x4a, 0x02, 0x5b, 0xe3, 0xaa, 0xdc,
+	0x28, 0xd5, 0xac, 0x34, 0xb6, 0x44, 0x6c, 0x49, 0x99, 0x99, 0xf5, 0x2e, 0x9c, 0xf8, 0x03, 0x39,
+	0x70, 0xe2, 0x77, 0x70, 0xe6, 0x0f, 0xf0, 0x5f, 0xf8, 0x0b, 0x1c, 0xa8, 0x79, 0xe8, 0xe5, 0x38,
+	0xd9, 0x6c, 0xe5, 0x36, 0xdd, 0xf3, 0x75, 0x6b, 0xfa, 0xdd, 0x82, 0x1b, 0x7c, 0x45, 0x98, 0x38,
+	0x9e, 0x8b, 0x94, 0x25, 0x22, 0xe1, 0x5f, 0xac, 0x28, 0xe7, 0x64, 0x41, 0xf9, 0x1d, 0x45, 0xa3,
+	0x61, 0xf5, 0xda, 0xfd, 0xa3, 0x03, 0x9d, 0x67, 0x1a, 0x82, 0x1e, 0x42, 0x2f, 0x65, 0xd4, 0x4b,
+	0x19, 0x4d, 0x09, 0xa3, 0x8e, 0xb5, 0x67, 0xed, 0xf7, 0xbe, 0xbc, 0x76, 0xa7, 0x2a, 0x71, 0xe7,
+	0x88, 0xd1, 0x23, 0x8d, 0x98, 0xd4, 0x30, 0xa4, 0x39, 0x85, 0xee, 0x43, 0x27, 0x13, 0xad, 0x2b,
+	0xd1, 0xab, 0x5b, 0x44, 0x8d, 0x5c, 0x86, 0x44, 0x77, 0xa1, 0xed, 0x27, 0xab, 0x55, 0x24, 0x9c,
+	0x86, 0x92, 0x19, 0x6f, 0xca, 0x1c, 0xa8, 0xdb, 0x49, 0x0d, 0x1b, 0x1c, 0xba, 0x0d, 0x2d, 0xca,
+	0x58, 0xc2, 0x9c, 0xa6, 0x12, 0xb8, 0xb2, 0x29, 0xf0, 0x83, 0xbc, 0x9c, 0xd4, 0xb0, 0x46, 0x49,
+	0xa3, 0xd6, 0x11, 0x3d, 0xf5, 0xfc, 0x90, 0xc4, 0x0b, 0xea, 0xb4, 0xb6, 0x1b, 0xf5, 0x3c, 0xa2,
+	0xa7, 0x07, 0x0a, 0x21, 0x8d, 0x5a, 0xe7, 0x14, 0x7a, 0x08, 0xb6, 0x12, 0x0f, 0x88, 0x20, 0x4e,
+	0x5b, 0x09, 0xdf, 0xdc, 0x14, 0x9e, 0x45, 0x8b, 0x98, 0x06, 0x52, 0xc5, 0x63, 0x22, 0xc8, 0xa4,
+	0x86, 0xbb, 0x6b, 0x73, 0x46, 0x5f, 0x41, 0x37, 0xa6, 0xa7, 0x9e, 0xa4, 0x9d, 0xce, 0x76, 0xa7,
+	0xfc, 0x48, 0x4f, 0xa5, 0xa8, 0x74, 0x4a, 0xac, 0x8f, 0xe8, 0x01, 0x40, 0x48, 0x09, 0x13, 0xde,
+	0x31, 0x25, 0xc2, 0xe9, 0x2a, 0xb9, 0x0f, 0x36, 0xe5, 0x26, 0x12, 0xf1, 0x88, 0x12, 0xe9, 0x1b,
+	0x3b, 0xcc, 0x08, 0xf4, 0x14, 0x76, 0xd6, 0x89, 0xa0, 0x9e, 0x4f, 0x99, 0x88, 0xe6, 0x91, 0x4f,
+	0x04, 0x75, 0xfa, 0x4a, 0xc3, 0xad, 0xd7, 0x8c, 0x4e, 0x04, 0x3d, 0x28, 0x60, 0x93, 0x1a, 0x1e,
+	0xad, 0xab, 0x2c, 0x74, 0x08, 0xc3, 0x39, 0x15, 0x7e, 0xe8, 0x31, 0xfa, 0xf2, 0x84, 0x72, 0xc1,
+	0x9d, 0x81, 0xd2, 0x75, 0x63, 0x53, 0xd7, 0xa1, 0x44, 0x61, 0x03, 0x9a, 0xd4, 0xf0, 0x60, 0x5e,
+	0x66, 0xc8, 0x57, 0x29, 0x06, 0x0d, 0x0a, 0x4d, 0xc3, 0xed, 0xaf, 0x3a, 0xd4, 0xb8, 0x92, 0xae,
+	0xd1, 0xbc, 0xca, 0x42, 0xcf, 0xe0, 0x52, 0x29, 0x51, 0x3d, 0x3f, 0x3c, 0x89, 0x5f, 0x38, 0xa3,
+	0xed, 0xea, 0x8a, 0x74, 0x3d, 0x90, 0x30, 0xa9, 0x2e, 0xad, 0xb2, 0xd0, 0xc7, 0x30, 0x24, 0x27,
+	0x22, 0xa4, 0xb1, 0x90, 0x46, 0x47, 0x49, 0xec, 0xd8, 0x7b, 0xd6, 0x7e, 0x1f, 0x6f, 0x70, 0xd1,
+	0xa7, 0xb0, 0xa3, 0x94, 0xfa, 0xc9, 0xd2, 0x5b, 0x53, 0xc6, 0x25, 0x12, 0xf6, 0xac, 0xfd, 0x01,
+	0x1e, 0x65, 0xfc, 0xe7, 0x9a, 0x8d, 0xee, 0xc2, 0xee, 0x8a, 0x9c, 0x79, 0xaf, 0xc1, 0x7b, 0x0a,
+	0x8e, 0x56, 0xe4, 0xec, 0xa8, 0x2a, 0xf1, 0xc8, 0x86, 0x8e, 0x9f, 0xc4, 0x82, 0xc6, 0xc2, 0x7d,
+	0x55, 0x07, 0x28, 0x9e, 0x8d, 0x10, 0x34, 0x55, 0xfe, 0xc8, 0x7a, 0x6c, 0x62, 0x75, 0x46, 0x3b,
+	0xd0, 0xe0, 0xf4, 0xa5, 0xaa, 0xb3, 0x26, 0x96, 0x47, 0x99, 0x69, 0x29, 0x4b, 0xd2, 0x84, 0x93,
+	0xa5, 0x29, 0x25, 0xe7, 0x75, 0x57, 0xe8, 0x7b, 0x9c, 0x23, 0xd1, 0x4f, 0x30, 0x4e, 0x19, 0x5d,
+	0x7b, 0xba, 0xb6, 0x3c, 0x1e, 0x2d, 0x62, 0x22, 0x4e, 0x18, 0xe5, 0x4e, 0x73, 0xaf, 0xb1, 0x2d,
+	0xeb, 0x66, 0x19, 0x02, 0xef, 0x4a, 0x41, 0x5d, 0x9d, 0x39, 0x93, 0xa3, 0x07, 0xd0, 0x33, 0x01,
+	0xf6, 0xa2, 0x80, 0x3b, 0xad, 0xed, 0x5a, 0x4c, 0x24, 0xa7, 0x8f, 0x31, 0x18, 0xf4, 0x34, 0xe0,
+	0x68, 0x0c, 0xed, 0x20, 0x5a, 0x50, 0x2e, 0x54, 0xa1, 0xd9, 0xd8, 0x50, 0xee, 0xbf, 0x16, 0x8c,
+	0x36, 0xc2, 0xf8, 0x8e, 0x4e, 0x19, 0x43, 0x7b, 0x49, 0x49, 0x40, 0x99, 0x72, 0x49, 0x13, 0x1b,
+	0x0a, 0xed, 0x42, 0x2b, 0x8a, 0x03, 0x7a, 0xa6, 0x7a, 0xc8, 0x00, 0x6b, 0x42, 0x72, 0x79, 0x48,
+	0x58, 0xa0, 0x9a, 0x44, 0x1f, 0x6b, 0xe2, 0x4d, 0xaf, 0x92, 0x2f, 0xe0, 0xd1, 0x6f, 0x54, 0x95,
+	0x75, 0x13, 0xab, 0x33, 0xba, 0x05, 0x3d, 0xd9, 0x28, 0x3c, 0x25, 0xc9, 0x55, 0xe5, 0x0e, 0x30,
+	0x48, 0xd6, 0x4c, 0x71, 0xd0, 0x47, 0xd0, 0x57, 0x77, 0x5e, 0x48, 0x78, 0x48, 0xb9, 0x63, 0xef,
+	0x35, 0xf6, 0xfb, 0xb8, 0xa7, 0x78, 0x13, 0xc5, 0x72, 0xbf, 0x05, 0x3b, 0x77, 0x0f, 0x1a, 0x42,
+	0x3d, 0x0a, 0x94, 0x91, 0x36, 0xae, 0x47, 0x01, 0xfa, 0x10, 0x6c, 0x7f, 0x19, 0xd1, 0x58, 0x7a,
+	0x57, 0x19, 0x6a, 0xe3, 0xae, 0x66, 0x4c, 0x03, 0xf7, 0x09, 0x0c, 0x2a, 0x65, 0xb8, 0x19, 0x0c,
+	0xeb, 0x02, 0xc1, 0x70, 0x6f, 0xc3, 0x68, 0xa3, 0x12, 0xd1, 0x35, 0xe8, 0xe6, 0xc5, 0x6b, 0xa9,
+	0x87, 0xe7, 0xb4, 0xfb, 0x8f, 0x05, 0x9d, 0x8b, 0x25, 0x6c, 0xe1, 0xd7, 0x46, 0xc5, 0xaf, 0x63,
+	0x68, 0x13, 0xce, 0x23, 0x2e, 0x54, 0x70, 0xba, 0xd8, 0x50, 0xe8, 0x3a, 0xd8, 0x79, 0x7a, 0x9a,
+	0x08, 0x15, 0x0c, 0x34, 0x85, 0xcb, 0x73, 0xc2, 0x85, 0x97, 0x12, 0x11, 0x16, 0x69, 0x6c, 0x3a,
+	0xf6, 0x5b, 0xb2, 0xf8, 0x92, 0x94, 0x3a, 0x22, 0x22, 0xcc, 0x59, 0xee, 0xef, 0x16, 0x0c, 0x75,
+	0xa9, 0x48, 0xdb, 0xfd, 0x84, 0x05, 0xe8, 0xbb, 0x0b, 0x4e, 0xc6, 0xca, 0x5c, 0xbc, 0xf7, 0xae,
+	0x73, 0x31, 0x9f, 0x8a, 0xee, 0x9f, 0x16, 0xb4, 0x75, 0x69, 0xbd, 0xa7, 0x33, 0xbf, 0x29, 0x3b,
+	0xad, 0x79, 0x9e, 0x33, 0x4a, 0xfe, 0x2c, 0xa2, 0xd0, 0x2a, 0x47, 0xc1, 0xfd, 0xdb, 0x82, 0xd1,
+	0xc6, 0xdc, 0x78, 0xcf, 0x27, 0x7e, 0x0d, 0x5d, 0x63, 0xf6, 0x3b, 0x34, 0x9d, 0x1c, 0x2a, 0xb7,
+	0x0d, 0xdd, 0xb4, 0xde, 0xd8, 0x64, 0x0a, 0xa9, 0x0c, 0xe9, 0xfe, 0x0c, 0x2d, 0xb5, 0x1e, 0xbc,
+	0x7f, 0x8a, 0x32, 0x4a, 0x78, 0x12, 0x2b, 0x97, 0xda, 0xd8, 0x50, 0xee, 0xf7, 0x00, 0xc5, 0x22,
+	0x21, 0x6b, 0x35, 0xa6, 0x67, 0xc2, 0x2b, 0x7d, 0xa8, 0x2b, 0x19, 0x12, 0x52, 0x52, 0x51, 0xaf,
+	0xa8, 0xf8, 0xaf, 0x0e, 0xdd, 0x6c, 0x93, 0x78, 0xbb, 0x86, 0x87, 0x30, 0x58, 0xca, 0x8c, 0x0f,
+	0xa8, 0x1f, 0xf1, 0xc8, 0x28, 0x7a, 0x5b, 0xd7, 0xef, 0x4b, 0xf8, 0x63, 0x83, 0x46, 0x33, 0x70,
+	0x2a, 0xe2, 0xe5, 0xde, 0xdf, 0x38, 0xcf, 0xa1, 0xe3, 0xb2, 0xaa, 0x52, 0xf7, 0x3f, 0x04, 0x14,
+	0xc5, 0xde, 0x7c, 0x19, 0x2d, 0x42, 0xe1, 0xe5, 0xe3, 0xa8, 0x79, 0xce, 0xc3, 0x76, 0xa2, 0xf8,
+	0x50, 0x89, 0x64, 0x1c, 0xf4, 0x79, 0x55, 0x8f, 0x0a, 0x79, 0x60, 0x32, 0xb1, 0x84, 0xd6, 0x7c,
+	0x59, 0xfb, 0x05, 0xfa, 0x22, 0xb5, 0x9f, 0x69, 0x2a, 0x6a, 0xff, 0x17, 0x18, 0x56, 0xb7, 0x39,
+	0xe4, 0xc2, 0x80, 0x91, 0x53, 0xaf, 0x58, 0x02, 0x2d, 0xd5, 0x7a, 0x7a, 0x8c, 0x9c, 0xe6, 0x98,
+	0x31, 0xb4, 0xe5, 0x67, 0x29, 0x33, 0xc9, 0x63, 0xa8, 0x6a, 0xcb, 0x6a, 0x6c, 0xb4, 0x2c, 0x77,
+	0x06, 0x1d, 0xb3, 0xfb, 0xa1, 0x09, 0xec, 0x28, 0x91, 0xa0, 0xf4, 0x9d, 0xfa, 0x5e, 0xe3, 0xfc,
+	0x65, 0x13, 0x0f, 0x79, 0x85, 0x76, 0x6f, 0x81, 0x9d, 0x2f, 0x86, 0xdb, 0xb2, 0xdc, 0x7d, 0x02,
+	0xf6, 0xac, 0x5c, 0xe5, 0xe6, 0xe1, 0x56, 0xe5, 0xe1, 0xbb, 0xd0, 0x5a, 0x93, 0xe5, 0x89, 0x6e,
+	0x58, 0x7d, 0xac, 0x09, 0x59, 0x20, 0x2b, 0xbe, 0x30, 0x86, 0xc8, 0xa3, 0xfb, 0xca, 0x82, 0x6e,
+	0x1e, 0xb4, 0x31, 0xb4, 0x43, 0x3d, 0x6c, 0xb5, 0x8b, 0x0c, 0x85, 0x1c, 0xe8, 0xa4, 0xe4, 0xd7,
+	0x65, 0x42, 0x02, 0xa3, 0x2e, 0x23, 0xe5, 0x40, 0x59, 0x51, 0x41, 0x94, 0xb9, 0x5a, 0x6b, 0x4e,
+	0xa3, 0xfb, 0x70, 0x65, 0x4d, 0x99, 0x6e, 0x31, 0x2a, 0x3d, 0xe5, 0xa4, 0x89, 0x7d, 0xdd, 0xc5,
+	0x9a, 0x78, 0xb7, 0x7c, 0x39, 0x33, 0x77, 0xee, 0x5f, 0x75, 0xe8, 0x4b, 0x57, 0x3c, 0xcb, 0xb4,
+	0x5c, 0x85, 0x8e, 0xf2, 0xa8, 0x19, 0xa2, 0x4d, 0xdc, 0x96, 0xe4, 0x34, 0x40, 0x9f, 0xc0, 0x68,
+	0x49, 0x84, 0x9c, 0x8c, 0xb9, 0x62, 0x1d, 0xbb, 0xa1, 0x66, 0x67, 0x2a, 0xd1, 0x67, 0x70, 0x29,
+	0x2b, 0x11, 0xee, 0x45, 0xb1, 0xae, 0x45, 0xbd, 0x4d, 0x8c, 0xf2, 0x8b, 0x69, 0xac, 0xc2, 0x78,
+	0x03, 0xe0, 0x78, 0x49, 0xfc, 0x17, 0xde, 0x52, 0x8f, 0xaf, 0xc6, 0x7e, 0x13, 0xdb, 0x8a, 0xf3,
+	0x54, 0x4e, 0x30, 0x07, 0x3a, 0xd9, 0x1e, 0xd8, 0x52, 0x9b, 0x41, 0x46, 0xa2, 0x7b, 0xb0, 0x4b,
+	0xd2, 0x74, 0x99, 0xd9, 0x9a, 0x3b, 0xa5, 0xad, 0x9c, 0x72, 0xb9, 0x74, 0x97, 0x5b, 0x76, 0x1d,
+	0x6c, 0x11, 0xad, 0x28, 0x17, 0x64, 0x95, 0xaa, 0x1d, 0xa4, 0x81, 0x0b, 0xc6, 0xd6, 0x55, 0xb5,
+	0xbb, 0x75, 0x55, 0x95, 0xb3, 0xa6, 0x3f, 0x23, 0x6b, 0x1a, 0x64, 0xbf, 0x81, 0x53, 0x18, 0xa5,
+	0x66, 0xfc, 0x79, 0x4c, 0xcd, 0x3f, 0x33, 0xf0, 0x6e, 0x6e, 0xaf, 0xe0, 0x6c, 0x4a, 0x4e, 0x6a,
+	0x78, 0x98, 0x56, 0x38, 0xe8, 0x5e, 0xfe, 0x77, 0xf7, 0x86, 0xc9, 0x67, 0xbe, 0x59, 0xfc, 0xde,
+	0x95, 0xf6, 0xe0, 0xe3, 0xb6, 0x02, 0xdd, 0xff, 0x7f, 0x00, 0x8f, 0x5e, 0x69, 0xdc, 0xd3, 0x0e,
 	0x00, 0x00,
 }
@@ -30,10 +30,10 @@ func TestBasic(t *testing.T) {
 	n3 := newNode(3, network, t.Name(), testDir)
 	n4 := newNode(4, network, t.Name(), testDir)
 
-	n1.Consensus.Start()
-	n2.Consensus.Start()
-	n3.Consensus.Start()
-	n4.Consensus.Start()
+	assert.NoError(t, n1.Consensus.Start())
+	assert.NoError(t, n2.Consensus.Start())
+	assert.NoError(t, n3.Consensus.Start())
+	assert.NoError(t, n4.Consensus.Start())
 
 	n1.Submit(Request{ID: "1", ClientID: "alice"})
 	n1.Submit(Request{ID: "2", ClientID: "alice"})
@@ -64,10 +64,10 @@ func TestRestartFollowers(t *testing.T) {
 	n3 := newNode(3, network, t.Name(), testDir)
 	n4 := newNode(4, network, t.Name(), testDir)
 
-	n1.Consensus.Start()
-	n2.Consensus.Start()
-	n3.Consensus.Start()
-	n4.Consensus.Start()
+	assert.NoError(t, n1.Consensus.Start())
+	assert.NoError(t, n2.Consensus.Start())
+	assert.NoError(t, n3.Consensus.Start())
+	assert.NoError(t, n4.Consensus.Start())
 
 	n1.Submit(Request{ID: "1", ClientID: "alice"})
 
@@ -109,10 +109,10 @@ func TestLeaderInPartition(t *testing.T) {
 	n2 := newNode(2, network, t.Name(), testDir)
 	n3 := newNode(3, network, t.Name(), testDir)
 
-	n0.Consensus.Start()
-	n1.Consensus.Start()
-	n2.Consensus.Start()
-	n3.Consensus.Start()
+	assert.NoError(t, n0.Consensus.Start())
+	assert.NoError(t, n1.Consensus.Start())
+	assert.NoError(t, n2.Consensus.Start())
+	assert.NoError(t, n3.Consensus.Start())
 
 	n0.Disconnect() // leader in partition
 
@@ -141,10 +141,10 @@ func TestAfterDecisionLeaderInPartition(t *testing.T) {
 	n2 := newNode(2, network, t.Name(), testDir)
 	n3 := newNode(3, network, t.Name(), testDir)
 
-	n0.Consensus.Start()
-	n1.Consensus.Start()
-	n2.Consensus.Start()
-	n3.Consensus.Start()
+	assert.NoError(t, n0.Consensus.Start())
+	assert.NoError(t, n1.Consensus.Start())
+	assert.NoError(t, n2.Consensus.Start())
+	assert.NoError(t, n3.Consensus.Start())
 
 	n0.Submit(Request{ID: "1", ClientID: "alice"}) // submit to leader
 
@@ -206,17 +206,17 @@ func TestMultiLeadersPartition(t *testing.T) {
 	n5 := newNode(5, network, t.Name(), testDir)
 	n6 := newNode(6, network, t.Name(), testDir)
 
-	n0.Consensus.Start()
-	n1.Consensus.Start()
+	assert.NoError(t, n0.Consensus.Start())
+	assert.NoError(t, n1.Consensus.Start())
 
 	n0.Disconnect() // leader in partition
 	n1.Disconnect() // next leader in partition
 
-	n2.Consensus.Start()
-	n3.Consensus.Start()
-	n4.Consensus.Start()
-	n5.Consensus.Start()
-	n6.Consensus.Start()
+	assert.NoError(t, n2.Consensus.Start())
+	assert.NoError(t, n3.Consensus.Start())
+	assert.NoError(t, n4.Consensus.Start())
+	assert.NoError(t, n5.Consensus.Start())
+	assert.NoError(t, n6.Consensus.Start())
 
 	n2.Submit(Request{ID: "1", ClientID: "alice"}) // submit to new leader
 	n3.Submit(Request{ID: "1", ClientID: "alice"}) // submit to follower
@@ -252,10 +252,10 @@ func TestCatchingUpWithViewChange(t *testing.T) {
 	n2 := newNode(2, network, t.Name(), testDir)
 	n3 := newNode(3, network, t.Name(), testDir)
 
-	n0.Consensus.Start()
-	n1.Consensus.Start()
-	n2.Consensus.Start()
-	n3.Consensus.Start()
+	assert.NoError(t, n0.Consensus.Start())
+	assert.NoError(t, n1.Consensus.Start())
+	assert.NoError(t, n2.Consensus.Start())
+	assert.NoError(t, n3.Consensus.Start())
 
 	n3.Disconnect() // will need to catch up
 
@@ -300,10 +300,10 @@ func TestLeaderForwarding(t *testing.T) {
 	n2 := newNode(2, network, t.Name(), testDir)
 	n3 := newNode(3, network, t.Name(), testDir)
 
-	n0.Consensus.Start()
-	n1.Consensus.Start()
-	n2.Consensus.Start()
-	n3.Consensus.Start()
+	assert.NoError(t, n0.Consensus.Start())
+	assert.NoError(t, n1.Consensus.Start())
+	assert.NoError(t, n2.Consensus.Start())
+	assert.NoError(t, n3.Consensus.Start())
 
 	n1.Submit(Request{ID: "1", ClientID: "alice"})
 	n2.Submit(Request{ID: "2", ClientID: "bob"})
@@ -342,10 +342,10 @@ func TestLeaderExclusion(t *testing.T) {
 
 	n0.DisconnectFrom(3)
 
-	n0.Consensus.Start()
-	n1.Consensus.Start()
-	n2.Consensus.Start()
-	n3.Consensus.Start()
+	assert.NoError(t, n0.Consensus.Start())
+	assert.NoError(t, n1.Consensus.Start())
+	assert.NoError(t, n2.Consensus.Start())
+	assert.NoError(t, n3.Consensus.Start())
 
 	// We create new batches until the disconnected node catches up the quorum.
 	for reqID := 1; reqID < 100; reqID++ {
@@ -373,10 +373,10 @@ func TestCatchingUpWithSync(t *testing.T) {
 	n2 := newNode(2, network, t.Name(), testDir)
 	n3 := newNode(3, network, t.Name(), testDir)
 
-	n0.Consensus.Start()
-	n1.Consensus.Start()
-	n2.Consensus.Start()
-	n3.Consensus.Start()
+	assert.NoError(t, n0.Consensus.Start())
+	assert.NoError(t, n1.Consensus.Start())
+	assert.NoError(t, n2.Consensus.Start())
+	assert.NoError(t, n3.Consensus.Start())
 
 	n3.Disconnect() // will need to catch up
 
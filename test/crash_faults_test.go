@@ -0,0 +1,75 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/SmartBFT-Go/consensus/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestCrashFaultsFollowerDown(t *testing.T) {
+	t.Parallel()
+	network := make(Network)
+	defer network.Shutdown()
+
+	testDir, err := ioutil.TempDir("", t.Name())
+	assert.NoErrorf(t, err, "generate temporary test dir")
+	defer os.RemoveAll(testDir)
+
+	var nodes []*App
+	for id := uint64(1); id <= 3; id++ {
+		n := newNode(id, network, t.Name(), testDir)
+		n.Consensus.FaultModel = types.CrashFaults
+		nodes = append(nodes, n)
+	}
+
+	for _, n := range nodes {
+		assert.NoError(t, n.Consensus.Start())
+	}
+
+	// Two out of three nodes are a quorum
+	nodes[2].Disconnect()
+
+	nodes[0].Submit(Request{ID: "1", ClientID: "alice"})
+
+	data1 := <-nodes[0].Delivered
+	data2 := <-nodes[1].Delivered
+	assert.Equal(t, data1, data2)
+}
+
+func TestCrashFaultsLeaderDown(t *testing.T) {
+	t.Parallel()
+	network := make(Network)
+	defer network.Shutdown()
+
+	testDir, err := ioutil.TempDir("", t.Name())
+	assert.NoErrorf(t, err, "generate temporary test dir")
+	defer os.RemoveAll(testDir)
+
+	var nodes []*App
+	for id := uint64(1); id <= 3; id++ {
+		n := newNode(id, network, t.Name(), testDir)
+		n.Consensus.FaultModel = types.CrashFaults
+		nodes = append(nodes, n)
+	}
+
+	for _, n := range nodes {
+		assert.NoError(t, n.Consensus.Start())
+	}
+
+	nodes[0].Disconnect() // leader in partition
+
+	nodes[1].Submit(Request{ID: "1", ClientID: "alice"})
+	nodes[2].Submit(Request{ID: "1", ClientID: "alice"})
+
+	data2 := <-nodes[1].Delivered
+	data3 := <-nodes[2].Delivered
+	assert.Equal(t, data2, data3)
+}
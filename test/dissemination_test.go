@@ -0,0 +1,52 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDisseminateRequests(t *testing.T) {
+	t.Parallel()
+	network := make(Network)
+	defer network.Shutdown()
+
+	testDir, err := ioutil.TempDir("", t.Name())
+	assert.NoErrorf(t, err, "generate temporary test dir")
+	defer os.RemoveAll(testDir)
+
+	var nodes []*App
+	for id := uint64(1); id <= 4; id++ {
+		n := newNode(id, network, t.Name(), testDir)
+		n.Consensus.DisseminateRequests = true
+		nodes = append(nodes, n)
+	}
+
+	for _, n := range nodes {
+		assert.NoError(t, n.Consensus.Start())
+	}
+
+	// All nodes hold the request, so they assemble the proposal by themselves
+	for _, n := range nodes {
+		n.Submit(Request{ID: "1", ClientID: "alice"})
+	}
+	data := <-nodes[0].Delivered
+	for _, n := range nodes[1:] {
+		assert.Equal(t, data, <-n.Delivered)
+	}
+
+	// Only the leader holds the request, so the followers fetch it
+	nodes[0].Submit(Request{ID: "2", ClientID: "alice"})
+	data = <-nodes[0].Delivered
+	assert.Equal(t, &Request{ID: "2", ClientID: "alice"}, requestFromBytes(data.Batch.Requests[0]))
+	for _, n := range nodes[1:] {
+		assert.Equal(t, data, <-n.Delivered)
+	}
+}
@@ -0,0 +1,166 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/SmartBFT-Go/consensus/smartbftprotos"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+const (
+	byzantineNodeID  = 4
+	deliveryDeadline = 10 * time.Second
+)
+
+// byzantineHandler stands for a faulty node which ignores everything sent to it,
+// while the fuzzer speaks on its behalf.
+type byzantineHandler struct{}
+
+func (byzantineHandler) HandleMessage(sender uint64, m *smartbftprotos.Message) {}
+
+func (byzantineHandler) HandleRequest(sender uint64, req []byte) {}
+
+func (byzantineHandler) Stop() {}
+
+// encodeMessageSequence encodes messages in the format decodeMessageSequence expects:
+// a target byte, a length byte and the marshaled message, for every message.
+func encodeMessageSequence(targets []byte, messages ...*smartbftprotos.Message) []byte {
+	var res []byte
+	for i, m := range messages {
+		raw, err := proto.Marshal(m)
+		if err != nil {
+			panic(err)
+		}
+		if len(raw) > 255 {
+			panic("message too big")
+		}
+		res = append(res, targets[i%len(targets)], byte(len(raw)))
+		res = append(res, raw...)
+	}
+	return res
+}
+
+type targetedMessage struct {
+	target  uint64
+	message *smartbftprotos.Message
+}
+
+// decodeMessageSequence decodes the given data into messages and the honest nodes they are sent to.
+// Records that cannot be unmarshaled are skipped.
+func decodeMessageSequence(data []byte, honestNodes int) []targetedMessage {
+	var res []targetedMessage
+	for len(data) >= 2 {
+		target := uint64(data[0])%uint64(honestNodes) + 1
+		size := int(data[1])
+		data = data[2:]
+		if size > len(data) {
+			size = len(data)
+		}
+		raw := data[:size]
+		data = data[size:]
+
+		m := &smartbftprotos.Message{}
+		if err := proto.Unmarshal(raw, m); err != nil {
+			continue
+		}
+		res = append(res, targetedMessage{target: target, message: m})
+	}
+	return res
+}
+
+func fuzzSeedMessages() []*smartbftprotos.Message {
+	md, _ := proto.Marshal(&smartbftprotos.ViewMetadata{ViewId: 0, LatestSequence: 0})
+	proposal := &smartbftprotos.Proposal{
+		Payload:  Batch{Requests: [][]byte{Request{ClientID: "mallory", ID: "1"}.ToBytes()}}.ToBytes(),
+		Metadata: md,
+	}
+	vd, _ := proto.Marshal(&smartbftprotos.ViewData{
+		NextView:     1,
+		LastDecision: &smartbftprotos.Proposal{},
+	})
+
+	return []*smartbftprotos.Message{
+		{Content: &smartbftprotos.Message_PrePrepare{PrePrepare: &smartbftprotos.PrePrepare{Proposal: proposal}}},
+		{Content: &smartbftprotos.Message_PrePrepare{PrePrepare: &smartbftprotos.PrePrepare{Seq: 1}}},
+		{Content: &smartbftprotos.Message_Prepare{Prepare: &smartbftprotos.Prepare{Digest: "digest"}}},
+		{Content: &smartbftprotos.Message_Commit{Commit: &smartbftprotos.Commit{Seq: 1}}},
+		{Content: &smartbftprotos.Message_Commit{Commit: &smartbftprotos.Commit{
+			Seq:       5,
+			View:      3,
+			Signature: &smartbftprotos.Signature{Signer: byzantineNodeID},
+		}}},
+		{Content: &smartbftprotos.Message_ViewChange{ViewChange: &smartbftprotos.ViewChange{NextView: 1}}},
+		{Content: &smartbftprotos.Message_ViewData{ViewData: &smartbftprotos.SignedViewData{
+			RawViewData: vd,
+			Signer:      byzantineNodeID,
+		}}},
+		{Content: &smartbftprotos.Message_NewView{NewView: &smartbftprotos.NewView{
+			SignedViewData: []*smartbftprotos.SignedViewData{{}},
+		}}},
+		{Content: &smartbftprotos.Message_HeartBeat{HeartBeat: &smartbftprotos.HeartBeat{View: 1}}},
+		{Content: &smartbftprotos.Message_Error{Error: &smartbftprotos.Error{}}},
+	}
+}
+
+// FuzzByzantineMessageSequence runs three honest nodes next to a Byzantine node
+// which sends them a fuzzed sequence of messages.
+// It ensures the honest nodes neither panic nor get stuck,
+// and that they all deliver the same decision.
+func FuzzByzantineMessageSequence(f *testing.F) {
+	seeds := fuzzSeedMessages()
+	f.Add(encodeMessageSequence([]byte{0, 1, 2}, seeds...))
+	for _, m := range seeds {
+		f.Add(encodeMessageSequence([]byte{0}, m))
+		f.Add(encodeMessageSequence([]byte{1, 2}, m, m))
+	}
+
+	f.Fuzz(func(t *testing.T, data []byte) {
+		network := make(Network)
+		defer network.Shutdown()
+
+		testDir, err := ioutil.TempDir("", "FuzzByzantineMessageSequence")
+		assert.NoErrorf(t, err, "generate temporary test dir")
+		defer os.RemoveAll(testDir)
+
+		var honestNodes []*App
+		for id := uint64(1); id < byzantineNodeID; id++ {
+			n := newNode(id, network, "FuzzByzantineMessageSequence", testDir)
+			n.Mute()
+			honestNodes = append(honestNodes, n)
+		}
+		network.AddOrUpdateNode(byzantineNodeID, byzantineHandler{})
+
+		for _, n := range honestNodes {
+			assert.NoError(t, n.Consensus.Start())
+		}
+
+		for _, m := range decodeMessageSequence(data, len(honestNodes)) {
+			network.send(byzantineNodeID, m.target, m.message)
+		}
+
+		honestNodes[0].Submit(Request{ID: "1", ClientID: "alice"})
+
+		var delivered []*AppRecord
+		for _, n := range honestNodes {
+			select {
+			case record := <-n.Delivered:
+				delivered = append(delivered, record)
+			case <-time.After(deliveryDeadline):
+				t.Fatalf("node %d did not deliver within %v", n.ID, deliveryDeadline)
+			}
+		}
+
+		for _, record := range delivered[1:] {
+			assert.Equal(t, delivered[0], record)
+		}
+	})
+}
@@ -0,0 +1,59 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/SmartBFT-Go/consensus/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHaltOnCorruptedWAL(t *testing.T) {
+	t.Parallel()
+	network := make(Network)
+	defer network.Shutdown()
+
+	testDir, err := ioutil.TempDir("", t.Name())
+	assert.NoErrorf(t, err, "generate temporary test dir")
+	defer os.RemoveAll(testDir)
+
+	n1 := newNode(1, network, t.Name(), testDir)
+	n2 := newNode(2, network, t.Name(), testDir)
+	n3 := newNode(3, network, t.Name(), testDir)
+	n4 := newNode(4, network, t.Name(), testDir)
+
+	n4.Consensus.WALInitialContent = [][]byte{{0xff}}
+
+	assert.NoError(t, n1.Consensus.Start())
+	assert.NoError(t, n2.Consensus.Start())
+	assert.NoError(t, n3.Consensus.Start())
+	assert.NoError(t, n4.Consensus.Start())
+
+	select {
+	case err := <-n4.Consensus.Err():
+		fatalErr, isFatal := err.(*types.FatalError)
+		assert.True(t, isFatal)
+		assert.Equal(t, uint64(4), fatalErr.Node)
+		assert.Contains(t, err.Error(), "failed restoring view from WAL")
+	case <-time.After(10 * time.Second):
+		t.Fatal("node 4 didn't halt")
+	}
+
+	// The halted node keeps running, and the rest of the nodes keep ordering without it
+	n1.Submit(Request{ID: "1", ClientID: "alice"})
+
+	data1 := <-n1.Delivered
+	data2 := <-n2.Delivered
+	data3 := <-n3.Delivered
+	assert.Equal(t, data1, data2)
+	assert.Equal(t, data1, data3)
+	assert.Len(t, n4.Delivered, 0)
+	assert.Error(t, n4.Consensus.SubmitRequest(Request{ID: "2", ClientID: "alice"}.ToBytes()))
+}
@@ -0,0 +1,74 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package test
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/SmartBFT-Go/consensus/pkg/journal"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestJournalReplay(t *testing.T) {
+	t.Parallel()
+	network := make(Network)
+	defer network.Shutdown()
+
+	testDir, err := ioutil.TempDir("", t.Name())
+	assert.NoErrorf(t, err, "generate temporary test dir")
+	defer os.RemoveAll(testDir)
+
+	n1 := newNode(1, network, t.Name(), testDir)
+	n2 := newNode(2, network, t.Name(), testDir)
+	n3 := newNode(3, network, t.Name(), testDir)
+	n4 := newNode(4, network, t.Name(), testDir)
+
+	buff := &bytes.Buffer{}
+	recorder := journal.NewRecorder(buff)
+	n4.Consensus.Journal = recorder
+
+	assert.NoError(t, n1.Consensus.Start())
+	assert.NoError(t, n2.Consensus.Start())
+	assert.NoError(t, n3.Consensus.Start())
+	assert.NoError(t, n4.Consensus.Start())
+
+	n1.Submit(Request{ID: "1", ClientID: "alice"})
+	n1.Submit(Request{ID: "2", ClientID: "alice"})
+
+	recorded := <-n4.Delivered
+	<-n1.Delivered
+	<-n2.Delivered
+	<-n3.Delivered
+
+	n4.Consensus.Stop()
+	assert.NoError(t, recorder.Close())
+
+	// Replay the journal into a fresh node 4, in a network where the other nodes are unreachable
+	replayNetwork := make(Network)
+	defer replayNetwork.Shutdown()
+	replayDir := filepath.Join(testDir, "replay")
+	for id := uint64(1); id <= 3; id++ {
+		unreachable := newNode(id, replayNetwork, t.Name(), replayDir)
+		unreachable.Disconnect()
+		assert.NoError(t, unreachable.Consensus.Start())
+	}
+	fresh := newNode(4, replayNetwork, t.Name(), replayDir)
+
+	replayer := journal.NewReplayer(fresh.Consensus, fresh)
+	fresh.Consensus.Application = replayer
+	fresh.Consensus.Scheduler = replayer.Scheduler()
+	fresh.Consensus.ViewChangerTicker = replayer.ViewChangerTicker()
+	assert.NoError(t, fresh.Consensus.Start())
+
+	count, err := replayer.Replay(journal.NewReader(buff))
+	assert.NoError(t, err)
+	assert.NotZero(t, count)
+	assert.Equal(t, recorded, <-fresh.Delivered)
+}
@@ -0,0 +1,52 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLinearCommunication(t *testing.T) {
+	t.Parallel()
+	network := make(Network)
+	defer network.Shutdown()
+
+	testDir, err := ioutil.TempDir("", t.Name())
+	assert.NoErrorf(t, err, "generate temporary test dir")
+	defer os.RemoveAll(testDir)
+
+	var nodes []*App
+	for id := uint64(1); id <= 4; id++ {
+		n := newNode(id, network, t.Name(), testDir)
+		n.Consensus.VoteCollectorTimeout = time.Second
+		n.Consensus.VoteCollector = 4
+		nodes = append(nodes, n)
+	}
+
+	for _, n := range nodes {
+		assert.NoError(t, n.Consensus.Start())
+	}
+
+	nodes[0].Submit(Request{ID: "1", ClientID: "alice"})
+	data := <-nodes[0].Delivered
+	for _, n := range nodes[1:] {
+		assert.Equal(t, data, <-n.Delivered)
+	}
+
+	// Without the collector the nodes fall back to sending their votes to all nodes
+	nodes[3].Disconnect()
+
+	nodes[0].Submit(Request{ID: "2", ClientID: "alice"})
+	data = <-nodes[0].Delivered
+	for _, n := range nodes[1:3] {
+		assert.Equal(t, data, <-n.Delivered)
+	}
+}
@@ -0,0 +1,98 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/SmartBFT-Go/consensus/smartbftprotos"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLeaderRotationAfterDecisions(t *testing.T) {
+	t.Parallel()
+	network := make(Network)
+	defer network.Shutdown()
+
+	testDir, err := ioutil.TempDir("", t.Name())
+	assert.NoErrorf(t, err, "generate temporary test dir")
+	defer os.RemoveAll(testDir)
+
+	var nodes []*App
+	for id := uint64(1); id <= 4; id++ {
+		n := newNode(id, network, t.Name(), testDir)
+		n.Consensus.DecisionsPerLeader = 2
+		nodes = append(nodes, n)
+	}
+
+	for _, n := range nodes {
+		assert.NoError(t, n.Consensus.Start())
+	}
+
+	for i := 1; i <= 6; i++ {
+		for _, n := range nodes {
+			n.Submit(Request{ID: fmt.Sprintf("%d", i), ClientID: "alice"})
+		}
+
+		data1 := <-nodes[0].Delivered
+		for _, n := range nodes[1:] {
+			assert.Equal(t, data1, <-n.Delivered)
+		}
+
+		md := &smartbftprotos.ViewMetadata{}
+		assert.NoError(t, proto.Unmarshal(data1.Metadata, md))
+		assert.Equal(t, uint64(i), md.LatestSequence)
+		// Every leader makes two decisions before the next one takes over
+		assert.Equal(t, uint64((i-1)/2), md.ViewId)
+		assert.Equal(t, uint64((i-1)%2), md.DecisionsInView)
+	}
+}
+
+func TestDeposedLeaderBlacklisted(t *testing.T) {
+	t.Parallel()
+	network := make(Network)
+	defer network.Shutdown()
+
+	testDir, err := ioutil.TempDir("", t.Name())
+	assert.NoErrorf(t, err, "generate temporary test dir")
+	defer os.RemoveAll(testDir)
+
+	var nodes []*App
+	for id := uint64(1); id <= 4; id++ {
+		n := newNode(id, network, t.Name(), testDir)
+		n.Consensus.DecisionsPerLeader = 1
+		n.Consensus.LeaderBlacklisting = true
+		nodes = append(nodes, n)
+	}
+
+	for _, n := range nodes {
+		assert.NoError(t, n.Consensus.Start())
+	}
+
+	nodes[0].Disconnect() // leader of view 0 in partition
+
+	for i := 1; i <= 4; i++ {
+		for _, n := range nodes[1:] {
+			n.Submit(Request{ID: fmt.Sprintf("%d", i), ClientID: "alice"})
+		}
+
+		data2 := <-nodes[1].Delivered
+		for _, n := range nodes[2:] {
+			assert.Equal(t, data2, <-n.Delivered)
+		}
+
+		md := &smartbftprotos.ViewMetadata{}
+		assert.NoError(t, proto.Unmarshal(data2.Metadata, md))
+		// The first decision is made after the leader of view 0 is deposed, and the rest follow a decision each.
+		// View 4 is led by node 2 instead of the blacklisted node 1, so no other view change takes place.
+		assert.Equal(t, uint64(i), md.ViewId)
+		assert.Equal(t, []uint64{1}, md.BlackList)
+	}
+}
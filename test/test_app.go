@@ -13,6 +13,7 @@ import (
 	"time"
 
 	"github.com/SmartBFT-Go/consensus/pkg/consensus"
+	"github.com/SmartBFT-Go/consensus/pkg/logging"
 	"github.com/SmartBFT-Go/consensus/pkg/types"
 	"github.com/SmartBFT-Go/consensus/pkg/wal"
 	"github.com/SmartBFT-Go/consensus/smartbftprotos"
@@ -62,7 +63,9 @@ func (a *App) Restart() {
 	defer a.Node.Unlock()
 	a.Consensus.Stop()
 	a.Setup()
-	a.Consensus.Start()
+	if err := a.Consensus.Start(); err != nil {
+		panic(err)
+	}
 }
 
 func (a *App) Disconnect() {
@@ -269,7 +272,7 @@ func newNode(id uint64, network Network, testName string, testDir string) *App {
 			ViewChangerTimeout:      time.Minute,
 			Scheduler:               app.clock.C,
 			SelfID:                  id,
-			Logger:                  sugaredLogger,
+			Logger:                  logging.NewZapLogger(sugaredLogger),
 			WAL:                     writeAheadLog,
 			Metadata:                *app.latestMD,
 			Verifier:                app,
@@ -0,0 +1,54 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/SmartBFT-Go/consensus/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDecisionTimestamps(t *testing.T) {
+	t.Parallel()
+	network := make(Network)
+	defer network.Shutdown()
+
+	testDir, err := ioutil.TempDir("", t.Name())
+	assert.NoErrorf(t, err, "generate temporary test dir")
+	defer os.RemoveAll(testDir)
+
+	var nodes []*App
+	for id := uint64(1); id <= 4; id++ {
+		n := newNode(id, network, t.Name(), testDir)
+		n.Consensus.TimestampSkew = time.Minute
+		nodes = append(nodes, n)
+	}
+
+	for _, n := range nodes {
+		assert.NoError(t, n.Consensus.Start())
+	}
+
+	var lastTimestamp time.Time
+	for i := 1; i <= 3; i++ {
+		nodes[0].Submit(Request{ID: fmt.Sprintf("%d", i), ClientID: "alice"})
+
+		data1 := <-nodes[0].Delivered
+		for _, n := range nodes[1:] {
+			assert.Equal(t, data1, <-n.Delivered)
+		}
+
+		timestamp, err := types.Proposal{Metadata: data1.Metadata}.Timestamp()
+		assert.NoError(t, err)
+		assert.True(t, timestamp.After(lastTimestamp))
+		assert.WithinDuration(t, time.Now(), timestamp, time.Minute)
+		lastTimestamp = timestamp
+	}
+}
@@ -0,0 +1,74 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/SmartBFT-Go/consensus/pkg/types"
+	"github.com/stretchr/testify/assert"
+)
+
+type requestTracer chan types.RequestEvent
+
+func (rt requestTracer) TraceRequest(event types.RequestEvent) {
+	rt <- event
+}
+
+func TestRequestTracing(t *testing.T) {
+	t.Parallel()
+	network := make(Network)
+	defer network.Shutdown()
+
+	testDir, err := ioutil.TempDir("", t.Name())
+	assert.NoErrorf(t, err, "generate temporary test dir")
+	defer os.RemoveAll(testDir)
+
+	tracer := make(requestTracer, 100)
+
+	n1 := newNode(1, network, t.Name(), testDir)
+	n2 := newNode(2, network, t.Name(), testDir)
+	n3 := newNode(3, network, t.Name(), testDir)
+	n4 := newNode(4, network, t.Name(), testDir)
+
+	n1.Consensus.RequestTracer = tracer
+
+	assert.NoError(t, n1.Consensus.Start())
+	assert.NoError(t, n2.Consensus.Start())
+	assert.NoError(t, n3.Consensus.Start())
+	assert.NoError(t, n4.Consensus.Start())
+
+	n1.Submit(Request{ID: "1", ClientID: "alice"})
+
+	<-n1.Delivered
+	<-n2.Delivered
+	<-n3.Delivered
+	<-n4.Delivered
+
+	request := types.RequestInfo{ID: "1", ClientID: "alice"}
+	expected := []types.RequestEvent{
+		{Node: 1, Type: types.RequestSubmitted, Request: request},
+		{Node: 1, Type: types.RequestBatched, Request: request, Sequence: 1},
+		{Node: 1, Type: types.RequestCommitted, Request: request, Sequence: 1},
+		{Node: 1, Type: types.RequestRemoved, Request: request},
+	}
+
+	var lastEventTime time.Time
+	for _, expectedEvent := range expected {
+		select {
+		case event := <-tracer:
+			assert.False(t, event.Time.Before(lastEventTime))
+			lastEventTime = event.Time
+			event.Time = time.Time{}
+			assert.Equal(t, expectedEvent, event)
+		case <-time.After(10 * time.Second):
+			t.Fatalf("did not trace %s", expectedEvent.Type)
+		}
+	}
+}
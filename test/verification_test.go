@@ -0,0 +1,52 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestBoundedVerification(t *testing.T) {
+	t.Parallel()
+	network := make(Network)
+	defer network.Shutdown()
+
+	testDir, err := ioutil.TempDir("", t.Name())
+	assert.NoErrorf(t, err, "generate temporary test dir")
+	defer os.RemoveAll(testDir)
+
+	var nodes []*App
+	for id := uint64(1); id <= 4; id++ {
+		n := newNode(id, network, t.Name(), testDir)
+		n.Consensus.VerificationWorkers = 1
+		n.Consensus.VerificationQueueSize = 1
+		nodes = append(nodes, n)
+	}
+
+	for _, n := range nodes {
+		assert.NoError(t, n.Consensus.Start())
+	}
+
+	for i := 1; i <= 5; i++ {
+		nodes[0].Submit(Request{ID: fmt.Sprintf("%d", i), ClientID: "alice"})
+		data := <-nodes[0].Delivered
+		for _, n := range nodes[1:] {
+			assert.Equal(t, data, <-n.Delivered)
+		}
+	}
+
+	// Every node verified the commits of the other nodes
+	for _, n := range nodes {
+		metrics := n.Consensus.VerificationMetrics()
+		assert.True(t, metrics.Verified >= 5*2, "verified %d signatures", metrics.Verified)
+		assert.Equal(t, uint64(0), metrics.Invalid)
+	}
+}
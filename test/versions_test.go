@@ -0,0 +1,83 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package test
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"testing"
+	"time"
+
+	protos "github.com/SmartBFT-Go/consensus/smartbftprotos"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestProtocolUpgradeOnceQuorumSupportsIt(t *testing.T) {
+	t.Parallel()
+	network := make(Network)
+	defer network.Shutdown()
+
+	testDir, err := ioutil.TempDir("", t.Name())
+	assert.NoErrorf(t, err, "generate temporary test dir")
+	defer os.RemoveAll(testDir)
+
+	// All nodes but the last one are upgraded, and send pre-prepares with request IDs once all of them can
+	var nodes []*App
+	for id := uint64(1); id <= 4; id++ {
+		n := newNode(id, network, t.Name(), testDir)
+		if id < 4 {
+			n.Consensus.DisseminateRequests = true
+		} else {
+			n.Consensus.MaxProtocolVersion = 1
+		}
+		nodes = append(nodes, n)
+	}
+
+	for _, n := range nodes {
+		assert.NoError(t, n.Consensus.Start())
+	}
+
+	protocolVersion := func(record *AppRecord) uint32 {
+		md := &protos.ViewMetadata{}
+		assert.NoError(t, proto.Unmarshal(record.Metadata, md))
+		return md.ProtocolVersion
+	}
+
+	// The leader learns which versions the nodes support from their votes on the first decision,
+	// which the node that wasn't upgraded decides as its pre-prepare carries the requests
+	nodes[0].Submit(Request{ID: "1", ClientID: "alice"})
+	data1 := <-nodes[0].Delivered
+	for _, n := range nodes[1:] {
+		assert.Equal(t, data1, <-n.Delivered)
+	}
+	assert.Equal(t, uint32(1), protocolVersion(data1))
+
+	for i := 2; i <= 3; i++ {
+		nodes[0].Submit(Request{ID: fmt.Sprintf("%d", i), ClientID: "alice"})
+		data := <-nodes[0].Delivered
+		for _, n := range nodes[1:3] {
+			assert.Equal(t, data, <-n.Delivered)
+		}
+		assert.Equal(t, uint32(3), protocolVersion(data))
+	}
+	for _, n := range nodes[:3] {
+		assert.Equal(t, uint32(3), n.Consensus.ProtocolVersion())
+	}
+
+	// The node which wasn't upgraded reports the leader instead of interpreting its messages
+	deadline := time.After(10 * time.Second)
+	for len(nodes[3].Consensus.IncompatibleNodes()) == 0 {
+		select {
+		case <-deadline:
+			t.Fatal("node 4 didn't report incompatible nodes")
+		case <-time.After(10 * time.Millisecond):
+		}
+	}
+	assert.Contains(t, nodes[3].Consensus.IncompatibleNodes(), uint64(1))
+	assert.Equal(t, uint32(1), nodes[3].Consensus.ProtocolVersion())
+}
@@ -0,0 +1,73 @@
+// Copyright IBM Corp. All Rights Reserved.
+//
+// SPDX-License-Identifier: Apache-2.0
+//
+
+package test
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWeightedQuorum(t *testing.T) {
+	t.Parallel()
+	network := make(Network)
+	defer network.Shutdown()
+
+	testDir, err := ioutil.TempDir("", t.Name())
+	assert.NoErrorf(t, err, "generate temporary test dir")
+	defer os.RemoveAll(testDir)
+
+	// The total weight is 11, so the tolerated faulty weight is 3 and a quorum weighs 8
+	weights := map[uint64]uint64{1: 3, 2: 3, 3: 3, 4: 1, 5: 1}
+
+	var nodes []*App
+	for id := uint64(1); id <= 5; id++ {
+		n := newNode(id, network, t.Name(), testDir)
+		n.Consensus.NodeWeights = weights
+		nodes = append(nodes, n)
+	}
+
+	for _, n := range nodes {
+		assert.NoError(t, n.Consensus.Start())
+	}
+
+	// Three out of five nodes are not a quorum by count, but they are by weight
+	nodes[3].Disconnect()
+	nodes[4].Disconnect()
+
+	nodes[0].Submit(Request{ID: "1", ClientID: "alice"})
+
+	data1 := <-nodes[0].Delivered
+	data2 := <-nodes[1].Delivered
+	data3 := <-nodes[2].Delivered
+	assert.Equal(t, data1, data2)
+	assert.Equal(t, data1, data3)
+}
+
+func TestInvalidWeights(t *testing.T) {
+	t.Parallel()
+	network := make(Network)
+	defer network.Shutdown()
+
+	testDir, err := ioutil.TempDir("", t.Name())
+	assert.NoErrorf(t, err, "generate temporary test dir")
+	defer os.RemoveAll(testDir)
+
+	var nodes []*App
+	for id := uint64(1); id <= 4; id++ {
+		nodes = append(nodes, newNode(id, network, t.Name(), testDir))
+	}
+
+	// Node 4 has no weight, so the configuration is rejected instead of crashing the process
+	nodes[0].Consensus.NodeWeights = map[uint64]uint64{1: 1, 2: 1, 3: 1}
+	assert.EqualError(t, nodes[0].Consensus.Start(), "invalid node weights: node 4 has no weight")
+
+	// Node 1 is heavier than the tolerated faulty weight, so its fault alone would break safety
+	nodes[0].Consensus.NodeWeights = map[uint64]uint64{1: 3, 2: 1, 3: 1, 4: 1}
+	assert.EqualError(t, nodes[0].Consensus.Start(), "invalid node weights: node 1 has a weight of 3, which exceeds the tolerated faulty weight 1")
+}